@@ -0,0 +1,82 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindClusterLicense = "ClusterLicense"
+	ResourceClusterLicense     = "clusterlicense"
+	ResourceClusterLicenses    = "clusterlicenses"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clusterlicenses,singular=clusterlicense,scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Product",type="string",JSONPath=".spec.product"
+// +kubebuilder:printcolumn:name="Plan",type="string",JSONPath=".status.planName"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="Expiry",type="date",JSONPath=".status.notAfter"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterLicense is a license installed in the cluster, so that admins can manage licenses declaratively.
+// The License kind of this group describes a verified license and is not a custom resource.
+type ClusterLicense struct {
+	metav1.TypeMeta   `json:",inline,omitempty"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterLicenseSpec   `json:"spec,omitempty"`
+	Status ClusterLicenseStatus `json:"status,omitempty"`
+}
+
+// ClusterLicenseSpec is the license and the product it is verified for.
+type ClusterLicenseSpec struct {
+	// License is the PEM or JWT encoded license.
+	License string `json:"license"`
+	// Product is the feature the license is verified for, eg. kubedb-enterprise.
+	Product string `json:"product"`
+}
+
+// ClusterLicenseStatus is the result of the last verification of the license.
+type ClusterLicenseStatus struct {
+	// ObservedGeneration is the generation of the spec that was verified.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Valid is true if the license is valid for the product in this cluster.
+	Valid    bool          `json:"valid"`
+	ID       string        `json:"id,omitempty"`
+	Status   LicenseStatus `json:"status,omitempty"`
+	PlanName string        `json:"planName,omitempty"`
+	Features []string      `json:"features,omitempty"`
+	NotAfter *metav1.Time  `json:"notAfter,omitempty"`
+	// Conditions are the Valid, Expired, WrongCluster and FeatureMissing conditions of the license.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterLicenseList is a list of ClusterLicenses.
+type ClusterLicenseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterLicense `json:"items"`
+}
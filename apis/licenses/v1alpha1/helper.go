@@ -16,8 +16,115 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	"strings"
+)
+
+const (
+	FeatureFlagDisableAnalytics     = "DisableAnalytics"
+	FeatureFlagClusterCAFingerprint = "ClusterCAFingerprint"
+	FeatureFlagNamespaces           = "Namespaces"
+	// FeatureFlagNodeSelector is a label selector that matches the nodes the license permits workloads to run on.
+	FeatureFlagNodeSelector = "NodeSelector"
+	// FeatureFlagProductVersion is a semver constraint on the product versions covered by the license, eg. "<= v2024.12.x".
+	FeatureFlagProductVersion = "ProductVersion"
+	// FeatureFlagTrial is "true" for trial licenses.
+	FeatureFlagTrial = "Trial"
+	// FeatureFlagAddOn is "true" for add-on licenses, which extend the features and limits of a base license.
+	FeatureFlagAddOn = "AddOn"
+	// FeatureFlagLimitPrefix prefixes the feature flags of x509 licenses that carry entitlement limits, eg. limit.nodes=10 .
+	FeatureFlagLimitPrefix = "limit."
+)
+
+// Entitlement limits understood by the license enforcer. Products may define their own, eg. the number of managed databases.
+const (
+	LimitNodes = "nodes"
+	LimitCPU   = "cpu"
+)
+
 func (l License) DisableAnalytics() bool {
-	return len(l.FeatureFlags) > 0 && l.FeatureFlags["DisableAnalytics"] == "true"
+	return len(l.FeatureFlags) > 0 && l.FeatureFlags[FeatureFlagDisableAnalytics] == "true"
+}
+
+// ClusterCAFingerprint returns the sha256 fingerprint of the cluster CA certificate
+// this license is bound to. It returns an empty string if the license is not bound to a CA.
+func (l License) ClusterCAFingerprint() string {
+	return l.FeatureFlags[FeatureFlagClusterCAFingerprint]
+}
+
+func (l License) Summary() LicenseSummary {
+	return LicenseSummary{
+		ID:                 l.ID,
+		Status:             l.Status,
+		Reason:             l.Reason,
+		PlanName:           l.PlanName,
+		Features:           l.Features,
+		NotAfter:           l.NotAfter,
+		Trial:              l.Trial,
+		TrialDaysRemaining: l.TrialDaysRemaining,
+	}
+}
+
+// NodeSelector returns the label selector for the nodes permitted by the license.
+// It returns an empty string if the license does not restrict nodes.
+func (l License) NodeSelector() string {
+	return l.FeatureFlags[FeatureFlagNodeSelector]
+}
+
+// ProductVersion returns the semver constraint on the product versions covered by the license.
+// It returns an empty string if the license covers all versions.
+func (l License) ProductVersion() string {
+	return l.FeatureFlags[FeatureFlagProductVersion]
+}
+
+// IsTrial returns true if this is a trial license.
+func (l License) IsTrial() bool {
+	return l.FeatureFlags[FeatureFlagTrial] == "true"
+}
+
+// IsAddOn returns true if this is an add-on license, which is only valid stacked on a base license.
+func (l License) IsAddOn() bool {
+	return l.FeatureFlags[FeatureFlagAddOn] == "true"
+}
+
+// Limit returns the entitlement limit for the resource. ok is false if the resource is unlimited.
+func (l License) Limit(resource string) (limit int64, ok bool) {
+	limit, ok = l.Limits[resource]
+	return
+}
+
+// SupportPlan returns the support tier and SLA of the contract under which the license was issued, if known.
+func (l License) SupportPlan() *SupportPlan {
+	if l.Contract == nil {
+		return nil
+	}
+	return l.Contract.Support
+}
+
+// Namespaces returns the namespaces covered by a per-namespace license.
+// It returns nil if the license covers the whole cluster.
+func (l License) Namespaces() []string {
+	v := l.FeatureFlags[FeatureFlagNamespaces]
+	if v == "" {
+		return nil
+	}
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	})
+}
+
+// CoversNamespace returns true if the license applies to the given namespace.
+func (l License) CoversNamespace(ns string) bool {
+	namespaces := l.Namespaces()
+	if namespaces == nil {
+		return true
+	}
+	for _, n := range namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
 }
 
 func (i *License) Less(j *License) bool {
@@ -60,3 +167,18 @@ func rankTier(t string) int {
 		return 1
 	}
 }
+
+func (q QuotaUsage) Unlimited() bool {
+	return q.Limit <= 0
+}
+
+// Remaining returns the unused amount of the quota. It returns -1 for unlimited quotas.
+func (q QuotaUsage) Remaining() int64 {
+	if q.Unlimited() {
+		return -1
+	}
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
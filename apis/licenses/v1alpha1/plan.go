@@ -0,0 +1,67 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+)
+
+// Plan is the tier of a license. Plans are ordered, so that products can enable
+// tiered functionality using AtLeast instead of comparing tier names.
+type Plan string
+
+const (
+	PlanUnknown    Plan = ""
+	PlanCommunity  Plan = "community"
+	PlanStandard   Plan = "standard"
+	PlanEnterprise Plan = "enterprise"
+)
+
+// ParsePlan returns the plan for a tier name. Unrecognized tiers are PlanUnknown.
+func ParsePlan(tier string) Plan {
+	switch p := Plan(strings.ToLower(strings.TrimSpace(tier))); p {
+	case PlanCommunity, PlanStandard, PlanEnterprise:
+		return p
+	}
+	return PlanUnknown
+}
+
+func (p Plan) rank() int {
+	switch p {
+	case PlanCommunity:
+		return 1
+	case PlanStandard:
+		return 2
+	case PlanEnterprise:
+		return 3
+	}
+	return 0
+}
+
+// AtLeast returns true if p includes the functionality of plan q.
+// PlanUnknown is not comparable, so AtLeast is always false if either plan is unknown.
+func (p Plan) AtLeast(q Plan) bool {
+	if p.rank() == 0 || q.rank() == 0 {
+		return false
+	}
+	return p.rank() >= q.rank()
+}
+
+// Plan returns the plan of the license, as encoded in its tier name.
+func (l License) Plan() Plan {
+	return ParsePlan(l.TierName)
+}
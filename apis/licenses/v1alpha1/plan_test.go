@@ -0,0 +1,44 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+func TestPlanAtLeast(t *testing.T) {
+	tests := []struct {
+		p, q v1alpha1.Plan
+		want bool
+	}{
+		{v1alpha1.PlanEnterprise, v1alpha1.PlanStandard, true},
+		{v1alpha1.PlanStandard, v1alpha1.PlanStandard, true},
+		{v1alpha1.PlanCommunity, v1alpha1.PlanEnterprise, false},
+		{v1alpha1.PlanUnknown, v1alpha1.PlanCommunity, false},
+		{v1alpha1.PlanEnterprise, v1alpha1.PlanUnknown, false},
+		{v1alpha1.PlanUnknown, v1alpha1.PlanUnknown, false},
+		{v1alpha1.ParsePlan("Enterprise "), v1alpha1.PlanEnterprise, true},
+		{v1alpha1.ParsePlan("gold"), v1alpha1.PlanCommunity, false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.AtLeast(tt.q); got != tt.want {
+			t.Errorf("Plan(%q).AtLeast(%q) = %v, want %v", tt.p, tt.q, got, tt.want)
+		}
+	}
+}
@@ -27,12 +27,14 @@ type License struct {
 	metav1.TypeMeta `json:",inline,omitempty"`
 
 	Data         []byte            `json:"-"`
+	Format       string            `json:"format,omitempty"` // x509 or jwt
 	Issuer       string            `json:"issuer,omitempty"` // byte.builders
 	ProductLine  string            `json:"productLine,omitempty"`
 	TierName     string            `json:"tierName,omitempty"`
 	PlanName     string            `json:"planName,omitempty"`
 	Features     []string          `json:"features,omitempty"`
 	FeatureFlags map[string]string `json:"featureFlags,omitempty"`
+	Limits       map[string]int64  `json:"limits,omitempty"`   // entitlements, eg. max nodes
 	Clusters     []string          `json:"clusters,omitempty"` // cluster_id ?
 	User         *User             `json:"user,omitempty"`
 	NotBefore    *metav1.Time      `json:"notBefore,omitempty"` // start of subscription start
@@ -40,6 +42,25 @@ type License struct {
 	ID           string            `json:"id,omitempty"`        // license ID
 	Status       LicenseStatus     `json:"status"`
 	Reason       string            `json:"reason"`
+	Contract     *Contract         `json:"contract,omitempty"` // set if the license was acquired from the issuer
+	// Trial is true for trial licenses.
+	Trial bool `json:"trial,omitempty"`
+	// TrialDaysRemaining is the number of started days left in the trial as of the verification of the license.
+	TrialDaysRemaining int `json:"trialDaysRemaining,omitempty"`
+	// AddOns are the IDs of the add-on licenses stacked on this license.
+	AddOns []string `json:"addOns,omitempty"`
+}
+
+// LicenseSummary is the license information products mirror into the status of their custom resources.
+type LicenseSummary struct {
+	ID                 string        `json:"id,omitempty"`
+	Status             LicenseStatus `json:"status"`
+	Reason             string        `json:"reason,omitempty"`
+	PlanName           string        `json:"planName,omitempty"`
+	Features           []string      `json:"features,omitempty"`
+	NotAfter           *metav1.Time  `json:"notAfter,omitempty"`
+	Trial              bool          `json:"trial,omitempty"`
+	TrialDaysRemaining int           `json:"trialDaysRemaining,omitempty"`
 }
 
 type User struct {
@@ -47,7 +68,7 @@ type User struct {
 	Email string `json:"email"`
 }
 
-// +kubebuilder:validation:Enum=unknown;active;invalid;canceled
+// +kubebuilder:validation:Enum=unknown;active;grace-period;invalid;canceled
 type LicenseStatus string
 
 const (
@@ -55,10 +76,50 @@ const (
 	LicenseActive   LicenseStatus = "active"
 	LicenseInvalid  LicenseStatus = "invalid"
 	LicenseCanceled LicenseStatus = "canceled"
+	// LicenseGracePeriod is used for a license that has expired recently, but is still accepted
+	// until its grace period ends.
+	LicenseGracePeriod LicenseStatus = "grace-period"
 )
 
 type Contract struct {
-	ID              string      `json:"id"`
-	StartTimestamp  metav1.Time `json:"startTimestamp"`
-	ExpiryTimestamp metav1.Time `json:"expiryTimestamp"`
+	ID              string       `json:"id"`
+	StartTimestamp  metav1.Time  `json:"startTimestamp"`
+	ExpiryTimestamp metav1.Time  `json:"expiryTimestamp"`
+	Support         *SupportPlan `json:"support,omitempty"`
+}
+
+// SupportPlan describes the support tier and SLA purchased under a contract.
+type SupportPlan struct {
+	Tier string `json:"tier"`
+	// Coverage is the support window, eg. 8x5 or 24x7
+	Coverage string `json:"coverage,omitempty"`
+	// ResponseTime is the guaranteed initial response time for critical issues
+	ResponseTime *metav1.Duration `json:"responseTime,omitempty"`
+	Channels     []string         `json:"channels,omitempty"`
+}
+
+// Quota describes the utilization of the entitlements purchased under a contract.
+type Quota struct {
+	ContractID string     `json:"contractID,omitempty"`
+	Clusters   QuotaUsage `json:"clusters"`
+	Nodes      QuotaUsage `json:"nodes"`
+}
+
+type QuotaUsage struct {
+	Used int64 `json:"used"`
+	// Limit is the purchased amount. 0 means unlimited.
+	Limit int64 `json:"limit,omitempty"`
+}
+
+// ClusterProfile contains signals used by the license issuer to detect clusters
+// cloned from the same etcd snapshot, since those share the kube-system namespace UID.
+type ClusterProfile struct {
+	ClusterUID string `json:"clusterUID"`
+	// NodeUIDs are sha256 hashes of the node UIDs
+	NodeUIDs                    []string     `json:"nodeUIDs,omitempty"`
+	CACertificateSerial         string       `json:"caCertificateSerial,omitempty"`
+	ClusterCreationTimestamp    *metav1.Time `json:"clusterCreationTimestamp,omitempty"`
+	OldestNodeCreationTimestamp *metav1.Time `json:"oldestNodeCreationTimestamp,omitempty"`
+	// Hash is the sha256 hash of all the collected signals
+	Hash string `json:"hash"`
 }
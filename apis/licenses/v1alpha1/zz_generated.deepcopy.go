@@ -22,14 +22,158 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLicense) DeepCopyInto(out *ClusterLicense) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLicense.
+func (in *ClusterLicense) DeepCopy() *ClusterLicense {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLicense)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterLicense) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLicenseList) DeepCopyInto(out *ClusterLicenseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterLicense, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLicenseList.
+func (in *ClusterLicenseList) DeepCopy() *ClusterLicenseList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLicenseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterLicenseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLicenseSpec) DeepCopyInto(out *ClusterLicenseSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLicenseSpec.
+func (in *ClusterLicenseSpec) DeepCopy() *ClusterLicenseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLicenseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLicenseStatus) DeepCopyInto(out *ClusterLicenseStatus) {
+	*out = *in
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLicenseStatus.
+func (in *ClusterLicenseStatus) DeepCopy() *ClusterLicenseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLicenseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProfile) DeepCopyInto(out *ClusterProfile) {
+	*out = *in
+	if in.NodeUIDs != nil {
+		in, out := &in.NodeUIDs, &out.NodeUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterCreationTimestamp != nil {
+		in, out := &in.ClusterCreationTimestamp, &out.ClusterCreationTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.OldestNodeCreationTimestamp != nil {
+		in, out := &in.OldestNodeCreationTimestamp, &out.OldestNodeCreationTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfile.
+func (in *ClusterProfile) DeepCopy() *ClusterProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Contract) DeepCopyInto(out *Contract) {
 	*out = *in
 	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
 	in.ExpiryTimestamp.DeepCopyInto(&out.ExpiryTimestamp)
+	if in.Support != nil {
+		in, out := &in.Support, &out.Support
+		*out = new(SupportPlan)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -64,6 +208,13 @@ func (in *License) DeepCopyInto(out *License) {
 			(*out)[key] = val
 		}
 	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]string, len(*in))
@@ -82,6 +233,16 @@ func (in *License) DeepCopyInto(out *License) {
 		in, out := &in.NotAfter, &out.NotAfter
 		*out = (*in).DeepCopy()
 	}
+	if in.Contract != nil {
+		in, out := &in.Contract, &out.Contract
+		*out = new(Contract)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddOns != nil {
+		in, out := &in.AddOns, &out.AddOns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -103,6 +264,91 @@ func (in *License) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseSummary) DeepCopyInto(out *LicenseSummary) {
+	*out = *in
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseSummary.
+func (in *LicenseSummary) DeepCopy() *LicenseSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Quota) DeepCopyInto(out *Quota) {
+	*out = *in
+	out.Clusters = in.Clusters
+	out.Nodes = in.Nodes
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Quota.
+func (in *Quota) DeepCopy() *Quota {
+	if in == nil {
+		return nil
+	}
+	out := new(Quota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupportPlan) DeepCopyInto(out *SupportPlan) {
+	*out = *in
+	if in.ResponseTime != nil {
+		in, out := &in.ResponseTime, &out.ResponseTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupportPlan.
+func (in *SupportPlan) DeepCopy() *SupportPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SupportPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -0,0 +1,58 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindLicenseStatus = "LicenseStatus"
+	ResourceLicenseStatus     = "licensestatus"
+	ResourceLicenseStatuses   = "licensestatuses"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=get,list
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LicenseStatus is a virtual resource served by the aggregated api server of a product.
+// It is computed by verifying the license of the product on every request and is never stored in etcd.
+// Its name is the product the license is verified for.
+type LicenseStatus struct {
+	metav1.TypeMeta   `json:",inline,omitempty"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Valid is true if the license is valid for the product in this cluster.
+	Valid   bool                    `json:"valid"`
+	License v1alpha1.LicenseSummary `json:"license"`
+	// Conditions are the Valid, Expired, WrongCluster and FeatureMissing conditions of the license.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LicenseStatusList is a list of LicenseStatuses.
+type LicenseStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []LicenseStatus `json:"items"`
+}
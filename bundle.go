@@ -0,0 +1,75 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// SplitLicenses splits a bundle of licenses, eg. the old and the renewed license during a
+// renewal overlap. Each PEM encoded license keeps the intermediate certificates following it.
+// JWT encoded licenses are separated by whitespace.
+func SplitLicenses(data []byte) [][]byte {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("-----")) {
+		var out [][]byte
+		for _, f := range bytes.Fields(data) {
+			if IsJWT(f) {
+				out = append(out, f)
+			}
+		}
+		if len(out) == 0 {
+			return [][]byte{data}
+		}
+		return out
+	}
+
+	var out [][]byte
+	var cur []byte
+	rest := data
+	for {
+		block, r := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		raw := bytes.TrimSpace(rest[:len(rest)-len(r)])
+		rest = r
+
+		// every certificate that is not a CA starts a new license
+		leaf := true
+		if block.Type == "CERTIFICATE" {
+			if cert, err := x509.ParseCertificate(block.Bytes); err == nil && cert.IsCA {
+				leaf = false
+			}
+		}
+		if leaf && cur != nil {
+			out = append(out, cur)
+			cur = nil
+		}
+		cur = append(cur, raw...)
+		cur = append(cur, '\n')
+	}
+	if cur != nil {
+		out = append(out, cur)
+	}
+	if len(out) == 0 {
+		return [][]byte{data}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"sync"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+// maxCachedLicenses bounds the memory used by a LicenseCache.
+const maxCachedLicenses = 16
+
+// LicenseCache remembers licenses that were decoded and whose signature was verified, keyed by the
+// sha256 checksum of the license and the trusted license CAs. Re-verifying an unchanged license then
+// skips PEM parsing and x509 chain building. All other checks, eg. the validity period and the cluster
+// binding, still run on every verification. A LicenseCache is safe for concurrent use.
+type LicenseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedLicense
+}
+
+type cachedLicense struct {
+	license v1alpha1.License
+	certs   []*x509.Certificate
+	token   string
+	claims  *LicenseClaims
+}
+
+func NewLicenseCache() *LicenseCache {
+	return &LicenseCache{entries: map[string]cachedLicense{}}
+}
+
+// Len returns the number of cached licenses.
+func (lc *LicenseCache) Len() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return len(lc.entries)
+}
+
+// cacheKey returns the checksum of the license and the trusted CAs.
+func cacheKey(opts ParserOptions) string {
+	h := sha256.New()
+	h.Write(opts.License)
+	for _, ca := range opts.TrustedCAs() {
+		h.Write(ca.Raw)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// restore fills c from the cache and returns true on a cache hit.
+func (lc *LicenseCache) restore(key string, c *CheckContext) bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	e, ok := lc.entries[key]
+	if !ok {
+		return false
+	}
+	c.License = *e.license.DeepCopy()
+	c.Certificates = e.certs
+	c.token = e.token
+	c.claims = e.claims
+	return true
+}
+
+// add caches the license decoded and verified in c.
+func (lc *LicenseCache) add(key string, c *CheckContext) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if len(lc.entries) >= maxCachedLicenses {
+		// licenses rarely change, so simply start over
+		lc.entries = map[string]cachedLicense{}
+	}
+	lc.entries[key] = cachedLicense{
+		license: *c.License.DeepCopy(),
+		certs:   c.Certificates,
+		token:   c.token,
+		claims:  c.claims,
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestLicenseCache(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	other := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := verifier.DecodeLicense(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := verifier.NewLicenseCache()
+	opts := verifier.VerifyOptions{
+		ParserOptions: verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     issuer.CACert,
+			License:    data,
+			Cache:      cache,
+		},
+		Features: "kubedb-enterprise",
+	}
+	first, err := verifier.CheckLicense(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := verifier.CheckLicense(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 || first.ID != second.ID || !sets.NewString(first.Features...).Equal(sets.NewString(second.Features...)) {
+		t.Errorf("CheckLicense() with cache = %v, want %v with one cached license, found %d", second, first, cache.Len())
+	}
+
+	// the cache must not skip the checks that don't depend on the license alone
+	expired := opts
+	expired.Clock = clocktesting.NewFakePassiveClock(decoded.NotAfter.Add(time.Hour))
+	if _, err := verifier.CheckLicense(expired); !errors.Is(err, verifier.ErrLicenseExpired) {
+		t.Errorf("CheckLicense() of a cached license after expiry error = %v, want %v", err, verifier.ErrLicenseExpired)
+	}
+	wrongCluster := opts
+	wrongCluster.ClusterUID = licensetest.WrongClusterUID
+	if _, err := verifier.CheckLicense(wrongCluster); !errors.Is(err, verifier.ErrWrongCluster) {
+		t.Errorf("CheckLicense() of a cached license for another cluster error = %v, want %v", err, verifier.ErrWrongCluster)
+	}
+	otherCA := opts
+	otherCA.CACert = other.CACert
+	if _, err := verifier.CheckLicense(otherCA); !errors.Is(err, verifier.ErrBadSignature) {
+		t.Errorf("CheckLicense() of a cached license with another CA error = %v, want %v", err, verifier.ErrBadSignature)
+	}
+}
@@ -18,37 +18,151 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/x509"
 	"encoding/json"
-	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"time"
 
-	"go.bytebuilders.dev/license-verifier/apis/licenses"
 	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
 	"go.bytebuilders.dev/license-verifier/info"
 
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"github.com/pkg/errors"
 )
 
+// DefaultTimeout is the time limit for requests to the license issuer, including reading the response body.
+const DefaultTimeout = 30 * time.Second
+
 type Client struct {
-	url        string
-	token      string
-	clusterUID string
+	url             string
+	registrationURL string
+	quotaURL        string
+	crlURL          string
+	verifyURL       string
+	usageURL        string
+	token           string
+	clusterUID      string
+	hc              *http.Client
+	rootCAs         *x509.CertPool
+	product         ProductInfo
+	timeout         time.Duration
+	// customTransport is set if the caller supplied the http client or transport
+	customTransport bool
 }
 
-func NewClient(baseURL, token, clusterUID string) (*Client, error) {
+// NewClient returns a client of the license issuer at baseURL, the AppsCode license issuer if empty.
+// Unless the caller supplies the http client or transport, the CA bundle in the file named by
+// EnvProxyCAFile is trusted in addition to the system roots.
+func NewClient(baseURL, token, clusterUID string, opts ...ClientOption) (*Client, error) {
 	u, err := info.LicenseIssuerAPIEndpoint(baseURL)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		url:        u,
-		token:      token,
-		clusterUID: clusterUID,
-	}, nil
+	r, err := info.RegistrationAPIEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	q, err := info.LicenseQuotaAPIEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	crl, err := info.LicenseRevocationListAPIEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	v, err := info.LicenseVerifyAPIEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := info.LicenseUsageAPIEndpoint(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		url:             u,
+		registrationURL: r,
+		quotaURL:        q,
+		crlURL:          crl,
+		verifyURL:       v,
+		usageURL:        usage,
+		token:           token,
+		clusterUID:      clusterUID,
+		hc:              http.DefaultClient,
+		product:         DefaultProductInfo(),
+		timeout:         DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if filename := os.Getenv(EnvProxyCAFile); filename != "" && !c.customTransport {
+		if err := c.AddProxyCAFile(filename); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
-func (c *Client) AcquireLicense(features []string) ([]byte, *v1alpha1.Contract, error) {
+// RegisterCluster registers the cluster with the license issuer.
+// Registering an already registered cluster is not an error.
+func (c *Client) RegisterCluster() error {
+	return c.RegisterClusterWithContext(context.TODO())
+}
+
+// RegisterClusterWithContext is like RegisterCluster but aborts the request when ctx is cancelled.
+func (c *Client) RegisterClusterWithContext(ctx context.Context) error {
+	opts := struct {
+		Cluster string `json:"cluster"`
+	}{
+		Cluster: c.clusterUID,
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.registrationURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(req, resp)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusConflict:
+		return nil
+	}
+	return statusError(req, resp, body, "Cluster", c.clusterUID)
+}
+
+// SetTimeout sets the time limit for requests to the license issuer. A zero timeout means no
+// time limit, so requests only end when their context is done.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// HTTPClient returns the http client used for requests to the license issuer, including the
+// proxy CA bundle, so that other services of the issuer, eg. OCSP responders, are reached the same way.
+func (c *Client) HTTPClient() *http.Client {
+	return c.hc
+}
+
+// AcquireLicense requests a license with the features for the cluster from the license issuer.
+// The request is canceled when ctx is done or the timeout of the client is exceeded.
+func (c *Client) AcquireLicense(ctx context.Context, features []string) ([]byte, *v1alpha1.Contract, error) {
 	opts := struct {
 		Cluster  string   `json:"cluster"`
 		Features []string `json:"features"`
@@ -61,7 +175,7 @@ func (c *Client) AcquireLicense(features []string) ([]byte, *v1alpha1.Contract,
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -70,36 +184,201 @@ func (c *Client) AcquireLicense(features []string) ([]byte, *v1alpha1.Contract,
 	if c.token != "" {
 		req.Header.Add("Authorization", "Bearer "+c.token)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(req, resp)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, nil, apierrors.NewGenericServerResponse(
-			resp.StatusCode,
-			http.MethodPost,
-			schema.GroupResource{Group: licenses.GroupName, Resource: "License"},
-			"",
-			string(body),
-			0,
-			false,
-		)
+		return nil, nil, statusError(req, resp, body, "License", "")
 	}
 
 	lc := struct {
 		Contract *v1alpha1.Contract `json:"contract,omitempty"`
 		License  []byte             `json:"license"`
 	}{}
+	// unknown fields are ignored, so that newer issuers can extend the response
 	err = json.Unmarshal(body, &lc)
+	if err == nil && len(lc.License) == 0 {
+		err = errors.New("license issuer response is missing the license")
+	}
 	if err != nil {
+		recordFailure(req, resp, body, err)
 		return nil, nil, err
 	}
 	return lc.License, lc.Contract, nil
 }
+
+// GetQuota returns the utilization of the entitlements purchased under the contract of this cluster.
+func (c *Client) GetQuota() (*v1alpha1.Quota, error) {
+	u, err := url.Parse(c.quotaURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("cluster", c.clusterUID)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(req, resp, body, "Quota", c.clusterUID)
+	}
+
+	var quota v1alpha1.Quota
+	err = json.Unmarshal(body, &quota)
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// GetRevocationList returns the PEM or DER encoded CRL of the licenses revoked by the issuer.
+// The caller must verify that it is signed by the license CA.
+func (c *Client) GetRevocationList() ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, c.crlURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(req, resp, body, "RevocationList", "")
+	}
+	return body, nil
+}
+
+// LicenseVerification is the verdict of the license issuer about a license.
+type LicenseVerification struct {
+	Status v1alpha1.LicenseStatus `json:"status"`
+	Reason string                 `json:"reason,omitempty"`
+	// Cluster is the cluster the license is currently assigned to. It differs from
+	// the cluster of the client if the license was transferred to another cluster.
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// VerifyLicense asks the license issuer whether the license is still valid for the cluster,
+// ie. it has not been revoked or transferred to another cluster.
+func (c *Client) VerifyLicense(license []byte) (*LicenseVerification, error) {
+	opts := struct {
+		Cluster string `json:"cluster"`
+		License []byte `json:"license"`
+	}{
+		Cluster: c.clusterUID,
+		License: license,
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.verifyURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(req, resp, body, "License", "")
+	}
+
+	var result LicenseVerification
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UsageReport is the anonymized usage of a product reported to the license issuer,
+// so that deployments beyond the purchased entitlements can be detected.
+type UsageReport struct {
+	// ClusterHash is the hex encoded sha256 hash of the cluster UID.
+	ClusterHash string   `json:"clusterHash"`
+	Product     string   `json:"product,omitempty"`
+	Features    []string `json:"features,omitempty"`
+	Nodes       int64    `json:"nodes,omitempty"`
+	// LicenseID is the serial number of the license in use, if any.
+	LicenseID string `json:"licenseID,omitempty"`
+	// Profile helps the license issuer detect cloned clusters. It is only sent if the user consents.
+	Profile *v1alpha1.ClusterProfile `json:"profile,omitempty"`
+}
+
+// ReportUsage sends the usage report to the license issuer.
+func (c *Client) ReportUsage(report UsageReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.usageURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(req, resp)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	}
+	return statusError(req, resp, body, "Usage", "")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	maxTranscripts     = 10
+	maxTranscriptBody  = 4 * 1024
+	redactedHeaderText = "<redacted>"
+)
+
+// Transcript is a sanitized record of a failed call to the license issuer.
+// Transcripts are meant to be included in support bundles.
+type Transcript struct {
+	Timestamp  time.Time         `json:"timestamp"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Header     http.Header       `json:"header,omitempty"`
+	StatusCode int               `json:"statusCode,omitempty"`
+	Response   string            `json:"response,omitempty"`
+	Error      string            `json:"error"`
+	TLSChain   []CertificateInfo `json:"tlsChain,omitempty"`
+}
+
+type CertificateInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serialNumber"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+}
+
+var diagnostics = struct {
+	mu          sync.Mutex
+	transcripts []Transcript
+}{}
+
+// FailedRequests returns the transcripts of the most recent failed calls to the license issuer.
+func FailedRequests() []Transcript {
+	diagnostics.mu.Lock()
+	defer diagnostics.mu.Unlock()
+	return append([]Transcript(nil), diagnostics.transcripts...)
+}
+
+// WriteDiagnostics writes the transcripts of the most recent failed calls to the license issuer as json.
+func WriteDiagnostics(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(FailedRequests())
+}
+
+// readBody reads the response body and records a transcript if it fails.
+func readBody(req *http.Request, resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		recordFailure(req, resp, nil, err)
+	}
+	return body, err
+}
+
+// statusError returns the error for an unexpected response status of the license issuer
+// and records a transcript of the request.
+func statusError(req *http.Request, resp *http.Response, body []byte, resource, name string) error {
+	err := apierrors.NewGenericServerResponse(
+		resp.StatusCode,
+		req.Method,
+		schema.GroupResource{Group: licenses.GroupName, Resource: resource},
+		name,
+		string(body),
+		0,
+		false,
+	)
+	recordFailure(req, resp, body, err)
+	return err
+}
+
+// recordFailure keeps a sanitized transcript of a failed call to the license issuer.
+// Every failure is recorded where it is detected: transport errors by Client.do and
+// unexpected responses by readBody, statusError and the callers decoding the response.
+func recordFailure(req *http.Request, resp *http.Response, body []byte, err error) {
+	t := Transcript{
+		Timestamp: time.Now().UTC(),
+		Method:    req.Method,
+		URL:       req.URL.Redacted(),
+		Header:    req.Header.Clone(),
+		Error:     err.Error(),
+	}
+	if t.Header.Get("Authorization") != "" {
+		t.Header.Set("Authorization", redactedHeaderText)
+	}
+	if resp != nil {
+		t.StatusCode = resp.StatusCode
+		if resp.TLS != nil {
+			t.TLSChain = certificateInfo(resp.TLS.PeerCertificates)
+		}
+	}
+	if len(body) > maxTranscriptBody {
+		body = body[:maxTranscriptBody]
+	}
+	t.Response = string(body)
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		t.TLSChain = certificateInfo(certErr.UnverifiedCertificates)
+	}
+
+	diagnostics.mu.Lock()
+	defer diagnostics.mu.Unlock()
+	diagnostics.transcripts = append(diagnostics.transcripts, t)
+	if n := len(diagnostics.transcripts); n > maxTranscripts {
+		diagnostics.transcripts = diagnostics.transcripts[n-maxTranscripts:]
+	}
+}
+
+func certificateInfo(certs []*x509.Certificate) []CertificateInfo {
+	out := make([]CertificateInfo, 0, len(certs))
+	for _, c := range certs {
+		out = append(out, CertificateInfo{
+			Subject:      c.Subject.String(),
+			Issuer:       c.Issuer.String(),
+			SerialNumber: c.SerialNumber.String(),
+			NotBefore:    c.NotBefore,
+			NotAfter:     c.NotAfter,
+		})
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/client"
+)
+
+func TestFailedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "issuer unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient(srv.URL, "secret-token", "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"RegisterCluster", c.RegisterCluster},
+		{"GetRevocationList", func() error {
+			_, err := c.GetRevocationList()
+			return err
+		}},
+		{"ReportUsage", func() error {
+			return c.ReportUsage(client.UsageReport{ClusterHash: "hash"})
+		}},
+		{"AcquireLicense", func() error {
+			_, _, err := c.AcquireLicense(context.TODO(), []string{"kubedb-enterprise"})
+			return err
+		}},
+	}
+	for _, tt := range calls {
+		before := len(client.FailedRequests())
+		if err := tt.call(); err == nil {
+			t.Fatalf("%s() error = nil, want error", tt.name)
+		}
+		transcripts := client.FailedRequests()
+		if len(transcripts) != before+1 && len(transcripts) != 10 {
+			t.Fatalf("%s() recorded %d transcripts, want 1", tt.name, len(transcripts)-before)
+		}
+		got := transcripts[len(transcripts)-1]
+		if got.StatusCode != http.StatusServiceUnavailable || got.Response != "issuer unavailable\n" {
+			t.Errorf("%s() transcript = %+v, want the response of the issuer", tt.name, got)
+		}
+		if auth := got.Header.Get("Authorization"); auth != "<redacted>" {
+			t.Errorf("%s() transcript Authorization header = %q, want it redacted", tt.name, auth)
+		}
+	}
+
+	srv.Close()
+	if _, err := c.GetRevocationList(); err == nil {
+		t.Fatalf("GetRevocationList() from a stopped issuer error = nil, want error")
+	}
+	transcripts := client.FailedRequests()
+	if got := transcripts[len(transcripts)-1]; got.StatusCode != 0 || got.Error == "" {
+		t.Errorf("GetRevocationList() transcript = %+v, want the transport error", got)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientOption customizes a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient sends requests to the license issuer with hc instead of http.DefaultClient,
+// eg. to go through a proxy, trust custom CAs or instrument requests.
+// The CA bundle named by EnvProxyCAFile is not added to the transport of hc.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		if hc != nil {
+			c.hc = hc
+			c.customTransport = true
+		}
+	}
+}
+
+// WithTransport sends requests to the license issuer through rt instead of http.DefaultTransport.
+// The CA bundle named by EnvProxyCAFile is not added to rt.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		hc := *c.hc
+		hc.Transport = rt
+		c.hc = &hc
+		c.customTransport = true
+	}
+}
+
+// WithTimeout sets the time limit for requests to the license issuer. See SetTimeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithProductInfo overrides the product identification sent to the license issuer. See SetProductInfo.
+func WithProductInfo(p ProductInfo) ClientOption {
+	return func(c *Client) {
+		c.product = p
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+)
+
+const clusterUID = "5d3f2a1b-7c6e-4f8a-b9d0-1e2f3a4b5c6d"
+
+type countingTransport struct {
+	rt       http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.rt.RoundTrip(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	srv := licensetest.NewIssuerServer(issuer)
+	defer srv.Close()
+
+	tr := &countingTransport{rt: http.DefaultTransport}
+	c, err := client.NewClient(srv.URL, "token", clusterUID,
+		client.WithHTTPClient(&http.Client{Transport: tr}),
+		client.WithTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.AcquireLicense(context.Background(), []string{"kubedb-enterprise"}); err != nil {
+		t.Fatal(err)
+	}
+	if tr.requests != 2 {
+		t.Errorf("transport requests = %d, want 2", tr.requests)
+	}
+
+	c, err = client.NewClient(srv.URL, "token", clusterUID, client.WithTransport(tr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddProxyCABundle(issuer.CACertPEM()); err == nil {
+		t.Error("AddProxyCABundle() error = nil, want unsupported transport")
+	}
+}
+
+func TestProxyCAFileWithCustomTransport(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	filename := filepath.Join(t.TempDir(), "proxy-ca.crt")
+	if err := os.WriteFile(filename, issuer.CACertPEM(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(client.EnvProxyCAFile, filename)
+
+	if _, err := client.NewClient("", "token", clusterUID); err != nil {
+		t.Errorf("NewClient() error = %v", err)
+	}
+	// the proxy CA bundle is only added to transports built by the client
+	tr := &countingTransport{rt: http.DefaultTransport}
+	if _, err := client.NewClient("", "token", clusterUID, client.WithTransport(tr)); err != nil {
+		t.Errorf("NewClient(WithTransport) error = %v", err)
+	}
+	if _, err := client.NewClient("", "token", clusterUID, client.WithHTTPClient(&http.Client{Transport: tr})); err != nil {
+		t.Errorf("NewClient(WithHTTPClient) error = %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// EnvProxyCAFile names a file with the PEM encoded CA certificates of a TLS intercepting proxy.
+// If set, these certificates are trusted for connections to the license issuer, in addition
+// to the system roots.
+const EnvProxyCAFile = "LICENSE_ISSUER_PROXY_CA_FILE"
+
+// AddProxyCABundle trusts the PEM encoded CA certificates of a TLS intercepting proxy,
+// in addition to the system roots, for connections to the license issuer.
+// These certificates are not used to verify licenses. The transport of the client must be
+// an *http.Transport, which is cloned.
+func (c *Client) AddProxyCABundle(bundle []byte) error {
+	rt := c.hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return errors.Errorf("can't add proxy CA bundle to transport of type %T", rt)
+	}
+	tr := base.Clone()
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	// keep the CAs trusted by a custom transport
+	var pool *x509.CertPool
+	if roots := tr.TLSClientConfig.RootCAs; roots != nil {
+		pool = roots.Clone()
+	} else {
+		var err error
+		pool, err = x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+	}
+	if !pool.AppendCertsFromPEM(bundle) {
+		return errors.New("proxy CA bundle does not contain any PEM encoded certificate")
+	}
+	tr.TLSClientConfig.RootCAs = pool
+	c.rootCAs = pool
+
+	hc := *c.hc
+	hc.Transport = tr
+	c.hc = &hc
+	return nil
+}
+
+// AddProxyCAFile trusts the CA certificates in the PEM encoded file for connections to the license issuer.
+func (c *Client) AddProxyCAFile(filename string) error {
+	bundle, err := os.ReadFile(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to read proxy CA bundle")
+	}
+	return c.AddProxyCABundle(bundle)
+}
+
+// do sends the request and explains certificate errors, which usually mean that
+// a TLS intercepting proxy sits between the cluster and the license issuer.
+// The issuer api version is negotiated and the client is identified for every request.
+// The request is limited to the timeout of the client. Transport errors are recorded for FailedRequests.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set(HeaderAPIVersion, strconv.Itoa(APIVersion))
+	c.setClientHeaders(req)
+	hc := c.hc
+	if c.timeout > 0 && (hc.Timeout == 0 || hc.Timeout > c.timeout) {
+		// unlike a context deadline, http.Client.Timeout also covers reading the response body
+		limited := *hc
+		limited.Timeout = c.timeout
+		hc = &limited
+	}
+	resp, err := hc.Do(req)
+	if err == nil {
+		if err := checkAPIVersion(resp); err != nil {
+			recordFailure(req, resp, nil, err)
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+	var authErr x509.UnknownAuthorityError
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &authErr) || errors.As(err, &verifyErr) {
+		msg := "certificate presented for %s is not trusted; if the cluster reaches the license issuer through a TLS intercepting proxy, provide the CA bundle of the proxy"
+		if c.rootCAs != nil {
+			msg = "certificate presented for %s is not trusted by the system roots or the proxy CA bundle"
+		}
+		err = errors.Wrapf(err, msg, req.URL.Host)
+	}
+	recordFailure(req, nil, nil, err)
+	return nil, err
+}
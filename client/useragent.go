@@ -0,0 +1,88 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/info"
+)
+
+const (
+	HeaderClientProduct         = "X-Client-Product"
+	HeaderClientProductUID      = "X-Client-Product-Uid"
+	HeaderClientProductVersion  = "X-Client-Product-Version"
+	HeaderClientVerifierVersion = "X-Client-Verifier-Version"
+	// HeaderClientCluster carries the sha256 hash of the cluster UID, so that the issuer can
+	// attribute traffic to a cluster without learning its UID from every request.
+	HeaderClientCluster = "X-Client-Cluster"
+)
+
+// ProductInfo identifies the product calling the license issuer.
+type ProductInfo struct {
+	// Name defaults to the features of the product set via ldflags.
+	Name string
+	// UID defaults to the product UID set via ldflags.
+	UID     string
+	Version string
+}
+
+// DefaultProductInfo returns the product info set via ldflags.
+func DefaultProductInfo() ProductInfo {
+	return ProductInfo{
+		Name: strings.Join(info.Features(), ","),
+		UID:  info.ProductUID,
+	}
+}
+
+// SetProductInfo overrides the product identification sent to the license issuer.
+func (c *Client) SetProductInfo(p ProductInfo) {
+	c.product = p
+}
+
+func (c *Client) setClientHeaders(req *http.Request) {
+	p := c.product
+	verifierVersion := info.LibraryVersion()
+
+	ua := "license-verifier/" + verifierVersion
+	if p.Name != "" {
+		product := p.Name
+		if p.Version != "" {
+			product += "/" + p.Version
+		}
+		ua = fmt.Sprintf("%s (%s)", ua, product)
+	}
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set(HeaderClientVerifierVersion, verifierVersion)
+	if p.Name != "" {
+		req.Header.Set(HeaderClientProduct, p.Name)
+	}
+	if p.UID != "" {
+		req.Header.Set(HeaderClientProductUID, p.UID)
+	}
+	if p.Version != "" {
+		req.Header.Set(HeaderClientProductVersion, p.Version)
+	}
+	if c.clusterUID != "" {
+		h := sha256.Sum256([]byte(c.clusterUID))
+		req.Header.Set(HeaderClientCluster, hex.EncodeToString(h[:]))
+	}
+}
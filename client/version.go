@@ -0,0 +1,62 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderAPIVersion carries the issuer api version spoken by the client in requests
+	// and by the issuer in responses.
+	HeaderAPIVersion = "X-License-Api-Version"
+	// HeaderMinAPIVersion carries the oldest client api version supported by the issuer.
+	HeaderMinAPIVersion = "X-License-Api-Min-Version"
+
+	// APIVersion is the issuer api version spoken by this client.
+	APIVersion = 1
+	// MinServerAPIVersion is the oldest issuer api version supported by this client.
+	MinServerAPIVersion = 1
+)
+
+var (
+	ErrClientTooOld = errors.New("license issuer requires a newer version of the license verifier")
+	ErrServerTooOld = errors.New("license issuer is too old for this version of the license verifier")
+)
+
+// checkAPIVersion detects version skew between the client and the issuer. Issuers that
+// don't report their api version are assumed to be compatible.
+func checkAPIVersion(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUpgradeRequired {
+		return errors.Wrapf(ErrClientTooOld, "client api version %d", APIVersion)
+	}
+	if v, ok := headerVersion(resp, HeaderMinAPIVersion); ok && v > APIVersion {
+		return errors.Wrapf(ErrClientTooOld, "client api version %d, issuer requires at least %d", APIVersion, v)
+	}
+	if v, ok := headerVersion(resp, HeaderAPIVersion); ok && v < MinServerAPIVersion {
+		return errors.Wrapf(ErrServerTooOld, "issuer api version %d, client requires at least %d", v, MinServerAPIVersion)
+	}
+	return nil
+}
+
+func headerVersion(resp *http.Response, key string) (int, bool) {
+	v, err := strconv.Atoi(resp.Header.Get(key))
+	return v, err == nil
+}
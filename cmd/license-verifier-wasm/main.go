@@ -0,0 +1,100 @@
+//go:build js && wasm
+
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command license-verifier-wasm exposes the license verifier to JavaScript, so that
+// web portals can preview a license before it is uploaded.
+//
+// It registers a global function
+//
+//	previewLicense(license, caCert, clusterUID) => string
+//
+// that returns the json encoded Preview of the license. caCert and clusterUID are optional;
+// without caCert the license is only decoded, without clusterUID the cluster binding is not checked.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// Preview is the result of previewing a license.
+type Preview struct {
+	License v1alpha1.License       `json:"license"`
+	Format  string                 `json:"format"`
+	Checks  []verifier.CheckResult `json:"checks,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+func previewLicense(license, caCert []byte, clusterUID string) Preview {
+	p := Preview{Format: verifier.LicenseFormat(license)}
+
+	l, err := verifier.DecodeLicense(license)
+	if err != nil || len(caCert) == 0 {
+		p.License = l
+		if err != nil {
+			p.Error = err.Error()
+		}
+		return p
+	}
+
+	ca, err := info.ParseCertificate(caCert)
+	if err != nil {
+		p.License = l
+		p.Error = err.Error()
+		return p
+	}
+	// the product is not known before the license is uploaded
+	checks := verifier.DefaultPipeline().Without(verifier.CheckProduct, verifier.CheckLimits)
+	if clusterUID == "" {
+		checks = checks.Without(verifier.CheckClusterBinding)
+	}
+	p.License, p.Checks, err = verifier.RunChecks(verifier.VerifyOptions{
+		ParserOptions: verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     ca,
+			License:    license,
+		},
+		Checks: checks,
+	})
+	if err != nil {
+		p.Error = err.Error()
+	}
+	return p
+}
+
+func arg(args []js.Value, i int) string {
+	if i < len(args) && args[i].Type() == js.TypeString {
+		return args[i].String()
+	}
+	return ""
+}
+
+func main() {
+	js.Global().Set("previewLicense", js.FuncOf(func(this js.Value, args []js.Value) any {
+		p := previewLicense([]byte(arg(args, 0)), []byte(arg(args, 1)), arg(args, 2))
+		data, _ := json.Marshal(p) // Preview always marshals
+		return js.ValueOf(string(data))
+	}))
+	// keep the exported function available
+	select {}
+}
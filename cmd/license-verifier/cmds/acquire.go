@@ -0,0 +1,75 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdAcquire() *cobra.Command {
+	var (
+		issuerURL  string
+		token      string
+		clusterUID string
+		features   string
+		file       string
+		timeout    time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:     "acquire",
+		Short:   "Acquire a license for a cluster from the license issuer",
+		Example: `  license-verifier acquire --token $TOKEN --cluster-uid 1d0dc5a9-3b8a-4d94-8c3f-6f2b0c1a9e7d --features kubedb-enterprise -f license.txt`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			lc, err := client.NewClient(issuerURL, token, clusterUID)
+			if err != nil {
+				return err
+			}
+			lc.SetTimeout(timeout)
+			license, _, err := lc.AcquireLicense(cmd.Context(), info.ParseFeatures(features))
+			if err != nil {
+				return err
+			}
+			if file == "" {
+				_, err = cmd.OutOrStdout().Write(license)
+				return err
+			}
+			if err := os.WriteFile(file, license, 0o600); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "license written to %s\n", file)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&issuerURL, "issuer-url", "", "URL of the license issuer. Defaults to the AppsCode license issuer")
+	cmd.Flags().StringVar(&token, "token", "", "API token of the license issuer")
+	cmd.Flags().StringVar(&clusterUID, "cluster-uid", "", "UID of the kube-system namespace of the cluster")
+	cmd.Flags().StringVar(&features, "features", "", "Comma separated features to license")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to write the license to. Defaults to stdout")
+	cmd.Flags().DurationVar(&timeout, "timeout", client.DefaultTimeout, "Time limit for the request to the license issuer. Zero means no limit")
+	_ = cmd.MarkFlagRequired("token")
+	_ = cmd.MarkFlagRequired("cluster-uid")
+	_ = cmd.MarkFlagRequired("features")
+	return cmd
+}
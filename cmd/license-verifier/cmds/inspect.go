@@ -0,0 +1,55 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func NewCmdInspect() *cobra.Command {
+	var (
+		file   string
+		output string
+	)
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Print the details of a license file without verifying it",
+		Example: `  license-verifier inspect -f license.txt
+  license-verifier inspect -f license.txt -o yaml
+  license-verifier inspect -f license.txt -o go-template='{{.serialNumber}} {{.daysRemaining}}'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			in, err := verifier.InspectLicense(data, time.Now())
+			if err != nil {
+				return err
+			}
+			return verifier.PrintInspection(cmd.OutOrStdout(), in, output)
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to the license file")
+	cmd.Flags().StringVarP(&output, "output", "o", verifier.OutputTable, "Output format, one of json, yaml, table or go-template=<template>")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
@@ -0,0 +1,73 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cmds implements the commands of the license-verifier binary, so that other CLIs,
+// eg. the kubectl license plugin, can embed them.
+package cmds
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/spf13/cobra"
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// NewRootCmd returns the license-verifier command, which lets support engineers debug licensing without writing Go.
+func NewRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "license-verifier",
+		Short:             "Verify, inspect and acquire AppsCode licenses",
+		SilenceUsage:      true,
+		DisableAutoGenTag: true,
+	}
+	cmd.AddCommand(NewCmdVerify())
+	cmd.AddCommand(NewCmdInspect())
+	cmd.AddCommand(NewCmdAcquire())
+	cmd.AddCommand(NewCmdSelfTest())
+	cmd.AddCommand(NewCmdVersion())
+	return cmd
+}
+
+func NewCmdSelfTest() *cobra.Command {
+	return &cobra.Command{
+		Use:   "selftest",
+		Short: "Check the license settings compiled into the binary",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := verifier.SelfTest(); err != nil {
+				return fmt.Errorf("self test failed: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "self test passed")
+			return nil
+		},
+	}
+}
+
+func NewCmdVersion() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the license verifier version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(info.Version())
+		},
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// ClusterUIDFunc returns the UID of the cluster a license is verified for, eg. by reading the
+// kube-system namespace of the current cluster.
+type ClusterUIDFunc func(ctx context.Context) (string, error)
+
+func NewCmdVerify() *cobra.Command {
+	return NewCmdVerifyForCluster(nil)
+}
+
+// NewCmdVerifyForCluster returns the verify command. Unless --cluster-uid is set, the license is verified
+// for the cluster returned by clusterUIDFn. If clusterUIDFn is nil, --cluster-uid is required.
+func NewCmdVerifyForCluster(clusterUIDFn ClusterUIDFunc) *cobra.Command {
+	var (
+		file       string
+		clusterUID string
+		features   string
+		caCertFile string
+		preflight  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a license file for a cluster",
+		Example: `  license-verifier verify -f license.txt --cluster-uid 1d0dc5a9-3b8a-4d94-8c3f-6f2b0c1a9e7d
+  license-verifier verify -f license.txt --cluster-uid 1d0dc5a9-3b8a-4d94-8c3f-6f2b0c1a9e7d --features kubedb-enterprise
+  license-verifier verify -f license.txt --cluster-uid 1d0dc5a9-3b8a-4d94-8c3f-6f2b0c1a9e7d --preflight`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if clusterUID == "" && clusterUIDFn != nil {
+				var err error
+				if clusterUID, err = clusterUIDFn(cmd.Context()); err != nil {
+					return err
+				}
+			}
+			opts, err := NewVerifyOptions(file, clusterUID, features)
+			if err != nil {
+				return err
+			}
+			if caCertFile != "" {
+				if opts.CACert, err = os.ReadFile(caCertFile); err != nil {
+					return err
+				}
+			}
+			if preflight {
+				r := verifier.Preflight(opts)
+				if err := r.Print(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+				if r.Error != "" {
+					return fmt.Errorf("license is not valid: %s", r.Error)
+				}
+				return nil
+			}
+			license, err := verifier.VerifyLicense(opts)
+			if err != nil {
+				return fmt.Errorf("license is not valid: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "license %s is valid for cluster %s\n", license.ID, clusterUID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to the license file")
+	clusterUIDUsage := "UID of the kube-system namespace of the cluster"
+	if clusterUIDFn != nil {
+		clusterUIDUsage += ". Defaults to the current cluster"
+	}
+	cmd.Flags().StringVar(&clusterUID, "cluster-uid", "", clusterUIDUsage)
+	cmd.Flags().StringVar(&features, "features", "", "Comma separated features the license must include. Defaults to any feature of the license")
+	cmd.Flags().StringVar(&caCertFile, "ca-cert", "", "Path to the license CA certificate. Defaults to the license CA of the product")
+	cmd.Flags().BoolVar(&preflight, "preflight", false, "Print the result of every verification check")
+	_ = cmd.MarkFlagRequired("file")
+	if clusterUIDFn == nil {
+		_ = cmd.MarkFlagRequired("cluster-uid")
+	}
+	return cmd
+}
+
+// NewVerifyOptions reads the license file and returns the options to verify it for the cluster.
+// If features is empty, the license is only checked for the cluster.
+func NewVerifyOptions(file, clusterUID, features string) (verifier.Options, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return verifier.Options{}, err
+	}
+	if features == "" {
+		decoded, err := verifier.DecodeLicense(data)
+		if err != nil {
+			return verifier.Options{}, err
+		}
+		features = strings.Join(decoded.Features, ",")
+	}
+	return verifier.Options{
+		ClusterUID: clusterUID,
+		Features:   features,
+		License:    data,
+	}, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types of a LicenseStatus.
+const (
+	ConditionValid          = "Valid"
+	ConditionExpired        = "Expired"
+	ConditionWrongCluster   = "WrongCluster"
+	ConditionFeatureMissing = "FeatureMissing"
+)
+
+// Condition reasons of a LicenseStatus.
+const (
+	ReasonLicenseActive      = "LicenseActive"
+	ReasonGracePeriod        = "GracePeriod"
+	ReasonLicenseExpired     = "LicenseExpired"
+	ReasonLicenseNotExpired  = "LicenseNotExpired"
+	ReasonWrongCluster       = "WrongCluster"
+	ReasonClusterMatched     = "ClusterMatched"
+	ReasonFeatureMissing     = "FeatureMissing"
+	ReasonFeaturesCovered    = "FeaturesCovered"
+	ReasonBadSignature       = "BadSignature"
+	ReasonMalformedLicense   = "MalformedLicense"
+	ReasonLimitExceeded      = "LimitExceeded"
+	ReasonNamespaceScope     = "NamespaceNotCovered"
+	ReasonVersionMismatch    = "VersionMismatch"
+	ReasonLicenseCanceled    = "LicenseCanceled"
+	ReasonLicenseInvalid     = "LicenseInvalid"
+	ReasonVerificationFailed = "VerificationFailed"
+)
+
+// LicenseStatus describes the outcome of a license verification with machine-readable conditions,
+// so that products can copy them into the status of their custom resources.
+type LicenseStatus struct {
+	License    v1alpha1.LicenseSummary `json:"license"`
+	Conditions []metav1.Condition      `json:"conditions,omitempty"`
+}
+
+// failureReasons maps the sentinel errors to the reason of the Valid condition.
+var failureReasons = []struct {
+	err    error
+	reason string
+}{
+	{ErrLicenseExpired, ReasonLicenseExpired},
+	{ErrWrongCluster, ReasonWrongCluster},
+	{ErrProductMismatch, ReasonFeatureMissing},
+	{ErrBadSignature, ReasonBadSignature},
+	{ErrMalformedLicense, ReasonMalformedLicense},
+	{ErrLimitExceeded, ReasonLimitExceeded},
+	{ErrNamespaceScope, ReasonNamespaceScope},
+	{ErrVersionMismatch, ReasonVersionMismatch},
+}
+
+// NewLicenseStatus returns the status of a license verified by CheckLicense.
+// The Valid condition is always known. The WrongCluster, Expired and FeatureMissing conditions are
+// checked in that order, so they are Unknown if verification failed before they were checked.
+func NewLicenseStatus(license v1alpha1.License, err error, now time.Time) LicenseStatus {
+	ts := metav1.NewTime(now)
+	cond := func(t string, status metav1.ConditionStatus, reason, msg string) metav1.Condition {
+		return metav1.Condition{Type: t, Status: status, Reason: reason, Message: msg, LastTransitionTime: ts}
+	}
+
+	if err == nil {
+		valid := cond(ConditionValid, metav1.ConditionTrue, ReasonLicenseActive, "")
+		expired := cond(ConditionExpired, metav1.ConditionFalse, ReasonLicenseNotExpired, "")
+		if license.Status == v1alpha1.LicenseGracePeriod {
+			valid = cond(ConditionValid, metav1.ConditionTrue, ReasonGracePeriod, license.Reason)
+			expired = cond(ConditionExpired, metav1.ConditionTrue, ReasonGracePeriod, license.Reason)
+		}
+		return LicenseStatus{
+			License: license.Summary(),
+			Conditions: []metav1.Condition{
+				valid,
+				cond(ConditionWrongCluster, metav1.ConditionFalse, ReasonClusterMatched, ""),
+				expired,
+				cond(ConditionFeatureMissing, metav1.ConditionFalse, ReasonFeaturesCovered, ""),
+			},
+		}
+	}
+
+	msg := err.Error()
+	reason := ReasonVerificationFailed
+	switch license.Status {
+	case v1alpha1.LicenseCanceled:
+		reason = ReasonLicenseCanceled
+	case v1alpha1.LicenseInvalid:
+		reason = ReasonLicenseInvalid
+	}
+	for _, r := range failureReasons {
+		if errors.Is(err, r.err) {
+			reason = r.reason
+			break
+		}
+	}
+
+	// the checks that passed before the failing one are False, the ones after it are Unknown
+	checks := []struct {
+		condition string
+		err       error
+		reason    string
+		passed    string
+	}{
+		{ConditionWrongCluster, ErrWrongCluster, ReasonWrongCluster, ReasonClusterMatched},
+		{ConditionExpired, ErrLicenseExpired, ReasonLicenseExpired, ReasonLicenseNotExpired},
+		{ConditionFeatureMissing, ErrProductMismatch, ReasonFeatureMissing, ReasonFeaturesCovered},
+	}
+	checked := license.Status == v1alpha1.LicenseInvalid || license.Status == v1alpha1.LicenseCanceled
+	if errors.Is(err, ErrBadSignature) || errors.Is(err, ErrMalformedLicense) {
+		checked = false
+	}
+	status := LicenseStatus{
+		License:    license.Summary(),
+		Conditions: []metav1.Condition{cond(ConditionValid, metav1.ConditionFalse, reason, msg)},
+	}
+	for _, c := range checks {
+		switch {
+		case errors.Is(err, c.err):
+			status.Conditions = append(status.Conditions, cond(c.condition, metav1.ConditionTrue, c.reason, msg))
+			checked = false
+		case checked:
+			status.Conditions = append(status.Conditions, cond(c.condition, metav1.ConditionFalse, c.passed, ""))
+		default:
+			status.Conditions = append(status.Conditions, cond(c.condition, metav1.ConditionUnknown, ReasonVerificationFailed, msg))
+		}
+	}
+	return status
+}
+
+// SetConditions copies the conditions into conditions, eg. the status conditions of a custom resource.
+// The LastTransitionTime of a condition is kept if its status did not change.
+func (s LicenseStatus) SetConditions(conditions *[]metav1.Condition) {
+	for _, c := range s.Conditions {
+		meta.SetStatusCondition(conditions, c)
+	}
+}
+
+// FindCondition returns the condition of the given type, or nil if it is not set.
+func (s LicenseStatus) FindCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(s.Conditions, conditionType)
+}
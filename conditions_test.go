@@ -0,0 +1,95 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLicenseStatus(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	now := time.Now()
+
+	tests := []struct {
+		profile  string
+		features string
+		want     map[string]metav1.ConditionStatus
+		reason   string
+	}{
+		{licensetest.ProfileEnterprise, "kubedb-enterprise", map[string]metav1.ConditionStatus{
+			verifier.ConditionValid:          metav1.ConditionTrue,
+			verifier.ConditionWrongCluster:   metav1.ConditionFalse,
+			verifier.ConditionExpired:        metav1.ConditionFalse,
+			verifier.ConditionFeatureMissing: metav1.ConditionFalse,
+		}, verifier.ReasonLicenseActive},
+		{licensetest.ProfileWrongCluster, "kubedb-enterprise", map[string]metav1.ConditionStatus{
+			verifier.ConditionValid:          metav1.ConditionFalse,
+			verifier.ConditionWrongCluster:   metav1.ConditionTrue,
+			verifier.ConditionExpired:        metav1.ConditionUnknown,
+			verifier.ConditionFeatureMissing: metav1.ConditionUnknown,
+		}, verifier.ReasonWrongCluster},
+		{licensetest.ProfileExpired, "kubedb-enterprise", map[string]metav1.ConditionStatus{
+			verifier.ConditionValid:          metav1.ConditionFalse,
+			verifier.ConditionWrongCluster:   metav1.ConditionFalse,
+			verifier.ConditionExpired:        metav1.ConditionTrue,
+			verifier.ConditionFeatureMissing: metav1.ConditionUnknown,
+		}, verifier.ReasonLicenseExpired},
+		{licensetest.ProfileFeatureLimited, "stash-enterprise", map[string]metav1.ConditionStatus{
+			verifier.ConditionValid:          metav1.ConditionFalse,
+			verifier.ConditionWrongCluster:   metav1.ConditionFalse,
+			verifier.ConditionExpired:        metav1.ConditionFalse,
+			verifier.ConditionFeatureMissing: metav1.ConditionTrue,
+		}, verifier.ReasonFeatureMissing},
+	}
+	for _, tt := range tests {
+		data, err := issuer.IssueProfile(clusterUID, tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		license, err := verifier.CheckLicense(verifier.VerifyOptions{
+			ParserOptions: verifier.ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     issuer.CACert,
+				License:    data,
+			},
+			Features: tt.features,
+		})
+		status := verifier.NewLicenseStatus(license, err, now)
+		for condition, want := range tt.want {
+			c := status.FindCondition(condition)
+			if c == nil || c.Status != want || c.Reason == "" {
+				t.Errorf("NewLicenseStatus() for %s condition %s = %v, want %s", tt.profile, condition, c, want)
+			}
+		}
+		if c := status.FindCondition(verifier.ConditionValid); c == nil || c.Reason != tt.reason {
+			t.Errorf("NewLicenseStatus() for %s Valid condition = %v, want reason %s", tt.profile, c, tt.reason)
+		}
+
+		var conditions []metav1.Condition
+		status.SetConditions(&conditions)
+		if len(conditions) != len(tt.want) {
+			t.Errorf("SetConditions() for %s = %v", tt.profile, conditions)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verifier implements offline verification of AppsCode licenses.
+//
+// This module does not depend on client-go and is meant to be imported by CLIs and
+// webhooks that don't want the Kubernetes client dependency tree. Besides
+// k8s.io/apimachinery, verification needs k8s.io/utils, golang-jwt, golang.org/x/crypto,
+// Masterminds/semver and sigs.k8s.io/yaml. hack/verify-deps.sh enforces this.
+// Enforcement inside a cluster (reading the cluster UID, events, periodic checks)
+// lives in the separate go.bytebuilders.dev/license-verifier/kubernetes module.
+package verifier
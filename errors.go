@@ -0,0 +1,88 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/x509"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors returned by license verification. Use errors.Is to check the cause of a failure.
+var (
+	ErrWrongCluster     = errors.New("license was issued for a different cluster")
+	ErrLicenseExpired   = errors.New("license has expired")
+	ErrProductMismatch  = errors.New("license was not issued for this product")
+	ErrBadSignature     = errors.New("license was not signed by the license issuer")
+	ErrMalformedLicense = errors.New("license is malformed")
+	ErrLimitExceeded    = errors.New("license limit exceeded")
+	ErrNamespaceScope   = errors.New("license does not cover the namespace")
+	ErrVersionMismatch  = errors.New("license does not cover this product version")
+	// ErrTrialExpired is returned in addition to ErrLicenseExpired when a trial license has expired.
+	ErrTrialExpired = errors.New("trial license has expired")
+)
+
+// licenseError annotates err with a sentinel error, so that callers can
+// branch on the cause of a verification failure using errors.Is .
+type licenseError struct {
+	sentinel error
+	err      error
+}
+
+func (e *licenseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *licenseError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+func withCause(sentinel, err error) error {
+	return &licenseError{sentinel: sentinel, err: err}
+}
+
+// certificateErrorCause returns the sentinel error matching a certificate verification error, if any.
+func certificateErrorCause(err error) error {
+	var hostErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var authErr x509.UnknownAuthorityError
+	switch {
+	case errors.As(err, &hostErr):
+		return ErrWrongCluster
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		return ErrLicenseExpired
+	case errors.As(err, &authErr), errors.Is(err, x509.ErrUnsupportedAlgorithm):
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// tokenErrorCause returns the sentinel error matching a JWT verification error, if any.
+func tokenErrorCause(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrWrongCluster
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrLicenseExpired
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrBadSignature
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrMalformedLicense
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+
+	"github.com/pkg/errors"
+)
+
+// OIDs of the license certificate extensions defined by the license issuer.
+var (
+	// OIDLicenseNamespaces is a SEQUENCE OF UTF8String listing the namespaces a license is restricted to.
+	OIDLicenseNamespaces = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57153, 1, 1}
+	// OIDLicenseProductVersion is a UTF8String holding a semver constraint on the product versions covered by a license.
+	OIDLicenseProductVersion = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57153, 1, 2}
+	// OIDLicenseFeatures is a SEQUENCE OF UTF8String listing the features of a license.
+	// It takes precedence over the subject organization and organizational unit.
+	OIDLicenseFeatures = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57153, 1, 3}
+	// OIDLicenseTrial is a BOOLEAN marking a trial license.
+	OIDLicenseTrial = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57153, 1, 4}
+	// OIDLicenseAddOn is a BOOLEAN marking an add-on license.
+	OIDLicenseAddOn = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57153, 1, 5}
+)
+
+// certificateExtension returns the value of the extension with the given id, if present.
+func certificateExtension(cert *x509.Certificate, id asn1.ObjectIdentifier) ([]byte, bool) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(id) {
+			return ext.Value, true
+		}
+	}
+	return nil, false
+}
+
+// stringsExtension decodes an extension holding a SEQUENCE OF strings.
+func stringsExtension(cert *x509.Certificate, id asn1.ObjectIdentifier) ([]string, bool, error) {
+	data, ok := certificateExtension(cert, id)
+	if !ok {
+		return nil, false, nil
+	}
+	var out []string
+	rest, err := asn1.Unmarshal(data, &out)
+	if err == nil && len(rest) > 0 {
+		err = errors.New("trailing data")
+	}
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "invalid license extension %s", id)
+	}
+	return out, true, nil
+}
+
+// stringExtension decodes an extension holding a single string.
+func stringExtension(cert *x509.Certificate, id asn1.ObjectIdentifier) (string, bool, error) {
+	data, ok := certificateExtension(cert, id)
+	if !ok {
+		return "", false, nil
+	}
+	var out string
+	rest, err := asn1.Unmarshal(data, &out)
+	if err == nil && len(rest) > 0 {
+		err = errors.New("trailing data")
+	}
+	if err != nil {
+		return "", true, errors.Wrapf(err, "invalid license extension %s", id)
+	}
+	return out, true, nil
+}
+
+// boolExtension decodes an extension holding a BOOLEAN.
+func boolExtension(cert *x509.Certificate, id asn1.ObjectIdentifier) (bool, bool, error) {
+	data, ok := certificateExtension(cert, id)
+	if !ok {
+		return false, false, nil
+	}
+	var out bool
+	rest, err := asn1.Unmarshal(data, &out)
+	if err == nil && len(rest) > 0 {
+		err = errors.New("trailing data")
+	}
+	if err != nil {
+		return false, true, errors.Wrapf(err, "invalid license extension %s", id)
+	}
+	return out, true, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"fmt"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// FeaturePolicy decides whether a license is valid when it covers only some of the requested features.
+type FeaturePolicy string
+
+const (
+	// FeaturePolicyAny accepts a license that covers at least one of the requested features. This is the default.
+	FeaturePolicyAny FeaturePolicy = "Any"
+	// FeaturePolicyAll accepts a license only if it covers all the requested features.
+	FeaturePolicyAll FeaturePolicy = "All"
+	// FeaturePolicyQuorum accepts a license that covers at least Quorum of the requested features.
+	FeaturePolicyQuorum FeaturePolicy = "Quorum"
+)
+
+// FeatureAliases maps a feature to the features it implies, eg. the old name of a renamed
+// product to its new name, or an edition to the features it includes. Implications are transitive.
+type FeatureAliases map[string][]string
+
+// Resolve returns the features together with all the features they imply.
+func (a FeatureAliases) Resolve(features []string) []string {
+	if len(a) == 0 {
+		return features
+	}
+	out := sets.NewString()
+	queue := append([]string(nil), features...)
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		if out.Has(f) {
+			continue
+		}
+		out.Insert(f)
+		queue = append(queue, a[f]...)
+	}
+	return out.List()
+}
+
+// VerifyFeatures returns whether the license was issued for each of the requested features.
+func VerifyFeatures(license v1alpha1.License, features []string) map[string]bool {
+	issued := sets.NewString(license.Features...)
+	out := make(map[string]bool, len(features))
+	for _, f := range features {
+		out[f] = issued.Has(f)
+	}
+	return out
+}
+
+// HasFeature returns true if the license was issued for the feature.
+// For x509 licenses, the features are read from the features extension of the certificate, or else
+// its subject Organization or OrganizationalUnit entries; see OIDLicenseFeatures.
+func HasFeature(license v1alpha1.License, feature string) bool {
+	for _, f := range license.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFeatures applies the policy to the per feature results of VerifyFeatures.
+// quorum is only used by FeaturePolicyQuorum and defaults to a majority of the requested features.
+// A quorum larger than the number of requested features is a configuration error.
+func CheckFeatures(results map[string]bool, policy FeaturePolicy, quorum int) error {
+	var missing []string
+	for f, ok := range results {
+		if !ok {
+			missing = append(missing, f)
+		}
+	}
+	found := len(results) - len(missing)
+
+	var required int
+	switch policy {
+	case FeaturePolicyAny, "":
+		required = 1
+	case FeaturePolicyAll:
+		required = len(results)
+	case FeaturePolicyQuorum:
+		required = quorum
+		if required <= 0 {
+			required = len(results)/2 + 1
+		}
+		if required > len(results) {
+			return fmt.Errorf("feature quorum %d exceeds the %d requested features", quorum, len(results))
+		}
+	default:
+		return fmt.Errorf("unknown feature policy %q", policy)
+	}
+	if found >= required && found > 0 {
+		return nil
+	}
+	return fmt.Errorf("license was not issued for %s", strings.Join(sets.NewString(missing...).List(), ","))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckFeatures(t *testing.T) {
+	results := map[string]bool{
+		"kubedb":    true,
+		"stash":     true,
+		"kubevault": false,
+	}
+	tests := []struct {
+		name    string
+		policy  FeaturePolicy
+		quorum  int
+		wantErr bool
+	}{
+		{name: "any", policy: FeaturePolicyAny},
+		{name: "default", policy: ""},
+		{name: "all", policy: FeaturePolicyAll, wantErr: true},
+		{name: "majority", policy: FeaturePolicyQuorum},
+		{name: "quorum", policy: FeaturePolicyQuorum, quorum: 3, wantErr: true},
+		{name: "quorum met", policy: FeaturePolicyQuorum, quorum: 2},
+		{name: "unknown", policy: "Most", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckFeatures(results, tt.policy, tt.quorum); (err != nil) != tt.wantErr {
+				t.Errorf("CheckFeatures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := CheckFeatures(map[string]bool{"kubedb": false}, FeaturePolicyAll, 0); err == nil {
+		t.Errorf("CheckFeatures() expected error for a license without any requested feature")
+	}
+	err := CheckFeatures(results, FeaturePolicyQuorum, 4)
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("CheckFeatures() error = %v, want an error for a quorum larger than the requested features", err)
+	}
+}
+
+func TestFeatureAliasesResolve(t *testing.T) {
+	aliases := FeatureAliases{
+		"kubedb-enterprise": {"kubedb"},
+		"kubedb":            {"kubedb-autoscaler", "kubedb-ops-manager"},
+		"kubedb-autoscaler": {"kubedb"}, // cycles are harmless
+	}
+	got := aliases.Resolve([]string{"kubedb-enterprise"})
+	want := []string{"kubedb", "kubedb-autoscaler", "kubedb-enterprise", "kubedb-ops-manager"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+	if got := FeatureAliases(nil).Resolve([]string{"stash"}); !reflect.DeepEqual(got, []string{"stash"}) {
+		t.Errorf("Resolve() without aliases = %v", got)
+	}
+}
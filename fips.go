@@ -0,0 +1,53 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+)
+
+// CheckAlgorithmPolicy returns an error if the certificate is not signed with or
+// does not carry a public key of a FIPS 140 approved algorithm.
+func CheckAlgorithmPolicy(cert *x509.Certificate) error {
+	switch cert.SignatureAlgorithm {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+	default:
+		return fmt.Errorf("certificate %s uses signature algorithm %s which is not FIPS approved", cert.Subject.CommonName, cert.SignatureAlgorithm)
+	}
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return fmt.Errorf("certificate %s uses a %d bit RSA key, FIPS mode requires at least 2048 bits", cert.Subject.CommonName, pub.N.BitLen())
+		}
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf("certificate %s uses elliptic curve %s which is not FIPS approved", cert.Subject.CommonName, pub.Curve.Params().Name)
+		}
+	default:
+		return fmt.Errorf("certificate %s uses public key algorithm %s which is not FIPS approved", cert.Subject.CommonName, cert.PublicKeyAlgorithm)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"fmt"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+// inGracePeriod returns true if a license that expired at notAfter is still within its grace period.
+func (opts ParserOptions) inGracePeriod(notAfter time.Time) bool {
+	if opts.GracePeriod <= 0 {
+		return false
+	}
+	now := opts.now()
+	return now.After(notAfter) && now.Before(notAfter.Add(opts.GracePeriod))
+}
+
+func (opts ParserOptions) gracePeriodLicense(license v1alpha1.License) v1alpha1.License {
+	license.Status = v1alpha1.LicenseGracePeriod
+	if license.NotAfter != nil {
+		license.Reason = fmt.Sprintf("license expired at %s and will stop working at %s",
+			license.NotAfter.UTC().Format(time.RFC3339),
+			license.NotAfter.Add(opts.GracePeriod).UTC().Format(time.RFC3339))
+	}
+	return license
+}
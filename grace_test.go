@@ -0,0 +1,69 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestGracePeriod(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := verifier.DecodeLicense(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		afterExpiry time.Duration
+		wantStatus  v1alpha1.LicenseStatus
+		wantErr     bool
+	}{
+		{"within grace period", time.Hour, v1alpha1.LicenseGracePeriod, false},
+		{"after grace period", 73 * time.Hour, v1alpha1.LicenseInvalid, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			license, err := verifier.ParseLicense(verifier.ParserOptions{
+				ClusterUID:  clusterUID,
+				CACert:      issuer.CACert,
+				License:     data,
+				Clock:       clocktesting.NewFakePassiveClock(decoded.NotAfter.Add(tt.afterExpiry)),
+				GracePeriod: 72 * time.Hour,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLicense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if license.Status != tt.wantStatus {
+				t.Errorf("ParseLicense() status = %s, want %s", license.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+#!/usr/bin/env bash
+
+# Copyright AppsCode Inc. and Contributors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -eou pipefail
+
+# The root module must stay importable by CLIs, webhooks and the wasm preview
+# without the Kubernetes client dependency tree or the CLI framework.
+FORBIDDEN='^(k8s\.io/client-go|k8s\.io/api/|sigs\.k8s\.io/controller-runtime|kmodules\.xyz|github\.com/spf13/cobra)'
+
+echo "Verifying dependencies of the root module:"
+deps=$(GOFLAGS=-mod=mod go list -deps ./...)
+if bad=$(grep -E "${FORBIDDEN}" <<<"${deps}"); then
+    echo "root module must not import:"
+    echo "${bad}"
+    exit 1
+fi
+echo "OK"
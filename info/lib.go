@@ -40,19 +40,28 @@ import (
 var (
 	EnforceLicense string
 	LicenseCA      string
+	// FIPSMode restricts license verification to FIPS approved algorithms.
+	// This is always enabled for binaries built with GOEXPERIMENT=boringcrypto.
+	FIPSMode string
 
 	ProductOwnerName string
 	ProductOwnerUID  string
 
 	ProductName string // This has been renamed to Features
 	ProductUID  string
+	// ProductVersion is checked against the version constraint of licenses sold for fixed product versions.
+	ProductVersion string
 
 	QADomain             = "appscode.ninja"
 	ProdDomain           = "appscode.com"
 	DeprecatedProdDomain = "byte.builders"
 
-	registrationAPIPath  = "api/v1/register"
-	LicenseIssuerAPIPath = "api/v1/license/issue"
+	RegistrationAPIPath          = "api/v1/register"
+	LicenseIssuerAPIPath         = "api/v1/license/issue"
+	LicenseQuotaAPIPath          = "api/v1/license/quota"
+	LicenseRevocationListAPIPath = "api/v1/license/crl"
+	LicenseVerifyAPIPath         = "api/v1/license/verify"
+	LicenseUsageAPIPath          = "api/v1/license/usage"
 )
 
 func Features() []string {
@@ -71,6 +80,14 @@ func SkipLicenseVerification() bool {
 	return !v
 }
 
+func FIPSEnabled() bool {
+	if fipsBuild {
+		return true
+	}
+	v, _ := strconv.ParseBool(FIPSMode)
+	return v
+}
+
 func MustRegistrationAPIEndpoint() string {
 	r, err := RegistrationAPIEndpoint()
 	if err != nil {
@@ -84,7 +101,7 @@ func RegistrationAPIEndpoint(override ...string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	u.Path = path.Join(u.Path, registrationAPIPath)
+	u.Path = path.Join(u.Path, RegistrationAPIPath)
 	return u.String(), nil
 }
 
@@ -105,6 +122,42 @@ func LicenseIssuerAPIEndpoint(override ...string) (string, error) {
 	return u.String(), nil
 }
 
+func LicenseQuotaAPIEndpoint(override ...string) (string, error) {
+	u, err := APIServerAddress(override...)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, LicenseQuotaAPIPath)
+	return u.String(), nil
+}
+
+func LicenseRevocationListAPIEndpoint(override ...string) (string, error) {
+	u, err := APIServerAddress(override...)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, LicenseRevocationListAPIPath)
+	return u.String(), nil
+}
+
+func LicenseVerifyAPIEndpoint(override ...string) (string, error) {
+	u, err := APIServerAddress(override...)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, LicenseVerifyAPIPath)
+	return u.String(), nil
+}
+
+func LicenseUsageAPIEndpoint(override ...string) (string, error) {
+	u, err := APIServerAddress(override...)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, LicenseUsageAPIPath)
+	return u.String(), nil
+}
+
 func MustAPIServerAddress() *url.URL {
 	u, err := APIServerAddress()
 	if err != nil {
@@ -182,10 +235,35 @@ func LoadLicenseCA() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// ParseCertificates parses all PEM encoded certificates in data, eg. a license followed by
+// its intermediate certificates.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("failed to parse certificate PEM")
+	}
+	return certs, nil
+}
+
 func ParseCertificate(data []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {
-		// This probably is a JWT token, should be check for that when ready
+		// JWT encoded licenses are handled by verifier.ParseLicense
 		return nil, errors.New("failed to parse certificate PEM")
 	}
 	return x509.ParseCertificate(block.Bytes)
@@ -0,0 +1,69 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package info
+
+import (
+	"runtime/debug"
+)
+
+const (
+	libraryModulePath = "go.bytebuilders.dev/license-verifier"
+
+	// LicenseFormatX509 is a PEM encoded x509 certificate signed by the license CA
+	LicenseFormatX509 = "x509"
+	// LicenseFormatJWT is a RS256 or ES256 signed JWT signed by the license CA private key
+	LicenseFormatJWT = "jwt"
+)
+
+// VersionInfo reports the license verifier version deployed with a product,
+// so that the license issuer and support tooling know which license formats it understands.
+type VersionInfo struct {
+	LibraryVersion string   `json:"libraryVersion"`
+	LicenseFormats []string `json:"licenseFormats"`
+}
+
+func Version() VersionInfo {
+	return VersionInfo{
+		LibraryVersion: LibraryVersion(),
+		LicenseFormats: SupportedLicenseFormats(),
+	}
+}
+
+// LibraryVersion returns the module version of the license verifier compiled into the binary.
+func LibraryVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	if bi.Main.Path == libraryModulePath {
+		return bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == libraryModulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
+
+// SupportedLicenseFormats returns the license formats this verifier can verify.
+func SupportedLicenseFormats() []string {
+	return []string{LicenseFormatX509, LicenseFormatJWT}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats of PrintInspection.
+const (
+	OutputJSON       = "json"
+	OutputYAML       = "yaml"
+	OutputTable      = "table"
+	OutputGoTemplate = "go-template"
+)
+
+// LicenseInspection describes a license for humans and scripts. It is decoded without verifying the license.
+type LicenseInspection struct {
+	SerialNumber string `json:"serialNumber"`
+	Format       string `json:"format"`
+	// Issuer is the distinguished name of the issuer of x509 licenses and the iss claim of JWT licenses.
+	Issuer string `json:"issuer"`
+	// SANs are the subject alternative names of x509 licenses and the audience of JWT licenses, ie. the licensed clusters.
+	SANs        []string     `json:"sans,omitempty"`
+	ProductLine string       `json:"productLine,omitempty"`
+	PlanName    string       `json:"planName,omitempty"`
+	Features    []string     `json:"features,omitempty"`
+	Trial       bool         `json:"trial,omitempty"`
+	NotBefore   *metav1.Time `json:"notBefore,omitempty"`
+	NotAfter    *metav1.Time `json:"notAfter,omitempty"`
+	// ExpiresIn is the time left until the license expires as of the inspection. It is negative for expired licenses.
+	ExpiresIn string `json:"expiresIn,omitempty"`
+	// DaysRemaining is the number of started days left until the license expires.
+	DaysRemaining int `json:"daysRemaining"`
+}
+
+// InspectLicense decodes a PEM or JWT encoded license without verifying it.
+func InspectLicense(data []byte, now time.Time) (LicenseInspection, error) {
+	license, err := DecodeLicense(data)
+	if err != nil {
+		return LicenseInspection{}, err
+	}
+	out := LicenseInspection{
+		SerialNumber: license.ID,
+		Format:       license.Format,
+		Issuer:       license.Issuer,
+		SANs:         license.Clusters,
+		ProductLine:  license.ProductLine,
+		PlanName:     license.PlanName,
+		Features:     license.Features,
+		Trial:        license.IsTrial(),
+		NotBefore:    license.NotBefore,
+		NotAfter:     license.NotAfter,
+	}
+	if !IsJWT(data) {
+		cert, err := info.ParseCertificate(data)
+		if err != nil {
+			return LicenseInspection{}, withCause(ErrMalformedLicense, err)
+		}
+		out.Issuer = cert.Issuer.String()
+		out.SANs = append([]string(nil), cert.DNSNames...)
+		out.SANs = append(out.SANs, cert.EmailAddresses...)
+		for _, u := range cert.URIs {
+			out.SANs = append(out.SANs, u.String())
+		}
+	}
+	if license.NotAfter != nil {
+		left := license.NotAfter.Sub(now).Round(time.Second)
+		out.ExpiresIn = left.String()
+		out.DaysRemaining = int(math.Max(math.Ceil(left.Hours()/24), 0))
+	}
+	return out, nil
+}
+
+// PrintInspection writes the inspection in the given output format: json, yaml, table or
+// go-template=<template>. Like kubectl, templates use the json field names, eg. {{.notAfter}}.
+func PrintInspection(w io.Writer, in LicenseInspection, output string) error {
+	format, tmpl, _ := strings.Cut(output, "=")
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(in, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(in)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case OutputTable, "":
+		return printInspectionTable(w, in)
+	case OutputGoTemplate:
+		if tmpl == "" {
+			return fmt.Errorf("template is required, use %s=<template>", OutputGoTemplate)
+		}
+		t, err := template.New("license").Parse(tmpl)
+		if err != nil {
+			return err
+		}
+		// execute the template on the json representation, so that it uses the json field names
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		var obj map[string]any
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&obj); err != nil {
+			return err
+		}
+		return t.Execute(w, obj)
+	default:
+		return fmt.Errorf("unknown output format %q, use one of %s, %s, %s or %s=<template>", output, OutputJSON, OutputYAML, OutputTable, OutputGoTemplate)
+	}
+}
+
+func printInspectionTable(w io.Writer, in LicenseInspection) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Serial Number:\t%s\n", in.SerialNumber)
+	fmt.Fprintf(tw, "Format:\t%s\n", in.Format)
+	fmt.Fprintf(tw, "Issuer:\t%s\n", in.Issuer)
+	fmt.Fprintf(tw, "SANs:\t%s\n", strings.Join(in.SANs, ", "))
+	fmt.Fprintf(tw, "Product Line:\t%s\n", in.ProductLine)
+	fmt.Fprintf(tw, "Plan:\t%s\n", in.PlanName)
+	fmt.Fprintf(tw, "Features:\t%s\n", strings.Join(in.Features, ", "))
+	if in.Trial {
+		fmt.Fprintf(tw, "Trial:\t%t\n", in.Trial)
+	}
+	if in.NotBefore != nil {
+		fmt.Fprintf(tw, "Not Before:\t%s\n", in.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if in.NotAfter != nil {
+		fmt.Fprintf(tw, "Not After:\t%s\n", in.NotAfter.UTC().Format(time.RFC3339))
+		fmt.Fprintf(tw, "Expires In:\t%s (%d days)\n", in.ExpiresIn, in.DaysRemaining)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,69 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestInspectLicense(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := verifier.InspectLicense(data, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.SerialNumber == "" || in.Issuer == "" || in.DaysRemaining <= 0 || !sets.NewString(in.SANs...).Has(clusterUID) {
+		t.Errorf("InspectLicense() = %+v", in)
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{verifier.OutputJSON, `"serialNumber": "` + in.SerialNumber + `"`},
+		{verifier.OutputYAML, "serialNumber: \"" + in.SerialNumber + "\""},
+		{verifier.OutputTable, "Serial Number:  " + in.SerialNumber},
+		{verifier.OutputGoTemplate + "={{.serialNumber}}", in.SerialNumber},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := verifier.PrintInspection(&buf, in, tt.output); err != nil {
+			t.Errorf("PrintInspection(%s) error = %v", tt.output, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("PrintInspection(%s) = %s, want %s", tt.output, buf.String(), tt.want)
+		}
+	}
+	if err := verifier.PrintInspection(io.Discard, in, "xml"); err == nil {
+		t.Error("PrintInspection(xml) error = nil, want unknown output format")
+	}
+}
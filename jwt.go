@@ -0,0 +1,92 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/golang-jwt/jwt/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LicenseClaims are the claims of a JWT encoded license.
+// The license is bound to the cluster UIDs listed in the audience claim
+// and is signed with the private key of the license CA.
+type LicenseClaims struct {
+	jwt.RegisteredClaims
+	ProductLine  string            `json:"productLine,omitempty"`
+	TierName     string            `json:"tierName,omitempty"`
+	PlanName     string            `json:"planName,omitempty"`
+	Features     []string          `json:"features"`
+	FeatureFlags map[string]string `json:"featureFlags,omitempty"`
+	Limits       map[string]int64  `json:"limits,omitempty"`
+	User         *v1alpha1.User    `json:"user,omitempty"`
+}
+
+var jwtSigningMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodES384.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// IsJWT returns true if data looks like a compact serialized JWT instead of a PEM encoded certificate.
+func IsJWT(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && !bytes.HasPrefix(data, []byte("-----")) && bytes.Count(data, []byte(".")) == 2
+}
+
+// licenseFromClaims extracts the license details from the claims of a JWT encoded license.
+func licenseFromClaims(data []byte, claims LicenseClaims) v1alpha1.License {
+	license := v1alpha1.License{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "License",
+		},
+		Data:         data,
+		Format:       info.LicenseFormatJWT,
+		Issuer:       claims.Issuer,
+		ProductLine:  claims.ProductLine,
+		TierName:     claims.TierName,
+		PlanName:     claims.PlanName,
+		Features:     claims.Features,
+		FeatureFlags: claims.FeatureFlags,
+		Limits:       claims.Limits,
+		Clusters:     claims.Audience,
+		User:         claims.User,
+		ID:           claims.ID,
+	}
+	if license.Issuer == "" {
+		license.Issuer = info.ProdDomain
+	}
+	if license.FeatureFlags == nil {
+		license.FeatureFlags = map[string]string{}
+	}
+	if claims.NotBefore != nil {
+		license.NotBefore = &metav1.Time{Time: claims.NotBefore.Time}
+	} else if claims.IssuedAt != nil {
+		license.NotBefore = &metav1.Time{Time: claims.IssuedAt.Time}
+	}
+	if claims.ExpiresAt != nil {
+		license.NotAfter = &metav1.Time{Time: claims.ExpiresAt.Time}
+	}
+
+	return license
+}
@@ -0,0 +1,100 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+func TestParseJWTLicense(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca", Organization: []string{"appscode.com"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(expiry time.Time) []byte {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodES256, LicenseClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "license-1",
+				Audience:  jwt.ClaimStrings{"cluster-1"},
+				NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+				ExpiresAt: jwt.NewNumericDate(expiry),
+			},
+			PlanName: "kubedb-enterprise",
+			Features: []string{"kubedb"},
+		}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []byte(token)
+	}
+
+	valid := sign(time.Now().Add(time.Hour))
+	if !IsJWT(valid) {
+		t.Fatalf("IsJWT() = false for a JWT license")
+	}
+
+	license, err := CheckLicense(VerifyOptions{
+		ParserOptions: ParserOptions{ClusterUID: "cluster-1", CACert: caCert, License: valid},
+		Features:      "kubedb",
+	})
+	if err != nil {
+		t.Fatalf("CheckLicense() error = %v", err)
+	}
+	if license.Status != v1alpha1.LicenseActive || license.ID != "license-1" || license.PlanName != "kubedb-enterprise" {
+		t.Errorf("CheckLicense() returned unexpected license %+v", license)
+	}
+
+	_, err = ParseLicense(ParserOptions{ClusterUID: "cluster-2", CACert: caCert, License: valid})
+	if !errors.Is(err, ErrWrongCluster) {
+		t.Errorf("ParseLicense() error = %v, want ErrWrongCluster", err)
+	}
+
+	license, err = ParseLicense(ParserOptions{ClusterUID: "cluster-1", CACert: caCert, License: sign(time.Now().Add(-time.Minute))})
+	if err == nil || license.Status != v1alpha1.LicenseInvalid {
+		t.Errorf("ParseLicense() accepted an expired license")
+	}
+}
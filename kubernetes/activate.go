@@ -0,0 +1,106 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/client-go/rest"
+)
+
+// Activate registers the current cluster with the license issuer, acquires a license
+// for the given features, verifies it and writes it to licenseFile (if set).
+// If no features are given, the features of the current product are used.
+func Activate(config *rest.Config, licenseFile, token string, features []string) (v1alpha1.License, error) {
+	return ActivateWithContext(context.TODO(), config, licenseFile, token, features)
+}
+
+// ActivateWithContext is like Activate but uses ctx for all api calls and requests to the license issuer.
+func ActivateWithContext(ctx context.Context, config *rest.Config, licenseFile, token string, features []string) (v1alpha1.License, error) {
+	le, err := NewLicenseEnforcer(config, licenseFile)
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	return le.Activate(ctx, token, features)
+}
+
+// Activate registers the cluster with the license issuer configured using SetIssuer, or the default issuer,
+// acquires a license for the given features, verifies it and writes it to the license file (if set).
+// token overrides the token of the configured issuer, if set.
+func (le *LicenseEnforcer) Activate(ctx context.Context, token string, features []string) (v1alpha1.License, error) {
+	if len(features) == 0 {
+		features = info.Features()
+	}
+
+	err := le.createClients()
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	uid, err := le.cachedClusterUID(ctx)
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+
+	issuer := IssuerConfig{}
+	if le.issuer != nil {
+		issuer = *le.issuer
+	}
+	if token != "" {
+		issuer.Token = token
+	}
+	c, err := issuer.newClient(uid)
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	if err = c.RegisterClusterWithContext(ctx); err != nil {
+		return verifier.BadLicense(errors.Wrap(err, "failed to register cluster"))
+	}
+	data, contract, err := c.AcquireLicense(ctx, features)
+	if err != nil {
+		return verifier.BadLicense(errors.Wrap(err, "failed to acquire license"))
+	}
+
+	le.verifyMu.Lock()
+	defer le.verifyMu.Unlock()
+
+	le.opts.Features = strings.Join(features, ",")
+	le.opts.License = data
+	le.setContract(contract)
+
+	// only a verified license is persisted, so that a bad license never replaces a working one
+	license, err := le.verify(ctx)
+	if err != nil {
+		return license, err
+	}
+	if le.licenseFile != "" {
+		if err = os.MkdirAll(filepath.Dir(le.licenseFile), 0o755); err != nil {
+			return verifier.BadLicense(err)
+		}
+		if err = os.WriteFile(le.licenseFile, data, 0o600); err != nil {
+			return verifier.BadLicense(errors.Wrap(err, "failed to write license"))
+		}
+	}
+	return license, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestActivate(t *testing.T) {
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	issuer := licensetest.NewTestIssuer(t)
+	srv := licensetest.NewIssuerServer(issuer)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		// trusted signs the license CA trusted by the enforcer
+		trusted   *licensetest.Issuer
+		wantErr   bool
+		wantWrite bool
+	}{
+		{name: "verified", trusted: issuer, wantWrite: true},
+		{name: "verification fails", trusted: licensetest.NewTestIssuer(t), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			licenseFile := filepath.Join(t.TempDir(), "license", "key.txt")
+			kc := fake.NewSimpleClientset(&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: clusterUID}})
+			le := &LicenseEnforcer{kc: kc, clock: clocktesting.NewFakeClock(time.Now()), licenseFile: licenseFile}
+			le.opts.CACert = tt.trusted.CACert
+			le.SetIssuer(&IssuerConfig{URL: srv.URL, Token: "configured-token"})
+
+			license, err := le.Activate(context.TODO(), "", []string{"kubedb-enterprise"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Activate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !srv.Registered(clusterUID) || srv.Issued() == 0 {
+				t.Errorf("Activate() did not register the cluster and acquire a license from the configured issuer")
+			}
+			if !tt.wantErr && license.Status != v1alpha1.LicenseActive {
+				t.Errorf("Activate() status = %s, want %s", license.Status, v1alpha1.LicenseActive)
+			}
+			_, err = os.Stat(licenseFile)
+			if written := err == nil; written != tt.wantWrite {
+				t.Errorf("Activate() wrote the license file = %v, want %v", written, tt.wantWrite)
+			}
+		})
+	}
+}
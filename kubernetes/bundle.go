@@ -0,0 +1,130 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/klog/v2"
+)
+
+// readLicenseFile reads the license file. The license file may also be a directory or a
+// bundle of licenses, in which case the first license valid for this cluster and product is used.
+// If none is valid, the first license is returned, so that verification reports why.
+// With license stacking, all licenses are returned, so that add-on licenses are stacked on the base license.
+func (le *LicenseEnforcer) readLicenseFile() ([]byte, error) {
+	fi, err := os.Stat(le.licenseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{le.licenseFile}
+	if fi.IsDir() {
+		entries, err := os.ReadDir(le.licenseFile)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, e := range entries {
+			// skip hidden files, eg. the ..data symlink of Secret volumes
+			if strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			p := filepath.Join(le.licenseFile, e.Name())
+			if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+				files = append(files, p)
+			}
+		}
+		if len(files) == 0 {
+			return nil, errors.Wrapf(os.ErrNotExist, "no license found in directory %s", le.licenseFile)
+		}
+	}
+
+	type candidate struct {
+		file string
+		data []byte
+	}
+	var candidates []candidate
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range verifier.SplitLicenses(data) {
+			candidates = append(candidates, candidate{file: f, data: l})
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0].data, nil
+	}
+	if le.licenseStacking {
+		var out []byte
+		seen := map[string]bool{}
+		for _, c := range candidates {
+			// the same license may be mounted more than once, eg. from the Secret and a bundle file
+			data := bytes.TrimSpace(c.data)
+			if seen[string(data)] {
+				continue
+			}
+			seen[string(data)] = true
+			out = append(out, data...)
+			out = append(out, '\n')
+		}
+		return out, nil
+	}
+
+	if err := le.readClusterCAFingerprint(); err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		opts := le.opts
+		opts.License = c.data
+		if license, err := verifier.CheckLicense(opts); err == nil {
+			klog.V(4).Infof("Using license %s from %s", license.ID, c.file)
+			return c.data, nil
+		}
+	}
+	return candidates[0].data, nil
+}
+
+// SetLicenseStacking enables stacking add-on licenses on the base license, when the license file
+// is a directory or a bundle of licenses. See verifier.StackLicenses for the rules.
+func (le *LicenseEnforcer) SetLicenseStacking(enabled bool) {
+	le.licenseStacking = enabled
+}
+
+// checkLicense verifies the license, stacking add-on licenses if enabled.
+func (le *LicenseEnforcer) checkLicense(opts verifier.VerifyOptions) (v1alpha1.License, error) {
+	if le.licenseStacking {
+		return verifier.StackLicenses(opts)
+	}
+	return le.runChecks(opts)
+}
+
+// runChecks verifies the license with the configured checks and records the result of each check.
+func (le *LicenseEnforcer) runChecks(opts verifier.VerifyOptions) (v1alpha1.License, error) {
+	license, checks, err := verifier.RunChecks(opts)
+	le.recordChecks(checks)
+	return license, err
+}
@@ -0,0 +1,120 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// CABundleKey is the default data key holding the PEM encoded license CA certificates
+const CABundleKey = "ca.crt"
+
+// CABundleSource points to a ConfigMap or Secret carrying PEM encoded license CA certificates,
+// which are trusted in addition to the license CA compiled into the product. This allows
+// air-gapped clusters to trust a new license CA without upgrading every product.
+// Only CAs cross-signed by a license CA compiled into the product are accepted.
+type CABundleSource struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key,omitempty"`
+}
+
+// SetCABundleSource configures the enforcer to trust the license CAs in the given ConfigMap or Secret.
+func (le *LicenseEnforcer) SetCABundleSource(src *CABundleSource) {
+	le.caBundle = src
+}
+
+// loadCABundle returns the additional license CAs trusted for a single verification: the CAs
+// compiled into the product after the first one, followed by the CAs in the CA bundle source.
+// If the CA bundle can't be read, only the license CAs compiled into the product are trusted.
+func (le *LicenseEnforcer) loadCABundle(ctx context.Context) []*x509.Certificate {
+	if le.caBundle == nil {
+		return le.bundledCAs
+	}
+	certs, err := le.readCABundle(ctx)
+	if err != nil {
+		klog.Warningf("Failed to read license CA bundle. Reason: %v", err)
+		return le.bundledCAs
+	}
+	return append(append(make([]*x509.Certificate, 0, len(le.bundledCAs)+len(certs)), le.bundledCAs...), certs...)
+}
+
+func (le *LicenseEnforcer) readCABundle(ctx context.Context) ([]*x509.Certificate, error) {
+	src := le.caBundle
+	key := src.Key
+	if key == "" {
+		key = CABundleKey
+	}
+
+	var data []byte
+	switch src.Kind {
+	case RevocationListKindSecret:
+		s, err := le.kc.CoreV1().Secrets(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read license CA bundle")
+		}
+		data = s.Data[key]
+	case RevocationListKindConfigMap, "":
+		cm, err := le.kc.CoreV1().ConfigMaps(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read license CA bundle")
+		}
+		if v, ok := cm.BinaryData[key]; ok {
+			data = v
+		} else {
+			data = []byte(cm.Data[key])
+		}
+	default:
+		return nil, fmt.Errorf("unknown license CA bundle source kind %q", src.Kind)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("license CA bundle %s %s/%s is missing key %s", src.Kind, src.Namespace, src.Name, key)
+	}
+	certs, err := info.ParseCertificates(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse license CA bundle")
+	}
+	for _, cert := range certs {
+		if !cert.IsCA {
+			return nil, fmt.Errorf("license CA bundle %s %s/%s contains certificate %s which is not a CA", src.Kind, src.Namespace, src.Name, cert.Subject)
+		}
+		if !le.crossSigned(cert) {
+			return nil, fmt.Errorf("license CA bundle %s %s/%s contains certificate %s which is not signed by a license CA of the product", src.Kind, src.Namespace, src.Name, cert.Subject)
+		}
+	}
+	return certs, nil
+}
+
+// crossSigned returns true if the CA certificate is signed by one of the license CAs compiled into the
+// product. The CA bundle is writable by cluster admins, so a self signed CA must never be trusted.
+func (le *LicenseEnforcer) crossSigned(cert *x509.Certificate) bool {
+	for _, ca := range append([]*x509.Certificate{le.opts.CACert}, le.bundledCAs...) {
+		if ca != nil && !ca.Equal(cert) && cert.CheckSignatureFrom(ca) == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCABundle(t *testing.T) {
+	root := licensetest.NewTestIssuer(t)
+	crossSigned, err := root.NewIntermediate("license-issuer-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selfSigned := licensetest.NewTestIssuer(t)
+
+	tests := []struct {
+		name   string
+		bundle []byte
+		want   int
+	}{
+		{"cross-signed", crossSigned.CACertPEM(), 1},
+		{"self signed", selfSigned.CACertPEM(), 0},
+		{"mixed", append(crossSigned.CACertPEM(), selfSigned.CACertPEM()...), 0},
+		{"compiled-in", root.CACertPEM(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &core.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "license-ca"},
+				Data:       map[string]string{CABundleKey: string(tt.bundle)},
+			}
+			le := &LicenseEnforcer{kc: fake.NewSimpleClientset(cm)}
+			le.opts.CACert = root.CACert
+			le.SetCABundleSource(&CABundleSource{Namespace: cm.Namespace, Name: cm.Name})
+
+			cas := le.loadCABundle(context.TODO())
+			if len(cas) != tt.want {
+				t.Errorf("loadCABundle() returned %d CAs, want %d", len(cas), tt.want)
+			}
+			if le.opts.CACerts != nil {
+				t.Error("loadCABundle() modified the options of the enforcer")
+			}
+		})
+	}
+}
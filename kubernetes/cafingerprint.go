@@ -0,0 +1,59 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/client-go/rest"
+)
+
+// ClusterCAFingerprint returns the sha256 fingerprint of the API server CA certificate in config.
+// It returns an empty string if config does not carry a CA certificate.
+func ClusterCAFingerprint(config *rest.Config) (string, error) {
+	if config == nil {
+		return "", nil
+	}
+	data := config.CAData
+	if len(data) == 0 && config.CAFile != "" {
+		var err error
+		data, err = os.ReadFile(config.CAFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read cluster CA certificate")
+		}
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	cert, err := info.ParseCertificate(data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse cluster CA certificate")
+	}
+	return verifier.CertificateFingerprint(cert), nil
+}
+
+func (le *LicenseEnforcer) readClusterCAFingerprint() (err error) {
+	if le.opts.ClusterCAFingerprint != "" {
+		return
+	}
+	le.opts.ClusterCAFingerprint, err = ClusterCAFingerprint(le.config)
+	return err
+}
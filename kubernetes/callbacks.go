@@ -0,0 +1,127 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+// licenseCallbacks tracks the license state observed by the periodic verifier and
+// the callbacks invoked when it changes.
+type licenseCallbacks struct {
+	mu             sync.Mutex
+	onValid        []func(license v1alpha1.License)
+	onExpiringSoon []func(license v1alpha1.License)
+	onInvalid      []func(license v1alpha1.License, err error)
+	onRenewed      []func(old, license v1alpha1.License)
+
+	observed bool
+	valid    bool
+	expiring bool
+	license  v1alpha1.License
+}
+
+// OnValid registers fn to be called when the license becomes valid, including the first successful verification.
+// Callbacks are invoked from the verification loop and must not block.
+func (le *LicenseEnforcer) OnValid(fn func(license v1alpha1.License)) {
+	le.callbacks.mu.Lock()
+	defer le.callbacks.mu.Unlock()
+	le.callbacks.onValid = append(le.callbacks.onValid, fn)
+}
+
+// OnExpiringSoon registers fn to be called once per license when its remaining lifetime falls below
+// the largest expiry warning threshold.
+func (le *LicenseEnforcer) OnExpiringSoon(fn func(license v1alpha1.License)) {
+	le.callbacks.mu.Lock()
+	defer le.callbacks.mu.Unlock()
+	le.callbacks.onExpiringSoon = append(le.callbacks.onExpiringSoon, fn)
+}
+
+// OnInvalid registers fn to be called when the license becomes invalid, including the first failed verification.
+// If the enforcer is configured to crash on failure, fn is called before the process exits.
+func (le *LicenseEnforcer) OnInvalid(fn func(license v1alpha1.License, err error)) {
+	le.callbacks.mu.Lock()
+	defer le.callbacks.mu.Unlock()
+	le.callbacks.onInvalid = append(le.callbacks.onInvalid, fn)
+}
+
+// OnRenewed registers fn to be called when a valid license is replaced by another valid license.
+func (le *LicenseEnforcer) OnRenewed(fn func(old, license v1alpha1.License)) {
+	le.callbacks.mu.Lock()
+	defer le.callbacks.mu.Unlock()
+	le.callbacks.onRenewed = append(le.callbacks.onRenewed, fn)
+}
+
+// notifyLicenseChange invokes the registered callbacks for the transition from the
+// previously observed license state to the result of the current verification.
+func (le *LicenseEnforcer) notifyLicenseChange(license v1alpha1.License, err error) {
+	var calls []func()
+
+	c := &le.callbacks
+	c.mu.Lock()
+	if err != nil {
+		if !c.observed || c.valid {
+			for _, fn := range c.onInvalid {
+				fn := fn
+				calls = append(calls, func() { fn(license, err) })
+			}
+		}
+		c.valid, c.expiring = false, false
+	} else {
+		if c.observed && c.valid && c.license.ID != license.ID {
+			old := c.license
+			for _, fn := range c.onRenewed {
+				fn := fn
+				calls = append(calls, func() { fn(old, license) })
+			}
+			c.expiring = false
+		}
+		if !c.observed || !c.valid {
+			for _, fn := range c.onValid {
+				fn := fn
+				calls = append(calls, func() { fn(license) })
+			}
+		}
+		if !c.expiring && le.expiringSoon(license) {
+			c.expiring = true
+			for _, fn := range c.onExpiringSoon {
+				fn := fn
+				calls = append(calls, func() { fn(license) })
+			}
+		}
+		c.valid = true
+	}
+	c.observed = true
+	c.license = license
+	c.mu.Unlock()
+
+	for _, call := range calls {
+		call()
+	}
+}
+
+// expiringSoon returns true if the license expires within the largest expiry warning threshold.
+func (le *LicenseEnforcer) expiringSoon(license v1alpha1.License) bool {
+	if license.NotAfter == nil || len(le.expiryWarningDays) == 0 {
+		return false
+	}
+	days := le.expiryWarningDays[len(le.expiryWarningDays)-1]
+	return license.NotAfter.Sub(le.clock.Now()) < time.Duration(days)*24*time.Hour
+}
@@ -0,0 +1,72 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func testLicense(id string, notAfter time.Time) v1alpha1.License {
+	return v1alpha1.License{
+		ID:       id,
+		Status:   v1alpha1.LicenseActive,
+		NotAfter: &metav1.Time{Time: notAfter},
+	}
+}
+
+func TestLicenseCallbacks(t *testing.T) {
+	now := time.Now()
+	le := &LicenseEnforcer{clock: clocktesting.NewFakeClock(now)}
+	le.SetExpiryWarnings([]int{30})
+
+	var got []string
+	le.OnValid(func(license v1alpha1.License) { got = append(got, "valid:"+license.ID) })
+	le.OnInvalid(func(license v1alpha1.License, err error) { got = append(got, "invalid") })
+	le.OnRenewed(func(old, license v1alpha1.License) { got = append(got, "renewed:"+old.ID+"->"+license.ID) })
+	le.OnExpiringSoon(func(license v1alpha1.License) { got = append(got, "expiring:"+license.ID) })
+
+	errInvalid := errors.New("invalid license")
+	steps := []struct {
+		license v1alpha1.License
+		err     error
+		want    []string
+	}{
+		{v1alpha1.License{}, errInvalid, []string{"invalid"}},
+		{v1alpha1.License{}, errInvalid, nil},
+		{testLicense("a", now.AddDate(1, 0, 0)), nil, []string{"valid:a"}},
+		{testLicense("a", now.AddDate(1, 0, 0)), nil, nil},
+		{testLicense("b", now.AddDate(0, 0, 10)), nil, []string{"renewed:a->b", "expiring:b"}},
+		{testLicense("b", now.AddDate(0, 0, 10)), nil, nil},
+		{v1alpha1.License{}, errInvalid, []string{"invalid"}},
+		{testLicense("b", now.AddDate(0, 0, 10)), nil, []string{"valid:b", "expiring:b"}},
+	}
+	for i, step := range steps {
+		got = nil
+		le.notifyLicenseChange(step.license, step.err)
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: callbacks = %v, want %v", i, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// EventReasonLicenseLimitExceeded is recorded when the cluster exceeds the capacity permitted by the license
+// and the capacity enforcement mode is CapacityWarn.
+const EventReasonLicenseLimitExceeded = "License Limit Exceeded"
+
+// CapacityEnforcementMode decides what happens when the cluster exceeds the capacity permitted by the license.
+type CapacityEnforcementMode string
+
+const (
+	// CapacityEnforce fails license verification. This is the default.
+	CapacityEnforce CapacityEnforcementMode = "Enforce"
+	// CapacityWarn only records a warning event. The other limits of the license, eg. counted
+	// using SetUsageCounter, are still enforced.
+	CapacityWarn CapacityEnforcementMode = "Warn"
+)
+
+// CapacityEnforcement configures how the node and cpu limits of a license are enforced.
+type CapacityEnforcement struct {
+	Mode CapacityEnforcementMode `json:"mode,omitempty"`
+	// SchedulableOnly only counts nodes that are ready and not cordoned.
+	SchedulableOnly bool `json:"schedulableOnly,omitempty"`
+	// RecheckInterval counts the nodes more often than the license is checked.
+	RecheckInterval metav1.Duration `json:"recheckInterval,omitempty"`
+}
+
+func (c CapacityEnforcement) Validate() error {
+	if c.Mode != "" && c.Mode != CapacityEnforce && c.Mode != CapacityWarn {
+		return fmt.Errorf("capacityEnforcement.mode must be %s or %s, found %q", CapacityEnforce, CapacityWarn, c.Mode)
+	}
+	if c.RecheckInterval.Duration != 0 && c.RecheckInterval.Duration < time.Minute {
+		return fmt.Errorf("capacityEnforcement.recheckInterval must be at least 1m, found %s", c.RecheckInterval.Duration)
+	}
+	return nil
+}
+
+// SetCapacityEnforcement configures how the node and cpu limits of a license are enforced.
+func (le *LicenseEnforcer) SetCapacityEnforcement(c *CapacityEnforcement) {
+	le.capacity = c
+}
+
+// countedNode returns true if the node counts against the capacity permitted by the license.
+func (le *LicenseEnforcer) countedNode(node core.Node) bool {
+	if le.capacity == nil || !le.capacity.SchedulableOnly {
+		return true
+	}
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == core.NodeReady {
+			return c.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// capacityResource returns true if the limit of the resource is enforced according to the
+// CapacityEnforcement mode.
+func capacityResource(resource string) bool {
+	return resource == v1alpha1.LimitNodes || resource == v1alpha1.LimitCPU
+}
+
+// capacityExceeded decides whether exceeding the license capacity fails verification.
+func (le *LicenseEnforcer) capacityExceeded(err error) error {
+	if le.capacity == nil || le.capacity.Mode != CapacityWarn {
+		return err
+	}
+	klog.Warningln(err)
+	e2 := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-limit", core.EventTypeWarning, EventReasonLicenseLimitExceeded, err.Error())
+	})
+	if e2 != nil {
+		klog.Warningln(e2)
+	}
+	return nil
+}
+
+// recheckCapacity triggers a verification every RecheckInterval, so that added nodes are noticed
+// before the next license check.
+func (le *LicenseEnforcer) recheckCapacity(ctx context.Context, trigger chan<- struct{}) {
+	if le.capacity == nil || le.capacity.RecheckInterval.Duration <= 0 {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-le.clock.After(le.capacity.RecheckInterval.Duration):
+			notify(trigger)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const kubeRootCAConfigMap = "kube-root-ca.crt"
+
+// ClusterProfileOptions are the consent flags for the signals collected in a ClusterProfile.
+// Only the cluster UID is collected when all flags are false.
+type ClusterProfileOptions struct {
+	NodeUIDs           bool `json:"nodeUIDs,omitempty"`
+	CACertificate      bool `json:"caCertificate,omitempty"`
+	CreationTimestamps bool `json:"creationTimestamps,omitempty"`
+}
+
+// CollectClusterProfile collects the signals allowed by opts that help the license issuer detect cloned clusters.
+func CollectClusterProfile(ctx context.Context, kc kubernetes.Interface, opts ClusterProfileOptions) (*v1alpha1.ClusterProfile, error) {
+	ns, err := kc.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cluster uid")
+	}
+	profile := v1alpha1.ClusterProfile{
+		ClusterUID: string(ns.UID),
+	}
+	if opts.CreationTimestamps {
+		profile.ClusterCreationTimestamp = ns.CreationTimestamp.DeepCopy()
+	}
+
+	if opts.NodeUIDs || opts.CreationTimestamps {
+		nodes, err := kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list nodes")
+		}
+		for _, node := range nodes.Items {
+			if opts.NodeUIDs {
+				h := sha256.Sum256([]byte(node.UID))
+				profile.NodeUIDs = append(profile.NodeUIDs, hex.EncodeToString(h[:]))
+			}
+			if opts.CreationTimestamps {
+				if profile.OldestNodeCreationTimestamp == nil || node.CreationTimestamp.Before(profile.OldestNodeCreationTimestamp) {
+					profile.OldestNodeCreationTimestamp = node.CreationTimestamp.DeepCopy()
+				}
+			}
+		}
+		sort.Strings(profile.NodeUIDs)
+	}
+
+	if opts.CACertificate {
+		cm, err := kc.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, kubeRootCAConfigMap, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read cluster CA certificate")
+		}
+		cert, err := info.ParseCertificate([]byte(cm.Data["ca.crt"]))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse cluster CA certificate")
+		}
+		profile.CACertificateSerial = cert.SerialNumber.String()
+	}
+
+	profile.Hash = hashClusterProfile(profile)
+	return &profile, nil
+}
+
+func hashClusterProfile(p v1alpha1.ClusterProfile) string {
+	parts := []string{p.ClusterUID, p.CACertificateSerial}
+	if p.ClusterCreationTimestamp != nil {
+		parts = append(parts, p.ClusterCreationTimestamp.UTC().String())
+	}
+	if p.OldestNodeCreationTimestamp != nil {
+		parts = append(parts, p.OldestNodeCreationTimestamp.UTC().String())
+	}
+	parts = append(parts, p.NodeUIDs...)
+	h := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,65 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectClusterProfile(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	kc := fake.NewSimpleClientset(
+		&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "cluster-1", CreationTimestamp: created}},
+		&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "node-uid-1", CreationTimestamp: metav1.NewTime(created.Add(time.Hour))}},
+		&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2", UID: "node-uid-2", CreationTimestamp: metav1.NewTime(created.Add(time.Minute))}},
+	)
+
+	profile, err := CollectClusterProfile(context.TODO(), kc, ClusterProfileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.ClusterUID != "cluster-1" || profile.NodeUIDs != nil || profile.ClusterCreationTimestamp != nil || profile.OldestNodeCreationTimestamp != nil {
+		t.Errorf("CollectClusterProfile() without consent = %+v, want only the cluster UID", profile)
+	}
+	uidOnly := profile.Hash
+
+	profile, err = CollectClusterProfile(context.TODO(), kc, ClusterProfileOptions{NodeUIDs: true, CreationTimestamps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256([]byte("node-uid-1"))
+	if len(profile.NodeUIDs) != 2 || (profile.NodeUIDs[0] != hex.EncodeToString(h[:]) && profile.NodeUIDs[1] != hex.EncodeToString(h[:])) {
+		t.Errorf("CollectClusterProfile() node UIDs = %v, want the hashes of both node UIDs", profile.NodeUIDs)
+	}
+	if !profile.ClusterCreationTimestamp.Equal(&created) {
+		t.Errorf("CollectClusterProfile() cluster creation timestamp = %v, want %v", profile.ClusterCreationTimestamp, created)
+	}
+	if want := created.Add(time.Minute); !profile.OldestNodeCreationTimestamp.Time.Equal(want) {
+		t.Errorf("CollectClusterProfile() oldest node creation timestamp = %v, want %v", profile.OldestNodeCreationTimestamp, want)
+	}
+	if profile.Hash == "" || profile.Hash == uidOnly {
+		t.Errorf("CollectClusterProfile() hash = %q, want it to cover the collected signals", profile.Hash)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/kubernetes/providers"
+
+	"github.com/pkg/errors"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Cluster identity providers that can be selected via ClusterUIDOptions.Provider .
+const (
+	// ClusterIDProviderAuto detects the platform of the cluster and uses its cluster id provider.
+	ClusterIDProviderAuto       = "auto"
+	ClusterIDProviderKubeSystem = "kube-system"
+	ClusterIDProviderOpenShift  = "openshift"
+	ClusterIDProviderEKS        = "eks"
+	ClusterIDProviderGKE        = "gke"
+	ClusterIDProviderAKS        = "aks"
+)
+
+var clusterIDProviders = []string{
+	ClusterIDProviderAuto,
+	ClusterIDProviderKubeSystem,
+	ClusterIDProviderOpenShift,
+	ClusterIDProviderEKS,
+	ClusterIDProviderGKE,
+	ClusterIDProviderAKS,
+}
+
+const (
+	awsMetadataEndpoint   = "http://169.254.169.254/latest"
+	azureMetadataEndpoint = "http://169.254.169.254/metadata"
+	gcpMetadataEndpoint   = "http://metadata.google.internal/computeMetadata/v1"
+	metadataTimeout       = 5 * time.Second
+)
+
+// ClusterIDProvider returns the identity a license is bound to. Unlike the kube-system
+// namespace UID, identities like the OpenShift cluster ID or the cloud provider cluster id
+// survive cluster rebuilds.
+type ClusterIDProvider interface {
+	ClusterID(ctx context.Context) (string, error)
+}
+
+// ClusterIDProviderFunc adapts a function to the ClusterIDProvider interface.
+type ClusterIDProviderFunc func(ctx context.Context) (string, error)
+
+func (f ClusterIDProviderFunc) ClusterID(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticClusterID returns a provider for an explicitly configured cluster identity.
+func StaticClusterID(id string) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		if id == "" {
+			return "", errors.New("cluster id is not set")
+		}
+		return id, nil
+	})
+}
+
+// KubeSystemClusterID returns the UID of the kube-system namespace, with the fallbacks configured in opts.
+func KubeSystemClusterID(kc kubernetes.Interface, opts ClusterUIDOptions) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		return ReadClusterUID(ctx, kc, opts)
+	})
+}
+
+var clusterVersionResource = schema.GroupVersionResource{
+	Group:    "config.openshift.io",
+	Version:  "v1",
+	Resource: "clusterversions",
+}
+
+// OpenShiftClusterID returns the cluster ID of an OpenShift cluster, as recorded in its ClusterVersion.
+func OpenShiftClusterID(dc dynamic.Interface) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		obj, err := dc.Resource(clusterVersionResource).Get(ctx, "version", metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read OpenShift cluster version")
+		}
+		id, _, err := unstructured.NestedString(obj.Object, "spec", "clusterID")
+		if err != nil {
+			return "", err
+		}
+		if id == "" {
+			return "", errors.New("OpenShift cluster version has no cluster ID")
+		}
+		return id, nil
+	})
+}
+
+// GKEClusterID returns the UID of the GKE cluster, read from the metadata server.
+func GKEClusterID(hc *http.Client) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		return getMetadata(ctx, hc, http.MethodGet, gcpMetadataEndpoint+"/instance/attributes/cluster-uid",
+			map[string]string{"Metadata-Flavor": "Google"})
+	})
+}
+
+// AKSClusterID returns the id of the node resource group of the AKS cluster, read from the instance metadata service.
+func AKSClusterID(hc *http.Client) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		data, err := getMetadata(ctx, hc, http.MethodGet, azureMetadataEndpoint+"/instance/compute?api-version=2021-02-01",
+			map[string]string{"Metadata": "true"})
+		if err != nil {
+			return "", err
+		}
+		var compute struct {
+			SubscriptionID    string `json:"subscriptionId"`
+			ResourceGroupName string `json:"resourceGroupName"`
+		}
+		if err := json.Unmarshal([]byte(data), &compute); err != nil {
+			return "", errors.Wrap(err, "failed to parse instance metadata")
+		}
+		if compute.SubscriptionID == "" || compute.ResourceGroupName == "" {
+			return "", errors.New("instance metadata is missing the subscription or resource group")
+		}
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", compute.SubscriptionID, compute.ResourceGroupName), nil
+	})
+}
+
+func getMetadata(ctx context.Context, hc *http.Client, method, u string, headers map[string]string) (string, error) {
+	if hc == nil {
+		hc = &http.Client{Timeout: metadataTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read instance metadata")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read instance metadata")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read instance metadata %s: %s", u, resp.Status)
+	}
+	v := strings.TrimSpace(string(data))
+	if v == "" {
+		return "", fmt.Errorf("instance metadata %s is empty", u)
+	}
+	return v, nil
+}
+
+// NewClusterIDProvider returns the built-in provider named by opts.Provider . Defaults to the kube-system namespace UID.
+func NewClusterIDProvider(config *rest.Config, kc kubernetes.Interface, opts ClusterUIDOptions) (ClusterIDProvider, error) {
+	switch opts.Provider {
+	case "", ClusterIDProviderKubeSystem:
+		return KubeSystemClusterID(kc, opts), nil
+	case ClusterIDProviderOpenShift:
+		dc, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return OpenShiftClusterID(dc), nil
+	case ClusterIDProviderEKS:
+		return EKSClusterID(kc, nil), nil
+	case ClusterIDProviderGKE:
+		return GKEClusterID(nil), nil
+	case ClusterIDProviderAKS:
+		return AKSClusterID(nil), nil
+	case ClusterIDProviderAuto:
+		return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+			p, err := providers.Detect(ctx, kc)
+			if kerr.IsForbidden(err) {
+				// the platform can't be detected without permissions, so bind to the kube-system namespace
+				p, err = providers.Generic, nil
+			}
+			if err != nil {
+				return "", errors.Wrap(err, "failed to detect the cluster platform")
+			}
+			opts := opts
+			opts.Provider = string(p)
+			cp, err := NewClusterIDProvider(config, kc, opts)
+			if err != nil {
+				return "", err
+			}
+			return cp.ClusterID(ctx)
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown cluster id provider %q", opts.Provider)
+}
+
+// SetClusterIDProvider replaces the provider of the identity licenses are bound to.
+func (le *LicenseEnforcer) SetClusterIDProvider(p ClusterIDProvider) {
+	le.clusterIDProvider = p
+}
@@ -0,0 +1,137 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterLicenseResyncPeriod is the maximum time between two verifications of a ClusterLicense,
+// so that its status follows the validity period of the license.
+const clusterLicenseResyncPeriod = time.Hour
+
+// ClusterLicenseReconciler verifies ClusterLicense objects and keeps their status current.
+type ClusterLicenseReconciler struct {
+	kc         client.Client
+	opts       verifier.VerifyOptions
+	clock      clock.Clock
+	clusterUID string
+}
+
+var _ reconcile.Reconciler = &ClusterLicenseReconciler{}
+
+// NewClusterLicenseReconciler returns a reconciler that verifies ClusterLicenses against the license CA
+// compiled into the product.
+func NewClusterLicenseReconciler(kc client.Client) (*ClusterLicenseReconciler, error) {
+	caData, err := info.LoadLicenseCA()
+	if err != nil {
+		return nil, err
+	}
+	caCerts, err := info.ParseCertificates(caData)
+	if err != nil {
+		return nil, err
+	}
+	c := clock.RealClock{}
+	return &ClusterLicenseReconciler{
+		kc: kc,
+		opts: verifier.VerifyOptions{
+			ParserOptions: verifier.ParserOptions{
+				CACert:    caCerts[0],
+				CACerts:   caCerts[1:],
+				Clock:     c,
+				ClockSkew: verifier.DefaultClockSkew,
+			},
+			ProductVersion: info.ProductVersion,
+		},
+		clock: c,
+	}, nil
+}
+
+// SetupWithManager registers the reconciler with the manager of the product.
+func (r *ClusterLicenseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := v1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterLicense{}).
+		Complete(r)
+}
+
+// Reconcile verifies the license of a ClusterLicense for its product and writes the result into its status.
+func (r *ClusterLicenseReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var obj v1alpha1.ClusterLicense
+	if err := r.kc.Get(ctx, req.NamespacedName, &obj); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	uid, err := r.readClusterUID(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	opts := r.opts
+	opts.ClusterUID = uid
+	opts.License = []byte(obj.Spec.License)
+	opts.Features = obj.Spec.Product
+	license, verr := verifier.CheckLicense(opts)
+	now := r.clock.Now()
+	status := verifier.NewLicenseStatus(license, verr, now)
+
+	patch := client.MergeFrom(obj.DeepCopy())
+	obj.Status.ObservedGeneration = obj.Generation
+	obj.Status.Valid = verr == nil
+	obj.Status.ID = status.License.ID
+	obj.Status.Status = status.License.Status
+	obj.Status.PlanName = status.License.PlanName
+	obj.Status.Features = status.License.Features
+	obj.Status.NotAfter = status.License.NotAfter
+	status.SetConditions(&obj.Status.Conditions)
+	if err := r.kc.Status().Patch(ctx, &obj, patch); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to update status of ClusterLicense %s", obj.Name)
+	}
+
+	requeueAfter := clusterLicenseResyncPeriod
+	if license.NotAfter != nil && license.NotAfter.After(now) && license.NotAfter.Sub(now) < requeueAfter {
+		requeueAfter = license.NotAfter.Sub(now) + time.Second
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// readClusterUID reads the UID of the kube-system namespace once.
+func (r *ClusterLicenseReconciler) readClusterUID(ctx context.Context) (string, error) {
+	if r.clusterUID != "" {
+		return r.clusterUID, nil
+	}
+	var ns core.Namespace
+	if err := r.kc.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, &ns); err != nil {
+		return "", errors.Wrap(err, "failed to read cluster UID")
+	}
+	r.clusterUID = string(ns.UID)
+	return r.clusterUID, nil
+}
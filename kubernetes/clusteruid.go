@@ -0,0 +1,169 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EnvClusterUID is used as the cluster UID, if the kube-system namespace can't be read.
+	// It never overrides the UID of a readable kube-system namespace.
+	EnvClusterUID = "LICENSE_CLUSTER_UID"
+	// ClusterUIDKey is the default ConfigMap key holding the cluster UID.
+	ClusterUIDKey = "clusterUID"
+)
+
+// ClusterUIDOptions configures how the cluster UID is read.
+// The UID of the kube-system namespace is used. Only if reading it is forbidden, the ConfigMap and
+// then the LICENSE_CLUSTER_UID environment variable are used instead. Other errors, eg. an unavailable
+// api server, are returned, so that the fallbacks can't replace the UID of the cluster.
+type ClusterUIDOptions struct {
+	// Namespace and Name of the ConfigMap holding the cluster UID for users that can't
+	// read the kube-system namespace.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Key of the ConfigMap. Defaults to clusterUID.
+	Key string `json:"key,omitempty"`
+	// Provider of the cluster identity, one of auto, kube-system, openshift, eks, gke or aks.
+	// Defaults to the UID of the kube-system namespace. auto detects the platform of the cluster.
+	Provider string `json:"provider,omitempty"`
+	// Backoff for retrying transient api errors. Defaults to 4 attempts starting at 500ms.
+	Backoff *wait.Backoff `json:"-"`
+}
+
+var defaultClusterUIDBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Steps:    4,
+}
+
+// ClusterUIDReader reads the cluster UID once and caches it.
+type ClusterUIDReader struct {
+	kc   kubernetes.Interface
+	opts ClusterUIDOptions
+
+	mu  sync.Mutex
+	uid string
+}
+
+func NewClusterUIDReader(kc kubernetes.Interface, opts ClusterUIDOptions) *ClusterUIDReader {
+	return &ClusterUIDReader{kc: kc, opts: opts}
+}
+
+// ClusterUID returns the cached cluster UID, reading it on first use.
+func (r *ClusterUIDReader) ClusterUID(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.uid != "" {
+		return r.uid, nil
+	}
+	uid, err := ReadClusterUID(ctx, r.kc, r.opts)
+	if err != nil {
+		return "", err
+	}
+	r.uid = uid
+	return uid, nil
+}
+
+// ReadClusterUID reads the cluster UID without caching.
+func ReadClusterUID(ctx context.Context, kc kubernetes.Interface, opts ClusterUIDOptions) (string, error) {
+	backoff := defaultClusterUIDBackoff
+	if opts.Backoff != nil {
+		backoff = *opts.Backoff
+	}
+
+	var errs []error
+	var uid string
+	err := retry(ctx, backoff, func(ctx context.Context) error {
+		ns, err := kc.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+		if err == nil {
+			uid = string(ns.UID)
+		}
+		return err
+	})
+	if err == nil {
+		return uid, nil
+	}
+	if !kerr.IsForbidden(err) && !kerr.IsUnauthorized(err) {
+		return "", errors.Wrap(err, "failed to read kube-system namespace")
+	}
+	errs = append(errs, errors.Wrap(err, "failed to read kube-system namespace"))
+
+	if opts.Name != "" {
+		key := opts.Key
+		if key == "" {
+			key = ClusterUIDKey
+		}
+		err = retry(ctx, backoff, func(ctx context.Context) error {
+			cm, err := kc.CoreV1().ConfigMaps(opts.Namespace).Get(ctx, opts.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			uid = cm.Data[key]
+			if uid == "" {
+				return fmt.Errorf("configmap %s/%s is missing key %s", opts.Namespace, opts.Name, key)
+			}
+			return nil
+		})
+		if err == nil {
+			return uid, nil
+		}
+		errs = append(errs, errors.Wrapf(err, "failed to read cluster UID from configmap %s/%s", opts.Namespace, opts.Name))
+	}
+
+	if v := os.Getenv(EnvClusterUID); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("failed to detect cluster UID: %v", errs)
+}
+
+// retry calls fn until it succeeds, fails with a permanent error or the backoff is exhausted.
+func retry(ctx context.Context, backoff wait.Backoff, fn func(ctx context.Context) error) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = fn(ctx)
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case kerr.IsForbidden(lastErr), kerr.IsUnauthorized(lastErr), kerr.IsNotFound(lastErr):
+			return false, lastErr
+		default:
+			return false, nil
+		}
+	})
+	if wait.Interrupted(err) && lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+// SetClusterUIDOptions configures the fallbacks used to read the cluster UID.
+func (le *LicenseEnforcer) SetClusterUIDOptions(opts ClusterUIDOptions) {
+	le.clusterUIDOpts = opts
+}
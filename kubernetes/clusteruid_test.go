@@ -0,0 +1,86 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestReadClusterUID(t *testing.T) {
+	const (
+		kubeSystemUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+		configMapUID  = "3c1f0a2e-7b1d-4c59-9e0a-2a1b3c4d5e6f"
+		envUID        = "5d2e1b3f-8c2e-4d6a-af1b-3b2c4d5e6f70"
+	)
+	forbidden := kerr.NewForbidden(schema.GroupResource{Resource: "namespaces"}, metav1.NamespaceSystem, errors.New("denied"))
+	unavailable := kerr.NewServiceUnavailable("unavailable")
+
+	tests := []struct {
+		name      string
+		getErr    error
+		configMap bool
+		env       string
+		want      string
+		wantErr   bool
+	}{
+		{name: "kube-system", configMap: true, env: envUID, want: kubeSystemUID},
+		{name: "forbidden, configmap", getErr: forbidden, configMap: true, env: envUID, want: configMapUID},
+		{name: "forbidden, env", getErr: forbidden, env: envUID, want: envUID},
+		{name: "forbidden, no fallback", getErr: forbidden, wantErr: true},
+		{name: "unavailable", getErr: unavailable, configMap: true, env: envUID, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvClusterUID, tt.env)
+			objs := []runtime.Object{
+				&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: types.UID(kubeSystemUID)}},
+			}
+			if tt.configMap {
+				objs = append(objs, &core.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster-uid"},
+					Data:       map[string]string{ClusterUIDKey: configMapUID},
+				})
+			}
+			kc := fake.NewSimpleClientset(objs...)
+			if tt.getErr != nil {
+				kc.PrependReactor("get", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.getErr
+				})
+			}
+			got, err := ReadClusterUID(context.TODO(), kc, ClusterUIDOptions{
+				Namespace: "default",
+				Name:      "cluster-uid",
+				Backoff:   &wait.Backoff{Steps: 1},
+			})
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ReadClusterUID() = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
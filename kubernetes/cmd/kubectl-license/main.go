@@ -0,0 +1,127 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-license is a kubectl plugin to inspect, verify and apply AppsCode licenses.
+// Install it into the PATH and run it as kubectl license <command>.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.bytebuilders.dev/license-verifier/cmd/license-verifier/cmds"
+	"go.bytebuilders.dev/license-verifier/kubernetes"
+
+	"github.com/spf13/cobra"
+	verifier "go.bytebuilders.dev/license-verifier"
+	kubernetesclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func main() {
+	if err := NewRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// clientOptions selects the cluster, like the matching kubectl flags.
+type clientOptions struct {
+	kubeconfig string
+	context    string
+}
+
+func NewRootCmd() *cobra.Command {
+	var opts clientOptions
+	cmd := &cobra.Command{
+		Use:               "kubectl license",
+		Short:             "Inspect, verify and apply AppsCode licenses",
+		SilenceUsage:      true,
+		DisableAutoGenTag: true,
+	}
+	cmd.PersistentFlags().StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	cmd.PersistentFlags().StringVar(&opts.context, "context", "", "Name of the kubeconfig context to use")
+
+	cmd.AddCommand(cmds.NewCmdInspect())
+	cmd.AddCommand(cmds.NewCmdVerifyForCluster(opts.clusterUID))
+	cmd.AddCommand(NewCmdApply(&opts))
+	return cmd
+}
+
+func NewCmdApply(opts *clientOptions) *cobra.Command {
+	var (
+		file      string
+		features  string
+		namespace string
+		name      string
+	)
+	cmd := &cobra.Command{
+		Use:     "apply",
+		Short:   "Verify a license file and store it in the shared license Secret",
+		Example: `  kubectl license apply -f license.txt`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kc, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+			clusterUID, err := kubernetes.ReadClusterUID(cmd.Context(), kc, kubernetes.ClusterUIDOptions{})
+			if err != nil {
+				return err
+			}
+			vopts, err := cmds.NewVerifyOptions(file, clusterUID, features)
+			if err != nil {
+				return err
+			}
+			license, err := verifier.VerifyLicense(vopts)
+			if err != nil {
+				return fmt.Errorf("license is not valid for cluster %s: %w", clusterUID, err)
+			}
+			shared := kubernetes.SharedLicense{Namespace: namespace, Name: name}
+			if err := kubernetes.StoreSharedLicense(cmd.Context(), kc, shared, license); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "license %s stored in secret %s/%s\n", license.ID, namespace, name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to the license file")
+	cmd.Flags().StringVar(&features, "features", "", "Comma separated features the license must include. Defaults to the features of the license")
+	cmd.Flags().StringVar(&namespace, "namespace", kubernetes.SharedLicenseNamespace, "Namespace of the shared license Secret")
+	cmd.Flags().StringVar(&name, "name", kubernetes.SharedLicenseName, "Name of the shared license Secret")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+// clusterUID reads the UID of the kube-system namespace of the current cluster.
+func (opts *clientOptions) clusterUID(ctx context.Context) (string, error) {
+	kc, err := opts.newClient()
+	if err != nil {
+		return "", err
+	}
+	return kubernetes.ReadClusterUID(ctx, kc, kubernetes.ClusterUIDOptions{})
+}
+
+func (opts *clientOptions) newClient() (kubernetesclient.Interface, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.context}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetesclient.NewForConfig(config)
+}
@@ -0,0 +1,344 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	EnvLicenseFile          = "LICENSE_FILE"
+	EnvLicenseCheckInterval = "LICENSE_CHECK_INTERVAL"
+)
+
+// Config holds all the options of the license enforcer.
+// It can be loaded from yaml or json and overridden by environment variables.
+type Config struct {
+	LicenseFile   string          `json:"licenseFile,omitempty"`
+	CheckInterval metav1.Duration `json:"checkInterval,omitempty"`
+	// Jitter stretches every check interval by a random factor in [0, Jitter),
+	// so that many operator pods don't re-verify at the same time.
+	Jitter float64 `json:"jitter,omitempty"`
+	// VerifyOnStartup verifies the license immediately instead of waiting for the first interval.
+	// Defaults to true.
+	VerifyOnStartup *bool                 `json:"verifyOnStartup,omitempty"`
+	RevocationList  *RevocationListSource `json:"revocationList,omitempty"`
+	Issuer          *IssuerConfig         `json:"issuer,omitempty"`
+	// CABundle trusts the license CAs in a ConfigMap or Secret in addition to the license CA
+	// compiled into the product, eg. the new license CA during a CA rotation. The CAs must be
+	// cross-signed by a license CA compiled into the product.
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+	// OCSP checks licenses with the OCSP responder embedded in the license certificate.
+	OCSP *OCSPConfig `json:"ocsp,omitempty"`
+	// UsageReporting configures the periodic report of anonymized usage to the license issuer.
+	UsageReporting UsageReporting `json:"usageReporting,omitempty"`
+	// ErrorBudget tolerates sporadic verification failures instead of enforcing on the first one.
+	ErrorBudget *ErrorBudget `json:"errorBudget,omitempty"`
+	// FlapDamping ignores verification status changes that happen too often.
+	FlapDamping *FlapDamping `json:"flapDamping,omitempty"`
+	// Coordination elects a leader among the replicas of the product, so that only the
+	// leader records events, reports usage and talks to the license issuer.
+	Coordination *Coordination `json:"coordination,omitempty"`
+	// SharedLicense shares verified licenses with the other AppsCode products in the cluster
+	// through a well-known Secret, so that they don't each contact the license issuer.
+	SharedLicense *SharedLicense `json:"sharedLicense,omitempty"`
+	// FailureThreshold retries transient verification failures with exponential backoff
+	// and only enforces them after they persisted. Defaults to 3 consecutive failures.
+	FailureThreshold *FailureThreshold `json:"failureThreshold,omitempty"`
+	// FeatureAliases maps a license feature to the features it implies, eg. an old product name to its new name.
+	FeatureAliases verifier.FeatureAliases `json:"featureAliases,omitempty"`
+	// Namespaces the product manages, which must be covered by a license restricted to namespaces.
+	// Defaults to the namespace of the pod.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// CapacityEnforcement configures how the node and cpu limits of a license are enforced.
+	CapacityEnforcement *CapacityEnforcement `json:"capacityEnforcement,omitempty"`
+	// FailurePolicy decides what happens when license verification fails. Defaults to CrashPod.
+	// The Callback policy can only be used with a handler set using SetFailureHandler.
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+	// TrialFailurePolicy decides what happens when a trial license has expired. Defaults to FailurePolicy.
+	TrialFailurePolicy FailurePolicy `json:"trialFailurePolicy,omitempty"`
+	// GracePeriod keeps accepting an expired license for the given duration, with a warning.
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+	// ExpiryWarningDays are the remaining days at which a warning event is recorded before the license expires.
+	// Defaults to 30, 14, 7 and 1 days.
+	ExpiryWarningDays []int `json:"expiryWarningDays,omitempty"`
+	// ClockSkew tolerates a node clock that is off when checking the validity period of the license.
+	// Defaults to 5m.
+	ClockSkew *metav1.Duration `json:"clockSkew,omitempty"`
+	// WatchLicenseFile re-verifies the license within seconds of the license file being updated.
+	WatchLicenseFile bool `json:"watchLicenseFile,omitempty"`
+	// LicenseStacking stacks add-on licenses on the base license, when the license file is a
+	// directory or a bundle of licenses.
+	LicenseStacking bool `json:"licenseStacking,omitempty"`
+	// LicenseSecret reads the license from a Secret instead of LicenseFile and
+	// re-verifies it whenever the Secret changes.
+	LicenseSecret *LicenseSecretReference `json:"licenseSecret,omitempty"`
+	// LicenseRemovalPolicy decides what happens when the license file or Secret is deleted.
+	// Setting it enables watching the license file. Defaults to Enforce.
+	LicenseRemovalPolicy LicenseRemovalPolicy `json:"licenseRemovalPolicy,omitempty"`
+	// LicenseRemovalGracePeriod is the time given to restore a deleted license with the Grace policy.
+	LicenseRemovalGracePeriod metav1.Duration `json:"licenseRemovalGracePeriod,omitempty"`
+	// ClusterUID configures the provider of the cluster identity and the fallbacks for reading the cluster UID.
+	ClusterUID *ClusterUIDOptions `json:"clusterUID,omitempty"`
+	// StatusConfigMap publishes the license status to a ConfigMap after every verification.
+	StatusConfigMap *StatusConfigMap `json:"statusConfigMap,omitempty"`
+}
+
+// LoadConfig parses a yaml or json encoded Config, applies environment variable
+// overrides and defaults, and validates the result.
+func LoadConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse license verifier config: %w", err)
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	cfg.Default()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ApplyEnv overrides config fields with values from environment variables, if set.
+func (c *Config) ApplyEnv() error {
+	if v, ok := os.LookupEnv(EnvLicenseFile); ok {
+		c.LicenseFile = v
+	}
+	if v, ok := os.LookupEnv(EnvLicenseCheckInterval); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", EnvLicenseCheckInterval, v, err)
+		}
+		c.CheckInterval.Duration = d
+	}
+	if v, ok := os.LookupEnv(EnvUsageReporting); ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", EnvUsageReporting, v, err)
+		}
+		c.UsageReporting.Disabled = !enabled
+	}
+	return nil
+}
+
+func (c *Config) Default() {
+	if c.CheckInterval.Duration == 0 {
+		c.CheckInterval.Duration = licenseCheckInterval
+	}
+	if c.VerifyOnStartup == nil {
+		verify := true
+		c.VerifyOnStartup = &verify
+	}
+	if c.CABundle != nil {
+		if c.CABundle.Kind == "" {
+			c.CABundle.Kind = RevocationListKindConfigMap
+		}
+		if c.CABundle.Key == "" {
+			c.CABundle.Key = CABundleKey
+		}
+	}
+	if c.RevocationList != nil {
+		if c.RevocationList.Kind == "" {
+			c.RevocationList.Kind = RevocationListKindConfigMap
+		}
+		if c.RevocationList.Key == "" {
+			c.RevocationList.Key = RevocationListKey
+		}
+		if c.RevocationList.Policy == "" {
+			c.RevocationList.Policy = verifier.RevocationPolicyFailOpen
+		}
+	}
+	if c.OCSP != nil && c.OCSP.Policy == "" {
+		c.OCSP.Policy = verifier.RevocationPolicyFailOpen
+	}
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.CheckInterval.Duration < time.Minute {
+		errs = append(errs, fmt.Errorf("checkInterval must be at least 1m, found %s", c.CheckInterval.Duration))
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("jitter must be between 0 and 1, found %v", c.Jitter))
+	}
+	if src := c.CABundle; src != nil {
+		if src.Kind != RevocationListKindConfigMap && src.Kind != RevocationListKindSecret {
+			errs = append(errs, fmt.Errorf("caBundle.kind must be %s or %s, found %q", RevocationListKindConfigMap, RevocationListKindSecret, src.Kind))
+		}
+		if src.Namespace == "" || src.Name == "" {
+			errs = append(errs, fmt.Errorf("caBundle.namespace and caBundle.name are required"))
+		}
+	}
+	if src := c.RevocationList; src != nil {
+		switch src.Kind {
+		case RevocationListKindConfigMap, RevocationListKindSecret:
+			if src.Namespace == "" {
+				errs = append(errs, fmt.Errorf("revocationList.namespace is required"))
+			}
+			if src.Name == "" {
+				errs = append(errs, fmt.Errorf("revocationList.name is required"))
+			}
+		case RevocationListKindIssuer:
+			if d := src.RefreshInterval.Duration; d != 0 && d < time.Minute {
+				errs = append(errs, fmt.Errorf("revocationList.refreshInterval must be at least 1m, found %s", d))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("revocationList.kind must be %s, %s or %s, found %q", RevocationListKindConfigMap, RevocationListKindSecret, RevocationListKindIssuer, src.Kind))
+		}
+		if src.Policy != verifier.RevocationPolicyFailOpen && src.Policy != verifier.RevocationPolicyFailClosed {
+			errs = append(errs, fmt.Errorf("revocationList.policy must be %s or %s, found %q", verifier.RevocationPolicyFailOpen, verifier.RevocationPolicyFailClosed, src.Policy))
+		}
+	}
+	if err := c.UsageReporting.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.OCSP != nil {
+		if c.OCSP.Policy != verifier.RevocationPolicyFailOpen && c.OCSP.Policy != verifier.RevocationPolicyFailClosed {
+			errs = append(errs, fmt.Errorf("ocsp.policy must be %s or %s, found %q", verifier.RevocationPolicyFailOpen, verifier.RevocationPolicyFailClosed, c.OCSP.Policy))
+		}
+		if c.OCSP.Timeout.Duration < 0 {
+			errs = append(errs, fmt.Errorf("ocsp.timeout must not be negative, found %s", c.OCSP.Timeout.Duration))
+		}
+	}
+	if c.ErrorBudget != nil {
+		if err := c.ErrorBudget.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.CapacityEnforcement != nil {
+		if err := c.CapacityEnforcement.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.FlapDamping != nil {
+		if err := c.FlapDamping.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Coordination != nil {
+		if err := c.Coordination.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.FailureThreshold != nil {
+		if err := c.FailureThreshold.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.LicenseSecret != nil && (c.LicenseSecret.Namespace == "" || c.LicenseSecret.Name == "") {
+		errs = append(errs, fmt.Errorf("licenseSecret.namespace and licenseSecret.name are required"))
+	}
+	switch c.LicenseRemovalPolicy {
+	case "", LicenseRemovalEnforce, LicenseRemovalWarn:
+	case LicenseRemovalGrace:
+		if c.LicenseRemovalGracePeriod.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("licenseRemovalGracePeriod is required for licenseRemovalPolicy %s", LicenseRemovalGrace))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("licenseRemovalPolicy must be %s, %s or %s, found %q", LicenseRemovalEnforce, LicenseRemovalWarn, LicenseRemovalGrace, c.LicenseRemovalPolicy))
+	}
+	for _, days := range c.ExpiryWarningDays {
+		if days <= 0 {
+			errs = append(errs, fmt.Errorf("expiryWarningDays must be positive, found %d", days))
+		}
+	}
+	if c.ClockSkew != nil && c.ClockSkew.Duration < 0 {
+		errs = append(errs, fmt.Errorf("clockSkew must not be negative, found %s", c.ClockSkew.Duration))
+	}
+	if c.GracePeriod.Duration < 0 {
+		errs = append(errs, fmt.Errorf("gracePeriod must not be negative, found %s", c.GracePeriod.Duration))
+	}
+	if c.FailurePolicy != "" && c.FailurePolicy != FailurePolicyCrashPod && c.FailurePolicy != FailurePolicyLogOnly {
+		errs = append(errs, fmt.Errorf("failurePolicy must be %s or %s, found %q", FailurePolicyCrashPod, FailurePolicyLogOnly, c.FailurePolicy))
+	}
+	if c.TrialFailurePolicy != "" && c.TrialFailurePolicy != FailurePolicyCrashPod && c.TrialFailurePolicy != FailurePolicyLogOnly {
+		errs = append(errs, fmt.Errorf("trialFailurePolicy must be %s or %s, found %q", FailurePolicyCrashPod, FailurePolicyLogOnly, c.TrialFailurePolicy))
+	}
+	if c.ClusterUID != nil && c.ClusterUID.Provider != "" && !sets.NewString(clusterIDProviders...).Has(c.ClusterUID.Provider) {
+		errs = append(errs, fmt.Errorf("clusterUID.provider must be one of %s, found %q", strings.Join(clusterIDProviders, ", "), c.ClusterUID.Provider))
+	}
+	if c.Issuer != nil && c.Issuer.ReacquireOnWrongCluster && c.Issuer.Token == "" {
+		errs = append(errs, fmt.Errorf("issuer.token is required to reacquire licenses"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// NewLicenseEnforcerForConfig returns a license enforcer configured using cfg.
+func NewLicenseEnforcerForConfig(config *rest.Config, cfg Config) (*LicenseEnforcer, error) {
+	cfg.Default()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	le, err := NewLicenseEnforcer(config, cfg.LicenseFile)
+	if err != nil {
+		return le, err
+	}
+	le.checkInterval = cfg.CheckInterval.Duration
+	le.jitter = cfg.Jitter
+	le.opts.GracePeriod = cfg.GracePeriod.Duration
+	if cfg.ClockSkew != nil {
+		le.SetClockSkew(cfg.ClockSkew.Duration)
+	}
+	if cfg.ExpiryWarningDays != nil {
+		le.SetExpiryWarnings(cfg.ExpiryWarningDays)
+	}
+	le.watchLicenseFile = cfg.WatchLicenseFile
+	le.licenseSecret = cfg.LicenseSecret
+	le.SetLicenseStacking(cfg.LicenseStacking)
+	le.SetLicenseRemovalPolicy(cfg.LicenseRemovalPolicy, cfg.LicenseRemovalGracePeriod.Duration)
+	le.verifyOnStartup = *cfg.VerifyOnStartup
+	le.caBundle = cfg.CABundle
+	le.revocation = cfg.RevocationList
+	le.ocsp = cfg.OCSP
+	le.SetUsageReporting(cfg.UsageReporting)
+	le.issuer = cfg.Issuer
+	le.SetErrorBudget(cfg.ErrorBudget)
+	le.SetFlapDamping(cfg.FlapDamping)
+	le.SetFailureThreshold(cfg.FailureThreshold)
+	le.SetCoordination(cfg.Coordination)
+	le.SetSharedLicense(cfg.SharedLicense)
+	le.SetFeatureAliases(cfg.FeatureAliases)
+	if len(cfg.Namespaces) > 0 {
+		le.SetNamespaces(cfg.Namespaces...)
+	}
+	le.SetCapacityEnforcement(cfg.CapacityEnforcement)
+	le.SetStatusConfigMap(cfg.StatusConfigMap)
+	if cfg.ClusterUID != nil {
+		le.SetClusterUIDOptions(*cfg.ClusterUID)
+	}
+	if err := le.SetFailurePolicy(cfg.FailurePolicy); err != nil {
+		return le, err
+	}
+	if err := le.SetTrialFailurePolicy(cfg.TrialFailurePolicy); err != nil {
+		return le, err
+	}
+	return le, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+	"kmodules.xyz/client-go/meta"
+)
+
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// Coordination makes the replicas of a product elect a leader using a coordination.k8s.io Lease.
+// Every replica keeps verifying the license, so that each of them enforces it, and records the warning
+// events of its own failures. Only the leader records the other events, publishes the status ConfigMap,
+// reports usage and talks to the license issuer to reacquire or verify licenses online. Followers use the
+// offline verification result.
+type Coordination struct {
+	// LeaseName defaults to <product>-license-verifier.
+	LeaseName string `json:"leaseName,omitempty"`
+	// LeaseNamespace defaults to the namespace of the pod.
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+	// LeaseDuration, RenewDeadline and RetryPeriod default to 15s, 10s and 2s.
+	LeaseDuration metav1.Duration `json:"leaseDuration,omitempty"`
+	RenewDeadline metav1.Duration `json:"renewDeadline,omitempty"`
+	RetryPeriod   metav1.Duration `json:"retryPeriod,omitempty"`
+}
+
+func (c Coordination) Validate() error {
+	if c.LeaseDuration.Duration < 0 || c.RenewDeadline.Duration < 0 || c.RetryPeriod.Duration < 0 {
+		return fmt.Errorf("coordination.leaseDuration, coordination.renewDeadline and coordination.retryPeriod must not be negative")
+	}
+	return nil
+}
+
+// leaderState tracks whether this replica leads the license verification.
+type leaderState struct {
+	leading atomic.Bool
+}
+
+// SetCoordination makes the replicas of the product elect a leader for the side effects of license verification.
+func (le *LicenseEnforcer) SetCoordination(c *Coordination) {
+	le.coordination = c
+}
+
+// IsLeader returns true if this replica records events and talks to the license issuer, which is
+// always the case without coordination.
+func (le *LicenseEnforcer) IsLeader() bool {
+	return le.coordination == nil || le.leader.leading.Load()
+}
+
+// startLeaderElection starts taking part in the leader election and waits until the first result
+// is known, so that the first license verification knows whether this replica leads. It stops
+// waiting after the lease duration, eg. if the Lease can't be read.
+func (le *LicenseEnforcer) startLeaderElection(ctx context.Context) {
+	c := le.coordination
+	if c == nil || le.kc == nil {
+		return
+	}
+	decided := make(chan struct{})
+	var once sync.Once
+	go le.runLeaderElection(ctx, func() { once.Do(func() { close(decided) }) })
+
+	select {
+	case <-decided:
+	case <-ctx.Done():
+	case <-time.After(durationOrDefault(c.LeaseDuration, DefaultLeaseDuration)):
+		klog.Warningln("License verification leader election has no result yet, verifying as follower")
+	}
+}
+
+func durationOrDefault(d metav1.Duration, def time.Duration) time.Duration {
+	if d.Duration > 0 {
+		return d.Duration
+	}
+	return def
+}
+
+// runLeaderElection takes part in the leader election until ctx is cancelled. decided is called
+// once this replica leads or has observed another leader.
+func (le *LicenseEnforcer) runLeaderElection(ctx context.Context, decided func()) {
+	c := le.coordination
+	name := c.LeaseName
+	if name == "" {
+		name = info.ProductName + "-license-verifier"
+	}
+	namespace := c.LeaseNamespace
+	if namespace == "" {
+		namespace = meta.PodNamespace()
+	}
+	id := meta.PodName()
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Client:     le.kc.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: id},
+		},
+		LeaseDuration:   durationOrDefault(c.LeaseDuration, DefaultLeaseDuration),
+		RenewDeadline:   durationOrDefault(c.RenewDeadline, DefaultRenewDeadline),
+		RetryPeriod:     durationOrDefault(c.RetryPeriod, DefaultRetryPeriod),
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				klog.Infof("Leading license verification using lease %s/%s", namespace, name)
+				le.leader.leading.Store(true)
+				decided()
+			},
+			OnStoppedLeading: func() {
+				le.leader.leading.Store(false)
+			},
+			OnNewLeader: func(identity string) {
+				// OnStartedLeading reports this replica as the leader
+				if identity != id {
+					decided()
+				}
+			},
+		},
+	})
+	if err != nil {
+		// without a working election, every replica acts on its own as if coordination was disabled
+		klog.Errorf("Failed to start license verification leader election, acting as leader. Reason: %v", err)
+		le.leader.leading.Store(true)
+		decided()
+		return
+	}
+	wait.UntilWithContext(ctx, elector.Run, durationOrDefault(c.RetryPeriod, DefaultRetryPeriod))
+}
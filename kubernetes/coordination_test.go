@@ -0,0 +1,83 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordination "k8s.io/api/coordination/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func testCoordination() *Coordination {
+	return &Coordination{
+		LeaseName:      "license-verifier",
+		LeaseNamespace: "default",
+		LeaseDuration:  metav1.Duration{Duration: 3 * time.Second},
+		RenewDeadline:  metav1.Duration{Duration: 2 * time.Second},
+		RetryPeriod:    metav1.Duration{Duration: 100 * time.Millisecond},
+	}
+}
+
+func TestStartLeaderElection(t *testing.T) {
+	now := metav1.NewMicroTime(time.Now())
+	held := &coordination.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "license-verifier"},
+		Spec: coordination.LeaseSpec{
+			HolderIdentity:       ptr.To("other-replica"),
+			LeaseDurationSeconds: ptr.To[int32](60),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		kc     *fake.Clientset
+		leader bool
+	}{
+		{"no leader", fake.NewSimpleClientset(), true},
+		{"other leader", fake.NewSimpleClientset(held), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			le := &LicenseEnforcer{kc: tt.kc, coordination: testCoordination()}
+
+			start := time.Now()
+			le.startLeaderElection(ctx)
+			if elapsed := time.Since(start); elapsed >= le.coordination.LeaseDuration.Duration {
+				t.Errorf("startLeaderElection() waited %s for the election result", elapsed)
+			}
+			if got := le.IsLeader(); got != tt.leader {
+				t.Errorf("IsLeader() = %v, want %v", got, tt.leader)
+			}
+			if !le.recordsEvent(core.EventTypeWarning) {
+				t.Errorf("recordsEvent(%s) = false, every replica must record its own warnings", core.EventTypeWarning)
+			}
+			if got := le.recordsEvent(core.EventTypeNormal); got != tt.leader {
+				t.Errorf("recordsEvent(%s) = %v, want %v", core.EventTypeNormal, got, tt.leader)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FlapDamping keeps the verification status from oscillating when a license alternates
+// between valid and invalid, eg. because of clock skew around its expiry. A status change
+// is only accepted after the previous status was kept for MinDwell, and at most
+// MaxTransitions status changes are accepted within Window.
+type FlapDamping struct {
+	MinDwell       metav1.Duration `json:"minDwell"`
+	Window         metav1.Duration `json:"window,omitempty"`
+	MaxTransitions int             `json:"maxTransitions,omitempty"`
+}
+
+func (d FlapDamping) Validate() error {
+	if d.MinDwell.Duration < 0 {
+		return fmt.Errorf("flapDamping.minDwell must not be negative, found %s", d.MinDwell.Duration)
+	}
+	if d.MaxTransitions < 0 {
+		return fmt.Errorf("flapDamping.maxTransitions must not be negative, found %d", d.MaxTransitions)
+	}
+	if d.MaxTransitions > 0 && d.Window.Duration <= 0 {
+		return fmt.Errorf("flapDamping.window is required with flapDamping.maxTransitions")
+	}
+	return nil
+}
+
+// statusDamper tracks the accepted verification status and its recent transitions.
+type statusDamper struct {
+	opts FlapDamping
+
+	mu          sync.Mutex
+	initialized bool
+	failed      bool
+	since       time.Time
+	transitions []time.Time
+}
+
+// observe returns true if the observed status must be ignored in favor of the accepted status.
+func (d *statusDamper) observe(failed bool, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.initialized {
+		d.initialized, d.failed, d.since = true, failed, now
+		return false
+	}
+	if failed == d.failed {
+		return false
+	}
+
+	if d.opts.Window.Duration > 0 {
+		recent := d.transitions[:0]
+		for _, t := range d.transitions {
+			if now.Sub(t) < d.opts.Window.Duration {
+				recent = append(recent, t)
+			}
+		}
+		d.transitions = recent
+	}
+	if now.Sub(d.since) < d.opts.MinDwell.Duration {
+		return true
+	}
+	if d.opts.MaxTransitions > 0 && len(d.transitions) >= d.opts.MaxTransitions {
+		return true
+	}
+	d.failed, d.since = failed, now
+	if d.opts.Window.Duration > 0 {
+		d.transitions = append(d.transitions, now)
+	}
+	return false
+}
+
+// SetFlapDamping makes the enforcer ignore verification status changes that happen too often.
+func (le *LicenseEnforcer) SetFlapDamping(d *FlapDamping) {
+	le.damper = nil
+	if d != nil {
+		le.damper = &statusDamper{opts: *d}
+	}
+}
+
+// damped records the outcome of a verification attempt and returns true if a
+// status change must be ignored, so that events and enforcement don't oscillate.
+func (le *LicenseEnforcer) damped(err error) bool {
+	if le.damper == nil {
+		return false
+	}
+	return le.damper.observe(err != nil, le.clock.Now())
+}
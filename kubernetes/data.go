@@ -0,0 +1,72 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// EnvLicense holds the license as raw PEM or JWT, or base64 encoded. It is used if no
+// license file is configured, eg. in CI pipelines and serverless deployments that can't mount files.
+const EnvLicense = "LICENSE"
+
+// SetLicenseData makes the enforcer verify the given license instead of reading the license file.
+func (le *LicenseEnforcer) SetLicenseData(license []byte) {
+	le.licenseData = license
+}
+
+// VerifyLicenseData verifies whether the provided license is valid for the current cluster or not.
+func VerifyLicenseData(config *rest.Config, license []byte) error {
+	if info.SkipLicenseVerification() {
+		klog.Infoln("License verification skipped")
+		return nil
+	}
+
+	klog.V(8).Infoln("Verifying license.......")
+	le, err := NewLicenseEnforcer(config, "")
+	if err != nil {
+		return le.handleLicenseVerificationFailure(err)
+	}
+	le.SetLicenseData(license)
+	if err := checkLicenseFile(context.TODO(), le); err != nil {
+		return le.handleLicenseVerificationFailure(err)
+	}
+	return nil
+}
+
+// licenseFromEnv returns the license set in the LICENSE environment variable, if any.
+func licenseFromEnv() []byte {
+	v := bytes.TrimSpace([]byte(os.Getenv(EnvLicense)))
+	if len(v) == 0 || bytes.HasPrefix(v, []byte("-----")) || verifier.IsJWT(v) {
+		return v
+	}
+	data, err := base64.StdEncoding.DecodeString(string(v))
+	if err != nil {
+		// not base64 encoded, verification reports the malformed license
+		return v
+	}
+	return data
+}
@@ -0,0 +1,74 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+// LicenseDebugStatus is the license status served by StatusHandler.
+type LicenseDebugStatus struct {
+	ID          string                 `json:"id,omitempty"`
+	Status      v1alpha1.LicenseStatus `json:"status,omitempty"`
+	Features    []string               `json:"features,omitempty"`
+	NotAfter    *metav1.Time           `json:"notAfter,omitempty"`
+	ClusterUID  string                 `json:"clusterUID,omitempty"`
+	LastChecked *metav1.Time           `json:"lastChecked,omitempty"`
+	LastError   string                 `json:"lastError,omitempty"`
+	// Checks is the result of each check run by the last verification
+	Checks []verifier.CheckResult `json:"checks,omitempty"`
+}
+
+// StatusHandler returns a handler that serves the result of the last license verification as json.
+// Unlike Install, it never reads the license itself, so it can be mounted on an existing
+// metrics or debug mux for support diagnostics.
+func (le *LicenseEnforcer) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-content-type-options", "nosniff")
+
+		utilruntime.Must(json.NewEncoder(w).Encode(le.debugStatus()))
+	})
+}
+
+func (le *LicenseEnforcer) debugStatus() LicenseDebugStatus {
+	result, ok := le.LastVerification()
+	if !ok {
+		return LicenseDebugStatus{Status: v1alpha1.LicenseUnknown}
+	}
+	lastChecked := metav1.NewTime(result.Time)
+	status := LicenseDebugStatus{
+		ID:          result.License.ID,
+		Status:      result.License.Status,
+		Features:    result.License.Features,
+		NotAfter:    result.License.NotAfter,
+		ClusterUID:  result.ClusterUID,
+		LastChecked: &lastChecked,
+		Checks:      le.LastChecks(),
+	}
+	if result.Err != nil {
+		status.LastError = result.Err.Error()
+	}
+	return status
+}
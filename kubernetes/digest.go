@@ -0,0 +1,273 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/client"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	core_util "kmodules.xyz/client-go/core/v1"
+)
+
+const (
+	defaultDigestInterval = 7 * 24 * time.Hour
+	digestConfigMapKey    = "digest.json"
+	// digestSentKey records when the digest was last delivered to all destinations
+	digestSentKey = "sentAt"
+	// digestWebhookTimeout bounds the delivery to a hung webhook
+	digestWebhookTimeout = 30 * time.Second
+)
+
+// Digest summarizes the health of the license so that admins can plan renewals.
+type Digest struct {
+	GeneratedAt   metav1.Time            `json:"generatedAt"`
+	ClusterUID    string                 `json:"clusterUID"`
+	LicenseID     string                 `json:"licenseID,omitempty"`
+	PlanName      string                 `json:"planName,omitempty"`
+	Features      []string               `json:"features,omitempty"`
+	Status        v1alpha1.LicenseStatus `json:"status"`
+	Reason        string                 `json:"reason,omitempty"`
+	NotAfter      *metav1.Time           `json:"notAfter,omitempty"`
+	DaysRemaining int                    `json:"daysRemaining"`
+	Nodes         int                    `json:"nodes"`
+	NodeQuota     *v1alpha1.QuotaUsage   `json:"nodeQuota,omitempty"`
+	Support       *v1alpha1.SupportPlan  `json:"support,omitempty"`
+}
+
+// DigestOptions configures where the license digest is delivered.
+// At least one of WebhookURL or ConfigMap must be set.
+type DigestOptions struct {
+	// Interval between digests. Defaults to a week.
+	Interval   time.Duration
+	WebhookURL string
+	// ConfigMap is written with the digest under the digest.json key, so that an external mailer can pick it up.
+	ConfigMap *metav1.ObjectMeta
+	// IssuerURL and Token are used to report the node quota of the contract, if Token is set.
+	IssuerURL string
+	Token     string
+	// StateFile records when the digest was last delivered, so that a restart does not deliver it again
+	// within the same interval. The time is also recorded in ConfigMap, if set.
+	StateFile string
+}
+
+// GenerateDigest summarizes the current license health.
+func (le *LicenseEnforcer) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	license, _ := le.LoadLicenseWithContext(ctx)
+	uid, err := le.cachedClusterUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := le.clock.Now()
+	d := Digest{
+		GeneratedAt: metav1.NewTime(now),
+		ClusterUID:  uid,
+		LicenseID:   license.ID,
+		PlanName:    license.PlanName,
+		Features:    license.Features,
+		Status:      license.Status,
+		Reason:      license.Reason,
+		NotAfter:    license.NotAfter,
+		Support:     license.SupportPlan(),
+	}
+	if license.NotAfter != nil {
+		d.DaysRemaining = int(license.NotAfter.Sub(now).Hours() / 24)
+	}
+
+	nodes, err := le.kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	d.Nodes = len(nodes.Items)
+
+	if opts.Token != "" {
+		lc, err := client.NewClient(opts.IssuerURL, opts.Token, uid)
+		if err != nil {
+			return nil, err
+		}
+		quota, err := lc.GetQuota()
+		if err != nil {
+			klog.Warningf("failed to read license quota. Reason: %v", err)
+		} else {
+			d.NodeQuota = &quota.Nodes
+		}
+	}
+	return &d, nil
+}
+
+// RunDigest periodically generates a license digest and delivers it until stopCh is closed.
+// A digest delivered within the last interval, eg. before a restart, is not delivered again.
+func (le *LicenseEnforcer) RunDigest(opts DigestOptions, stopCh <-chan struct{}) error {
+	if opts.WebhookURL == "" && opts.ConfigMap == nil {
+		return fmt.Errorf("license digest requires a webhook url or a configmap")
+	}
+	if opts.Interval == 0 {
+		opts.Interval = defaultDigestInterval
+	}
+	if err := le.createClients(); err != nil {
+		return err
+	}
+
+	ctx := wait.ContextForChannel(stopCh)
+	next := le.nextDigest(ctx, opts)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-le.clock.After(next):
+		}
+		if err := le.sendDigest(ctx, opts); err != nil {
+			klog.Errorln("Failed to deliver license digest. Reason: ", err.Error())
+		}
+		next = opts.Interval
+	}
+}
+
+// nextDigest returns how long to wait until the digest of the current interval is due.
+func (le *LicenseEnforcer) nextDigest(ctx context.Context, opts DigestOptions) time.Duration {
+	sent, err := le.lastDigestSent(ctx, opts)
+	if err != nil {
+		klog.Warningf("Failed to read when the license digest was last delivered. Reason: %v", err)
+		return 0
+	}
+	if sent.IsZero() {
+		return 0
+	}
+	return max(0, sent.Add(opts.Interval).Sub(le.clock.Now()))
+}
+
+// sendDigest generates and delivers the digest, then records when it was delivered.
+func (le *LicenseEnforcer) sendDigest(ctx context.Context, opts DigestOptions) error {
+	d, err := le.GenerateDigest(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := le.deliverDigest(ctx, opts, d); err != nil {
+		return err
+	}
+	return le.recordDigestSent(ctx, opts, d.GeneratedAt.Time)
+}
+
+// lastDigestSent returns the latest delivery time recorded in the state file or the ConfigMap.
+func (le *LicenseEnforcer) lastDigestSent(ctx context.Context, opts DigestOptions) (time.Time, error) {
+	var values []string
+	if opts.StateFile != "" {
+		data, err := os.ReadFile(opts.StateFile)
+		if err != nil && !os.IsNotExist(err) {
+			return time.Time{}, err
+		}
+		values = append(values, string(bytes.TrimSpace(data)))
+	}
+	if opts.ConfigMap != nil {
+		cm, err := le.kc.CoreV1().ConfigMaps(opts.ConfigMap.Namespace).Get(ctx, opts.ConfigMap.Name, metav1.GetOptions{})
+		if err != nil && !kerr.IsNotFound(err) {
+			return time.Time{}, err
+		}
+		if err == nil {
+			values = append(values, cm.Data[digestSentKey])
+		}
+	}
+
+	var sent time.Time
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid license digest delivery time %q", v)
+		}
+		if t.After(sent) {
+			sent = t
+		}
+	}
+	return sent, nil
+}
+
+func (le *LicenseEnforcer) recordDigestSent(ctx context.Context, opts DigestOptions, sent time.Time) error {
+	v := sent.UTC().Format(time.RFC3339)
+	if opts.StateFile != "" {
+		if err := os.WriteFile(opts.StateFile, []byte(v), 0o644); err != nil {
+			return errors.Wrap(err, "failed to record license digest delivery")
+		}
+	}
+	if opts.ConfigMap != nil {
+		_, _, err := core_util.CreateOrPatchConfigMap(ctx, le.kc, *opts.ConfigMap, func(in *core.ConfigMap) *core.ConfigMap {
+			if in.Data == nil {
+				in.Data = map[string]string{}
+			}
+			in.Data[digestSentKey] = v
+			return in
+		}, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to record license digest delivery")
+		}
+	}
+	return nil
+}
+
+func (le *LicenseEnforcer) deliverDigest(ctx context.Context, opts DigestOptions, d *Digest) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	if opts.ConfigMap != nil {
+		_, _, err = core_util.CreateOrPatchConfigMap(ctx, le.kc, *opts.ConfigMap, func(in *core.ConfigMap) *core.ConfigMap {
+			if in.Data == nil {
+				in.Data = map[string]string{}
+			}
+			in.Data[digestConfigMapKey] = string(data)
+			return in
+		}, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to write license digest")
+		}
+	}
+
+	if opts.WebhookURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.WebhookURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		hc := http.Client{Timeout: digestWebhookTimeout}
+		resp, err := hc.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to post license digest")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("failed to post license digest, status: %s", resp.Status)
+		}
+	}
+	return nil
+}
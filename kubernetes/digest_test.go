@@ -0,0 +1,128 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestGenerateDigest(t *testing.T) {
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	issuer := licensetest.NewTestIssuer(t)
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kc := fake.NewSimpleClientset(
+		&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: clusterUID}},
+		&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+	le := &LicenseEnforcer{kc: kc, clock: clocktesting.NewFakeClock(time.Now()), licenseData: data}
+	le.opts.Features = "kubedb-enterprise"
+	le.opts.CACert = issuer.CACert
+
+	d, err := le.GenerateDigest(context.TODO(), DigestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ClusterUID != clusterUID || d.LicenseID == "" || d.Nodes != 1 {
+		t.Errorf("GenerateDigest() = %+v, want the license of cluster %s with 1 node", d, clusterUID)
+	}
+}
+
+func TestSendDigestOncePerInterval(t *testing.T) {
+	var digests []Digest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d Digest
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			t.Errorf("failed to decode license digest: %v", err)
+		}
+		digests = append(digests, d)
+	}))
+	defer srv.Close()
+
+	kc := fake.NewSimpleClientset(&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "cluster-1"}})
+	clk := clocktesting.NewFakeClock(time.Now().Truncate(time.Second))
+	opts := DigestOptions{
+		Interval:   24 * time.Hour,
+		WebhookURL: srv.URL,
+		ConfigMap:  &metav1.ObjectMeta{Namespace: "kube-system", Name: "license-digest"},
+		StateFile:  filepath.Join(t.TempDir(), "digest"),
+	}
+	newEnforcer := func() *LicenseEnforcer {
+		return &LicenseEnforcer{kc: kc, clock: clk, licenseData: []byte("invalid")}
+	}
+
+	le := newEnforcer()
+	if next := le.nextDigest(context.TODO(), opts); next != 0 {
+		t.Fatalf("nextDigest() before the first digest = %v, want 0", next)
+	}
+	if err := le.sendDigest(context.TODO(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(digests) != 1 || digests[0].ClusterUID != "cluster-1" {
+		t.Fatalf("sendDigest() delivered %+v, want one digest of cluster-1", digests)
+	}
+
+	// a restart within the interval waits for the rest of the interval
+	clk.Step(time.Hour)
+	if next := newEnforcer().nextDigest(context.TODO(), opts); next != 23*time.Hour {
+		t.Errorf("nextDigest() after a restart = %v, want %v", next, 23*time.Hour)
+	}
+	// the ConfigMap alone is enough to remember the delivery
+	stateless := opts
+	stateless.StateFile = ""
+	if next := newEnforcer().nextDigest(context.TODO(), stateless); next != 23*time.Hour {
+		t.Errorf("nextDigest() without a state file = %v, want %v", next, 23*time.Hour)
+	}
+	clk.Step(23 * time.Hour)
+	if next := newEnforcer().nextDigest(context.TODO(), opts); next != 0 {
+		t.Errorf("nextDigest() once the interval has passed = %v, want 0", next)
+	}
+}
+
+func TestSendDigestFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	kc := fake.NewSimpleClientset(&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "cluster-1"}})
+	le := &LicenseEnforcer{kc: kc, clock: clocktesting.NewFakeClock(time.Now()), licenseData: []byte("invalid")}
+	opts := DigestOptions{Interval: time.Hour, WebhookURL: srv.URL, StateFile: filepath.Join(t.TempDir(), "digest")}
+
+	if err := le.sendDigest(context.TODO(), opts); err == nil {
+		t.Fatal("sendDigest() error = nil, want the webhook failure")
+	}
+	if next := le.nextDigest(context.TODO(), opts); next != 0 {
+		t.Errorf("nextDigest() after a failed delivery = %v, want 0", next)
+	}
+}
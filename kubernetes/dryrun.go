@@ -0,0 +1,36 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"k8s.io/klog/v2"
+)
+
+// SetDryRun enables the dry-run mode, which is meant to stage a license rollout before enforcing it.
+// Licenses are verified and failures are logged and recorded as events and metrics as usual, but
+// nothing is enforced: the process is never terminated, the maintenance response is not served and
+// the license health checker always passes.
+// Dry-run can only be enabled by the product, never by the deployment, as it turns off enforcement.
+func (le *LicenseEnforcer) SetDryRun(enabled bool) {
+	le.dryRun = enabled
+}
+
+type dryRun struct{}
+
+func (dryRun) OnLicenseFailure(err error) {
+	klog.Warningf("License verification failed in dry-run mode, the license is not enforced. Reason: %v", err)
+}
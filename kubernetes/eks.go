@@ -0,0 +1,141 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.bytebuilders.dev/license-verifier/kubernetes/providers"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+const serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// eksIssuerPattern matches the OpenID Connect issuers AWS assigns to EKS clusters.
+var eksIssuerPattern = regexp.MustCompile(`^https://oidc\.eks\.[a-z0-9-]+\.amazonaws\.com(\.cn)?/id/[A-Za-z0-9]+$`)
+
+// EKSClusterID returns the OpenID Connect issuer of the EKS cluster, eg. https://oidc.eks.us-east-1.amazonaws.com/id/<id>.
+// AWS assigns the issuer when the cluster is created, so unlike the eks:cluster-name instance tag, users
+// can't set it. The issuer is read from the API server and proven by verifying the service account token
+// of the pod with the keys AWS publishes for the issuer, so that another cluster can't claim it.
+func EKSClusterID(kc kubernetes.Interface, hc *http.Client) ClusterIDProvider {
+	return ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+		issuer, err := providers.ServiceAccountIssuer(ctx, kc)
+		if err != nil {
+			return "", err
+		}
+		if !eksIssuerPattern.MatchString(issuer) {
+			return "", fmt.Errorf("service account issuer %s is not an EKS issuer", issuer)
+		}
+		token, err := os.ReadFile(serviceAccountTokenFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the service account token")
+		}
+		if err := verifyServiceAccountIssuer(ctx, hc, issuer, strings.TrimSpace(string(token))); err != nil {
+			return "", err
+		}
+		return issuer, nil
+	})
+}
+
+// verifyServiceAccountIssuer proves that the cluster holds the signing key of issuer, by verifying
+// a service account token of the cluster with the keys published by the issuer.
+func verifyServiceAccountIssuer(ctx context.Context, hc *http.Client, issuer, token string) error {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, hc, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return err
+	}
+	if discovery.Issuer != issuer {
+		return fmt.Errorf("issuer %s publishes the discovery document of %s", issuer, discovery.Issuer)
+	}
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, hc, discovery.JWKSURI, &jwks); err != nil {
+		return err
+	}
+
+	_, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, k := range jwks.Keys {
+			if k.Kty == "RSA" && k.Kid == kid {
+				return parseRSAPublicKey(k.N, k.E)
+			}
+		}
+		return nil, fmt.Errorf("issuer %s has no key %q", issuer, kid)
+	}, jwt.WithIssuer(issuer), jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil {
+		return errors.Wrapf(err, "service account token was not issued by %s", issuer)
+	}
+	return nil
+}
+
+func parseRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid RSA key modulus")
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid RSA key exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(new(big.Int).SetBytes(eb).Int64())}, nil
+}
+
+func getJSON(ctx context.Context, hc *http.Client, u string, v any) error {
+	if hc == nil {
+		hc = &http.Client{Timeout: metadataTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", u)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to read %s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", u)
+	}
+	return errors.Wrapf(json.Unmarshal(data, v), "failed to parse %s", u)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// EventReasonLicenseLimitApproaching is recorded when the usage of a resource reaches
+// limitWarningRatio of its limit in the license.
+const EventReasonLicenseLimitApproaching = "License Limit Approaching"
+
+const limitWarningRatio = 0.9
+
+// UsageCounter returns the current usage of a resource limited by the license.
+type UsageCounter func(ctx context.Context) (int64, error)
+
+// SetUsageCounter registers the counter for a resource limited by the license, eg. the
+// number of databases managed by a product. The nodes and cpu resources are counted by default.
+func (le *LicenseEnforcer) SetUsageCounter(resource string, fn UsageCounter) {
+	if le.usageCounters == nil {
+		le.usageCounters = map[string]UsageCounter{}
+	}
+	le.usageCounters[resource] = fn
+}
+
+func (le *LicenseEnforcer) usageCounter(resource string) UsageCounter {
+	if fn, ok := le.usageCounters[resource]; ok {
+		return fn
+	}
+	switch resource {
+	case v1alpha1.LimitNodes:
+		return le.countNodes
+	case v1alpha1.LimitCPU:
+		return le.countCPU
+	}
+	return nil
+}
+
+func (le *LicenseEnforcer) countNodes(ctx context.Context) (int64, error) {
+	nodes, err := le.kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list nodes")
+	}
+	var n int64
+	for _, node := range nodes.Items {
+		if le.countedNode(node) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// countCPU returns the allocatable cpu cores of all nodes, rounded up.
+func (le *LicenseEnforcer) countCPU(ctx context.Context) (int64, error) {
+	nodes, err := le.kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list nodes")
+	}
+	var milli int64
+	for _, node := range nodes.Items {
+		if le.countedNode(node) {
+			milli += node.Status.Allocatable.Cpu().MilliValue()
+		}
+	}
+	return (milli + 999) / 1000, nil
+}
+
+// usage counts the resources limited by the license. Resources without a counter are skipped.
+func (le *LicenseEnforcer) usage(ctx context.Context, license v1alpha1.License) (map[string]int64, error) {
+	usage := map[string]int64{}
+	for resource := range license.Limits {
+		fn := le.usageCounter(resource)
+		if fn == nil {
+			klog.V(4).Infof("License %s limits %s, which is not counted by this product", license.ID, resource)
+			continue
+		}
+		n, err := fn(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count %s", resource)
+		}
+		usage[resource] = n
+	}
+	return usage, nil
+}
+
+// checkLimits fails if the cluster uses more than the entitlements of the license,
+// and warns about resources whose usage approaches the limit.
+func (le *LicenseEnforcer) checkLimits(ctx context.Context, license v1alpha1.License) error {
+	if len(license.Limits) == 0 || le.kc == nil {
+		return nil
+	}
+	usage, err := le.usage(ctx, license)
+	if err != nil {
+		return err
+	}
+	capacity := map[string]int64{}
+	product := map[string]int64{}
+	for resource, used := range usage {
+		if capacityResource(resource) {
+			capacity[resource] = used
+		} else {
+			product[resource] = used
+		}
+	}
+	if err := verifier.CheckLimitsUsage(license, product); err != nil {
+		return err
+	}
+	if err := verifier.CheckLimitsUsage(license, capacity); err != nil {
+		if err := le.capacityExceeded(err); err != nil {
+			return err
+		}
+	}
+	for resource, used := range usage {
+		limit := license.Limits[resource]
+		// exceeded capacity is reported by capacityExceeded
+		if limit > 0 && used <= limit && float64(used) >= limitWarningRatio*float64(limit) {
+			le.warnLimitApproaching(resource, used, limit)
+		}
+	}
+	return nil
+}
+
+func (le *LicenseEnforcer) warnLimitApproaching(resource string, used, limit int64) {
+	msg := fmt.Sprintf("Using %d of %d %s permitted by the license", used, limit, resource)
+	klog.Warningln(msg)
+	err := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-limit-"+resource, core.EventTypeWarning, EventReasonLicenseLimitApproaching, msg)
+	})
+	if err != nil {
+		klog.Warningln(err)
+	}
+}
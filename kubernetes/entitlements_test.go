@@ -0,0 +1,69 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      CapacityEnforcementMode
+		databases int64
+		wantErr   bool
+	}{
+		{"enforce capacity", CapacityEnforce, 1, true},
+		{"warn capacity", CapacityWarn, 1, false},
+		{"warn capacity, product limit exceeded", CapacityWarn, 3, true},
+		{"enforce capacity, product limit exceeded", CapacityEnforce, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := fake.NewSimpleClientset(
+				&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+				&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+			)
+			le := &LicenseEnforcer{kc: kc}
+			le.SetCapacityEnforcement(&CapacityEnforcement{Mode: tt.mode})
+			le.SetUsageCounter("databases", func(ctx context.Context) (int64, error) {
+				return tt.databases, nil
+			})
+
+			license := v1alpha1.License{
+				ID:     "1",
+				Limits: map[string]int64{v1alpha1.LimitNodes: 1, "databases": 2},
+			}
+			err := le.checkLimits(context.TODO(), license)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, verifier.ErrLimitExceeded) {
+				t.Errorf("checkLimits() error = %v, want %v", err, verifier.ErrLimitExceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrorBudget tolerates sporadic verification failures, eg. a failed read of the license
+// file while a Secret is remounted. Enforcement only happens when the ratio of failed
+// attempts among the last Window attempts exceeds MaxFailureRatio.
+type ErrorBudget struct {
+	Window          int     `json:"window"`
+	MaxFailureRatio float64 `json:"maxFailureRatio"`
+}
+
+func (b ErrorBudget) Validate() error {
+	if b.Window <= 0 {
+		return fmt.Errorf("errorBudget.window must be positive, found %d", b.Window)
+	}
+	if b.MaxFailureRatio < 0 || b.MaxFailureRatio >= 1 {
+		return fmt.Errorf("errorBudget.maxFailureRatio must be in [0, 1), found %v", b.MaxFailureRatio)
+	}
+	return nil
+}
+
+// outcomeWindow is a ring buffer of the most recent verification outcomes.
+type outcomeWindow struct {
+	mu       sync.Mutex
+	failed   []bool
+	next     int
+	recorded int
+}
+
+func newOutcomeWindow(size int) *outcomeWindow {
+	return &outcomeWindow{failed: make([]bool, size)}
+}
+
+func (w *outcomeWindow) record(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failed[w.next] = err != nil
+	w.next = (w.next + 1) % len(w.failed)
+	if w.recorded < len(w.failed) {
+		w.recorded++
+	}
+}
+
+func (w *outcomeWindow) failureRatio() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.recorded == 0 {
+		return 0
+	}
+	n := 0
+	for i := 0; i < w.recorded; i++ {
+		if w.failed[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(w.recorded)
+}
+
+// SetErrorBudget makes the enforcer tolerate sporadic verification failures within the budget.
+func (le *LicenseEnforcer) SetErrorBudget(b *ErrorBudget) {
+	le.errorBudget = b
+	le.outcomes = nil
+	if b != nil {
+		le.outcomes = newOutcomeWindow(b.Window)
+	}
+}
+
+// withinErrorBudget records the outcome of a verification attempt and
+// returns true if a failure should be tolerated.
+func (le *LicenseEnforcer) withinErrorBudget(err error) bool {
+	if le.outcomes == nil {
+		return false
+	}
+	le.outcomes.record(err)
+	return err != nil && le.outcomes.failureRatio() <= le.errorBudget.MaxFailureRatio
+}
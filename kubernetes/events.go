@@ -0,0 +1,174 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// failureEventInterval is the minimum time between two events for the same verification failure
+	failureEventInterval  = 10 * time.Minute
+	defaultEventQueueSize = 16
+	eventWriteTimeout     = 10 * time.Second
+	eventFlushTimeout     = 15 * time.Second
+)
+
+// ErrEventQueueFull is returned when an event is dropped because too many events are waiting to be written.
+var ErrEventQueueFull = errors.New("license event queue is full")
+
+// EventEmitter writes events in the background, so that verification and enforcement
+// are not delayed by a slow api server.
+type EventEmitter struct {
+	queue chan func(ctx context.Context) error
+
+	mu      sync.Mutex
+	pending int
+	// idle is closed once all queued events are written
+	idle chan struct{}
+}
+
+// NewEventEmitter returns an EventEmitter that buffers up to size events.
+func NewEventEmitter(size int) *EventEmitter {
+	if size <= 0 {
+		size = defaultEventQueueSize
+	}
+	e := &EventEmitter{
+		queue: make(chan func(ctx context.Context) error, size),
+	}
+	go e.run()
+	return e
+}
+
+func (e *EventEmitter) run() {
+	for write := range e.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), eventWriteTimeout)
+		if err := write(ctx); err != nil {
+			klog.Errorln("Failed to record license event. Reason: ", err.Error())
+		}
+		cancel()
+		e.done()
+	}
+}
+
+// Emit queues write without waiting for it. The event is dropped if the queue is full.
+func (e *EventEmitter) Emit(write func(ctx context.Context) error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// count the event before it is queued, so that Flush never misses it
+	e.pending++
+	if e.pending == 1 {
+		e.idle = make(chan struct{})
+	}
+	select {
+	case e.queue <- write:
+		return nil
+	default:
+		e.doneLocked()
+		return ErrEventQueueFull
+	}
+}
+
+func (e *EventEmitter) done() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.doneLocked()
+}
+
+func (e *EventEmitter) doneLocked() {
+	e.pending--
+	if e.pending == 0 {
+		close(e.idle)
+	}
+}
+
+// Flush waits until all queued events are written or the timeout expires.
+func (e *EventEmitter) Flush(timeout time.Duration) error {
+	e.mu.Lock()
+	if e.pending == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	idle := e.idle
+	e.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for license events to be written")
+	}
+}
+
+func (le *LicenseEnforcer) eventEmitter() *EventEmitter {
+	le.eventsMu.Lock()
+	defer le.eventsMu.Unlock()
+	if le.events == nil {
+		le.events = NewEventEmitter(defaultEventQueueSize)
+	}
+	return le.events
+}
+
+// SetEventEmitter replaces the emitter used to record license events.
+func (le *LicenseEnforcer) SetEventEmitter(e *EventEmitter) {
+	le.eventsMu.Lock()
+	defer le.eventsMu.Unlock()
+	le.events = e
+}
+
+// FlushEvents waits until the queued license events are written or the timeout expires.
+// Call it before shutting down to not lose events.
+func (le *LicenseEnforcer) FlushEvents(timeout time.Duration) error {
+	return le.eventEmitter().Flush(timeout)
+}
+
+// eventDeduper collapses repeated identical events into a single event per interval.
+type eventDeduper struct {
+	mu         sync.Mutex
+	message    string
+	last       time.Time
+	suppressed int32
+}
+
+// next returns the message to record and the number of occurrences it stands for.
+// ok is false if the event is suppressed, because the same message was recorded less than interval ago.
+func (d *eventDeduper) next(message string, now time.Time, interval time.Duration, force bool) (msg string, count int32, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if message != d.message {
+		d.message, d.last, d.suppressed = message, now, 0
+		return message, 1, true
+	}
+	if !force && now.Sub(d.last) < interval {
+		d.suppressed++
+		return "", 0, false
+	}
+	msg, count = message, d.suppressed+1
+	if d.suppressed > 0 {
+		msg = fmt.Sprintf("%s (occurred %d times since %s)", message, count, d.last.UTC().Format(time.RFC3339))
+	}
+	d.last, d.suppressed = now, 0
+	return msg, count, true
+}
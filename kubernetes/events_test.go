@@ -0,0 +1,92 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventEmitterFlush(t *testing.T) {
+	e := NewEventEmitter(1)
+	if err := e.Flush(time.Second); err != nil {
+		t.Errorf("Flush() without events = %v, want nil", err)
+	}
+
+	release := make(chan struct{})
+	if err := e.Emit(func(ctx context.Context) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("Emit() = %v", err)
+	}
+	if err := e.Flush(10 * time.Millisecond); err == nil {
+		t.Errorf("Flush() with a blocked event = nil, want timeout")
+	}
+	close(release)
+	if err := e.Flush(time.Second); err != nil {
+		t.Errorf("Flush() after the event was written = %v, want nil", err)
+	}
+}
+
+func TestEventEmitterConcurrentEmitAndFlush(t *testing.T) {
+	e := NewEventEmitter(4)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := e.Emit(func(ctx context.Context) error { return nil })
+			if err != nil && !errors.Is(err, ErrEventQueueFull) {
+				t.Errorf("Emit() = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := e.Flush(time.Second); err != nil {
+				t.Errorf("Flush() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := e.Flush(time.Second); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+}
+
+func TestSetEventEmitter(t *testing.T) {
+	le := &LicenseEnforcer{}
+	e := NewEventEmitter(1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		le.SetEventEmitter(e)
+	}()
+	go func() {
+		defer wg.Done()
+		_ = le.FlushEvents(time.Second)
+	}()
+	wg.Wait()
+	le.SetEventEmitter(e)
+	if got := le.eventEmitter(); got != e {
+		t.Errorf("eventEmitter() = %p, want the emitter set using SetEventEmitter %p", got, e)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// EventReasonLicenseExpiring is recorded when the license has fewer days remaining than an expiry warning threshold.
+const EventReasonLicenseExpiring = "License Expiring"
+
+// DefaultExpiryWarningDays are the remaining days at which a warning is recorded before a license expires.
+var DefaultExpiryWarningDays = []int{30, 14, 7, 1}
+
+// SetExpiryWarnings sets the remaining days at which a warning is recorded before the license expires.
+// An empty list disables the warnings.
+func (le *LicenseEnforcer) SetExpiryWarnings(days []int) {
+	le.expiryWarningDays = append([]int(nil), days...)
+	sort.Ints(le.expiryWarningDays)
+}
+
+// warnExpiry records a warning once for every threshold the remaining lifetime of the license falls below.
+func (le *LicenseEnforcer) warnExpiry(license v1alpha1.License) {
+	if license.NotAfter == nil {
+		return
+	}
+	remaining := license.NotAfter.Sub(le.clock.Now())
+	threshold := 0
+	for _, days := range le.expiryWarningDays {
+		if remaining < time.Duration(days)*24*time.Hour {
+			threshold = days
+			break
+		}
+	}
+	if threshold == 0 {
+		return
+	}
+	if last, ok := le.expiryWarned[license.ID]; ok && last <= threshold {
+		// already warned for this threshold
+		return
+	}
+	if le.expiryWarned == nil {
+		le.expiryWarned = map[string]int{}
+	}
+	le.expiryWarned[license.ID] = threshold
+
+	msg := fmt.Sprintf("License %s expires in less than %d day(s) at %s", license.ID, threshold, license.NotAfter.UTC().Format(time.RFC3339))
+	klog.Warningln(msg)
+	err := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-expiring", core.EventTypeWarning, EventReasonLicenseExpiring, msg)
+	})
+	if err != nil {
+		klog.Warningln(err)
+	}
+}
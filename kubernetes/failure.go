@@ -0,0 +1,154 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// FailurePolicy decides what happens to the process when license verification fails.
+type FailurePolicy string
+
+const (
+	// FailurePolicyCrashPod terminates the process, so that the pod is restarted. This is the default.
+	FailurePolicyCrashPod FailurePolicy = "CrashPod"
+	// FailurePolicyLogOnly only logs the failure and records an event.
+	FailurePolicyLogOnly FailurePolicy = "LogOnly"
+	// FailurePolicyCallback calls a handler registered using SetFailureHandler.
+	FailurePolicyCallback FailurePolicy = "Callback"
+)
+
+// FailureHandler is called after a license verification failure has been logged and recorded as an event.
+type FailureHandler interface {
+	OnLicenseFailure(err error)
+}
+
+// FailureHandlerFunc adapts a function to a FailureHandler.
+type FailureHandlerFunc func(err error)
+
+func (f FailureHandlerFunc) OnLicenseFailure(err error) {
+	f(err)
+}
+
+type crashPod struct{}
+
+// CrashPod returns a FailureHandler that terminates the process.
+func CrashPod() FailureHandler {
+	return crashPod{}
+}
+
+func (crashPod) OnLicenseFailure(error) {
+	// Send interrupt so that all go-routines shut-down gracefully
+	// https://pracucci.com/graceful-shutdown-of-kubernetes-pods.html
+	// https://linuxhandbook.com/sigterm-vs-sigkill/
+	//
+	// Need to send signal twice because
+	// we catch the first INT/TERM signal
+	// ref: https://github.com/kubernetes/apiserver/blob/8d97c871d91c75b81b8b4c438f4dd1eaa7f35052/pkg/server/signal.go#L47-L51
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	time.Sleep(30 * time.Second)
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGKILL)
+}
+
+// LogOnly returns a FailureHandler that keeps the process running.
+func LogOnly() FailureHandler {
+	return FailureHandlerFunc(func(error) {})
+}
+
+// Callback returns a FailureHandler that calls fn, eg. to disable licensed features
+// instead of shutting down.
+func Callback(fn func(err error)) FailureHandler {
+	return FailureHandlerFunc(fn)
+}
+
+// SetFailureHandler sets the handler called when license verification fails. Defaults to CrashPod.
+func (le *LicenseEnforcer) SetFailureHandler(h FailureHandler) {
+	le.failureHandler = h
+}
+
+// SetFailurePolicy sets one of the builtin failure handlers.
+// FailurePolicyCallback requires a handler set using SetFailureHandler.
+func (le *LicenseEnforcer) SetFailurePolicy(policy FailurePolicy) error {
+	switch policy {
+	case FailurePolicyCrashPod, "":
+		le.failureHandler = CrashPod()
+	case FailurePolicyLogOnly:
+		le.failureHandler = LogOnly()
+	case FailurePolicyCallback:
+		if le.failureHandler == nil {
+			return fmt.Errorf("failure policy %s requires a failure handler", policy)
+		}
+	default:
+		return fmt.Errorf("unknown failure policy %q", policy)
+	}
+	return nil
+}
+
+// SetTrialFailureHandler sets the handler called when a trial license has expired, eg. to
+// switch the product to community mode instead of shutting down. Defaults to the failure handler.
+func (le *LicenseEnforcer) SetTrialFailureHandler(h FailureHandler) {
+	le.trialFailureHandler = h
+}
+
+// SetTrialFailurePolicy sets one of the builtin failure handlers for expired trial licenses.
+// FailurePolicyCallback requires a handler set using SetTrialFailureHandler.
+func (le *LicenseEnforcer) SetTrialFailurePolicy(policy FailurePolicy) error {
+	switch policy {
+	case "":
+		le.trialFailureHandler = nil
+	case FailurePolicyCrashPod:
+		le.trialFailureHandler = CrashPod()
+	case FailurePolicyLogOnly:
+		le.trialFailureHandler = LogOnly()
+	case FailurePolicyCallback:
+		if le.trialFailureHandler == nil {
+			return fmt.Errorf("trial failure policy %s requires a trial failure handler", policy)
+		}
+	default:
+		return fmt.Errorf("unknown trial failure policy %q", policy)
+	}
+	return nil
+}
+
+// failureHandlerFor returns the handler for the verification failure.
+func (le *LicenseEnforcer) failureHandlerFor(err error) FailureHandler {
+	if le.dryRun {
+		return dryRun{}
+	}
+	if le.trialFailureHandler != nil && errors.Is(err, verifier.ErrTrialExpired) {
+		return le.trialFailureHandler
+	}
+	if le.failureHandler == nil {
+		return CrashPod()
+	}
+	return le.failureHandler
+}
+
+// crashOnFailure returns true if the verification failure terminates the process.
+func (le *LicenseEnforcer) crashOnFailure(err error) bool {
+	_, crash := le.failureHandlerFor(err).(crashPod)
+	return crash
+}
+
+func (le *LicenseEnforcer) onLicenseFailure(err error) {
+	le.failureHandlerFor(err).OnLicenseFailure(err)
+}
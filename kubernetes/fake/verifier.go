@@ -0,0 +1,101 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/kubernetes"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Verifier is a kubernetes.Verifier that returns a configurable result, so that license gated
+// code paths can be unit tested without certificates or clusters.
+type Verifier struct {
+	mu      sync.Mutex
+	license v1alpha1.License
+	err     error
+	calls   int
+}
+
+var _ kubernetes.Verifier = &Verifier{}
+
+// NewVerifier returns a Verifier that returns license and err.
+func NewVerifier(license v1alpha1.License, err error) *Verifier {
+	return &Verifier{license: license, err: err}
+}
+
+// Valid returns a Verifier that returns an active license for the features, valid for a year.
+func Valid(features ...string) *Verifier {
+	return NewVerifier(ValidLicense(features...), nil)
+}
+
+// Invalid returns a Verifier that rejects the license with err.
+func Invalid(err error) *Verifier {
+	license, _ := verifier.BadLicense(err)
+	license.Status = v1alpha1.LicenseInvalid
+	return NewVerifier(license, err)
+}
+
+// ValidLicense returns an active license for the features, valid for a year.
+func ValidLicense(features ...string) v1alpha1.License {
+	now := time.Now()
+	return v1alpha1.License{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "License",
+		},
+		Issuer:    "fake",
+		PlanName:  "fake",
+		Features:  features,
+		NotBefore: &metav1.Time{Time: now},
+		NotAfter:  &metav1.Time{Time: now.AddDate(1, 0, 0)},
+		ID:        "fake",
+		Status:    v1alpha1.LicenseActive,
+	}
+}
+
+// Set changes the result returned by subsequent calls to Verify.
+func (v *Verifier) Set(license v1alpha1.License, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.license, v.err = license, err
+}
+
+// Verify returns the status of the configured result.
+func (v *Verifier) Verify(ctx context.Context) (*verifier.LicenseStatus, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls++
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	status := verifier.NewLicenseStatus(*v.license.DeepCopy(), v.err, time.Now())
+	return &status, v.err
+}
+
+// Calls returns the number of times Verify was called.
+func (v *Verifier) Calls() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.calls
+}
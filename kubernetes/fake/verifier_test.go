@@ -0,0 +1,51 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/kubernetes/fake"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+func TestVerifier(t *testing.T) {
+	v := fake.Valid("kubedb-enterprise")
+	status, err := v.Verify(context.TODO())
+	if err != nil || !meta.IsStatusConditionTrue(status.Conditions, verifier.ConditionValid) {
+		t.Fatalf("Verify() = %+v, %v, want a valid license", status, err)
+	}
+	if !meta.IsStatusConditionFalse(status.Conditions, verifier.ConditionExpired) {
+		t.Errorf("Verify() conditions = %+v, want the license not expired", status.Conditions)
+	}
+
+	v = fake.Invalid(errors.Wrap(verifier.ErrLicenseExpired, "expired yesterday"))
+	status, err = v.Verify(context.TODO())
+	if !errors.Is(err, verifier.ErrLicenseExpired) {
+		t.Fatalf("Verify() error = %v, want %v", err, verifier.ErrLicenseExpired)
+	}
+	if !meta.IsStatusConditionFalse(status.Conditions, verifier.ConditionValid) || !meta.IsStatusConditionTrue(status.Conditions, verifier.ConditionExpired) {
+		t.Errorf("Verify() conditions = %+v, want an invalid, expired license", status.Conditions)
+	}
+	if v.Calls() != 1 {
+		t.Errorf("Calls() = %d, want 1", v.Calls())
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// VerifyLicenseForFeatures verifies that the license is valid and was issued for all the features,
+// so that a product can gate individual features on the same license.
+func (le *LicenseEnforcer) VerifyLicenseForFeatures(features ...string) error {
+	return le.VerifyLicenseForFeaturesWithContext(context.TODO(), features...)
+}
+
+// VerifyLicenseForFeaturesWithContext is like VerifyLicenseForFeatures but uses ctx for all api calls.
+func (le *LicenseEnforcer) VerifyLicenseForFeaturesWithContext(ctx context.Context, features ...string) error {
+	license, err := le.verifyOnce(ctx)
+	if err != nil {
+		return err
+	}
+
+	license.Features = le.opts.FeatureAliases.Resolve(license.Features)
+	var missing []string
+	for _, f := range features {
+		if !verifier.HasFeature(license, f) {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: license %s does not cover %s", verifier.ErrProductMismatch, license.ID, strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// SetFeatureAliases makes the enforcer accept licenses issued for features that imply the product features.
+func (le *LicenseEnforcer) SetFeatureAliases(aliases verifier.FeatureAliases) {
+	le.opts.FeatureAliases = aliases
+}
+
+// SetNamespaces sets the namespaces the product manages, which must be covered by a license restricted
+// to namespaces. Defaults to the namespace of the pod.
+func (le *LicenseEnforcer) SetNamespaces(namespaces ...string) {
+	le.opts.Namespaces = namespaces
+}
+
+// SetChecks sets the checks run by every license verification. Defaults to verifier.DefaultPipeline.
+func (le *LicenseEnforcer) SetChecks(checks verifier.Pipeline) {
+	le.opts.Checks = checks
+}
+
+// SetProductVersion sets the product version checked against the version constraint of the license.
+// Defaults to info.ProductVersion.
+func (le *LicenseEnforcer) SetProductVersion(version string) {
+	le.opts.ProductVersion = version
+}
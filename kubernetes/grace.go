@@ -0,0 +1,44 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// SetGracePeriod keeps accepting an expired license for the given duration.
+// During the grace period, a warning event is recorded on every verification.
+func (le *LicenseEnforcer) SetGracePeriod(d time.Duration) {
+	le.opts.GracePeriod = d
+}
+
+func (le *LicenseEnforcer) warnGracePeriod(license v1alpha1.License) {
+	klog.Warningf("License %s is in its grace period: %s", license.ID, license.Reason)
+	err := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-grace", core.EventTypeWarning, EventReasonLicenseGracePeriod,
+			"License is in its grace period. Reason: "+license.Reason)
+	})
+	if err != nil {
+		klog.Warningln(err)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcauth gates the premium gRPC services of a product on its license.
+// It is a separate package, so that products without gRPC don't depend on it.
+package grpcauth
+
+import (
+	"context"
+
+	"go.bytebuilders.dev/license-verifier/kubernetes"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor that rejects calls with PermissionDenied
+// unless the last verified license is valid and includes all the given features.
+// Register it only for services that serve premium endpoints.
+func UnaryServerInterceptor(le *kubernetes.LicenseEnforcer, features ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := le.CheckRequest(features...); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is like UnaryServerInterceptor for streaming calls.
+func StreamServerInterceptor(le *kubernetes.LicenseEnforcer, features ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := le.CheckRequest(features...); err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return handler(srv, ss)
+	}
+}
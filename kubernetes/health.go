@@ -0,0 +1,47 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// LicenseHealthChecker returns a healthz.Checker that fails until the license has been verified
+// and whenever the effective verification result is a failure, see EffectiveVerification. It can be added to the /readyz endpoint of a product
+// to stop serving traffic instead of crashing the pod when the license is invalid.
+// The checker always passes in dry-run mode.
+func (le *LicenseEnforcer) LicenseHealthChecker() healthz.Checker {
+	return func(_ *http.Request) error {
+		return le.licenseError()
+	}
+}
+
+// licenseError returns why the product must not operate, or nil if the license is valid
+// or the enforcer runs in dry-run mode.
+func (le *LicenseEnforcer) licenseError() error {
+	if le.dryRun {
+		return nil
+	}
+	result, ok := le.EffectiveVerification()
+	if !ok {
+		return errors.New("license has not been verified yet")
+	}
+	return result.Err
+}
@@ -0,0 +1,57 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestEffectiveVerification(t *testing.T) {
+	now := time.Now()
+	le := &LicenseEnforcer{clock: clocktesting.NewFakeClock(now)}
+	if err := le.licenseError(); err == nil {
+		t.Errorf("licenseError() before verification = nil, want error")
+	}
+
+	license := testLicense("a", now.AddDate(1, 0, 0))
+	le.actOn(le.recordVerification(license, nil, now))
+	if err := le.licenseError(); err != nil {
+		t.Errorf("licenseError() after a valid verification = %v, want nil", err)
+	}
+
+	// a failure tolerated by the failure policies is recorded, but not acted on
+	errTransient := errors.New("issuer unavailable")
+	le.recordVerification(license, errTransient, now)
+	if result, _ := le.LastVerification(); !errors.Is(result.Err, errTransient) {
+		t.Errorf("LastVerification() error = %v, want %v", result.Err, errTransient)
+	}
+	if err := le.licenseError(); err != nil {
+		t.Errorf("licenseError() after a tolerated failure = %v, want nil", err)
+	}
+	if err := le.CheckRequest(); err != nil {
+		t.Errorf("CheckRequest() after a tolerated failure = %v, want nil", err)
+	}
+
+	le.actOn(le.recordVerification(license, errTransient, now))
+	if err := le.licenseError(); !errors.Is(err, errTransient) {
+		t.Errorf("licenseError() after a failure = %v, want %v", err, errTransient)
+	}
+}
@@ -18,6 +18,7 @@ package kubernetes
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,7 +26,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
@@ -47,26 +48,85 @@ import (
 	"k8s.io/client-go/tools/reference"
 	"k8s.io/klog/v2"
 	"k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
+	"k8s.io/utils/clock"
 	core_util "kmodules.xyz/client-go/core/v1"
 	"kmodules.xyz/client-go/discovery"
 	"kmodules.xyz/client-go/dynamic"
 	"kmodules.xyz/client-go/meta"
-	"kmodules.xyz/client-go/tools/clusterid"
 )
 
 const (
-	EventSourceLicenseVerifier           = "License Verifier"
-	EventReasonLicenseVerificationFailed = "License Verification Failed"
+	EventSourceLicenseVerifier              = "License Verifier"
+	EventReasonLicenseVerificationFailed    = "License Verification Failed"
+	EventReasonLicenseGracePeriod           = "License Grace Period"
+	EventReasonLicenseRemoved               = "License Removed"
+	EventReasonLicenseFormat                = "License Format"
+	EventReasonLicenseVerificationSucceeded = "License Verification Succeeded"
 
 	licensePath          = "/appscode/license"
+	licenseVersionPath   = licensePath + "/version"
 	licenseCheckInterval = 1 * time.Hour
 )
 
 type LicenseEnforcer struct {
-	licenseFile string
-	opts        verifier.VerifyOptions
-	config      *rest.Config
-	kc          kubernetes.Interface
+	licenseFile     string
+	opts            verifier.VerifyOptions
+	config          *rest.Config
+	kc              kubernetes.Interface
+	bundledCAs      []*x509.Certificate
+	caBundle        *CABundleSource
+	revocation      *RevocationListSource
+	crl             revocationListCache
+	ocsp            *OCSPConfig
+	ocspResponses   ocspCache
+	usageReporting  UsageReporting
+	clock           clock.Clock
+	checkInterval   time.Duration
+	jitter          float64
+	verifyOnStartup bool
+	maintenance     *MaintenanceHandler
+	issuer          *IssuerConfig
+	lastLicenseID   string
+	contract        *v1alpha1.Contract
+	// contractLicenseID is the ID of the license acquired with contract, empty until it is verified
+	contractLicenseID string
+	sources           []LicenseSource
+	errorBudget       *ErrorBudget
+	outcomes          *outcomeWindow
+	damper            *statusDamper
+	usageCounters     map[string]UsageCounter
+	capacity          *CapacityEnforcement
+	// expiryWarningDays are sorted in ascending order
+	expiryWarningDays []int
+	expiryWarned      map[string]int
+	failureHandler    FailureHandler
+	events            *EventEmitter
+	eventsMu          sync.Mutex
+	failureEvents     eventDeduper
+	state             verificationState
+	statusConfigMap   *StatusConfigMap
+	metrics           *Metrics
+	callbacks         licenseCallbacks
+	clusterUIDOpts    ClusterUIDOptions
+	// clusterIDProvider overrides clusterUIDOpts
+	clusterIDProvider ClusterIDProvider
+	// watchLicenseFile re-verifies the license as soon as the license file changes
+	watchLicenseFile bool
+	licenseSecret    *LicenseSecretReference
+	licenseData      []byte
+	removalPolicy    LicenseRemovalPolicy
+	removalGrace     time.Duration
+	// trialFailureHandler overrides failureHandler for expired trial licenses
+	trialFailureHandler FailureHandler
+	// licenseStacking stacks add-on licenses on the base license
+	licenseStacking bool
+	failureStreak   *failureStreak
+	dryRun          bool
+	coordination    *Coordination
+	leader          leaderState
+	sharedLicense   *SharedLicense
+	// verifyMu serializes license verifications, because they share the verification options
+	verifyMu sync.Mutex
 }
 
 // NewLicenseEnforcer returns a newly created license enforcer
@@ -75,21 +135,51 @@ func NewLicenseEnforcer(config *rest.Config, licenseFile string) (*LicenseEnforc
 		config:      config,
 		licenseFile: licenseFile,
 		opts: verifier.VerifyOptions{
-			Features: info.ProductName,
+			Features:       info.ProductName,
+			ProductVersion: info.ProductVersion,
 		},
+		clock:           clock.RealClock{},
+		checkInterval:   licenseCheckInterval,
+		verifyOnStartup: true,
+	}
+	le.SetExpiryWarnings(DefaultExpiryWarningDays)
+	le.SetFailureThreshold(nil)
+	le.opts.Clock = le.clock
+	le.opts.ClockSkew = verifier.DefaultClockSkew
+	// skip decoding the unchanged license on every periodic check
+	le.opts.Cache = verifier.NewLicenseCache()
+	if config != nil {
+		le.opts.Namespaces = []string{meta.PodNamespace()}
+	}
+	if licenseFile == "" {
+		le.licenseData = licenseFromEnv()
 	}
 
 	caData, err := info.LoadLicenseCA()
 	if err != nil {
 		return &le, err
 	}
-	le.opts.CACert, err = info.ParseCertificate(caData)
+	// the license CA may be a bundle of CAs, eg. the old and the new CA during a CA rotation
+	caCerts, err := info.ParseCertificates(caData)
 	if err != nil {
 		return &le, err
 	}
+	le.opts.CACert, le.bundledCAs = caCerts[0], caCerts[1:]
+	le.opts.CACerts = le.bundledCAs
 	return &le, nil
 }
 
+// SetClock replaces the clock used to evaluate license validity. This is meant for tests.
+func (le *LicenseEnforcer) SetClock(c clock.Clock) {
+	le.clock = c
+	le.opts.Clock = c
+}
+
+// SetClockSkew sets the tolerated skew of the node clock when checking the validity period of the license.
+func (le *LicenseEnforcer) SetClockSkew(d time.Duration) {
+	le.opts.ClockSkew = d
+}
+
 func MustLicenseEnforcer(config *rest.Config, licenseFile string) *LicenseEnforcer {
 	le, err := NewLicenseEnforcer(config, licenseFile)
 	if err != nil {
@@ -98,9 +188,22 @@ func MustLicenseEnforcer(config *rest.Config, licenseFile string) *LicenseEnforc
 	return le
 }
 
-func (le *LicenseEnforcer) getLicense() ([]byte, error) {
-	licenseBytes, err := os.ReadFile(le.licenseFile)
+func (le *LicenseEnforcer) getLicense(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	licenseBytes, err := le.readLicenseFile()
+	if le.standalone() && err != nil {
+		return nil, errors.Wrap(err, "failed to read license")
+	}
 	if errors.Is(err, os.ErrNotExist) || (err == nil && le.invalidLicense(licenseBytes)) {
+		if le.sharedLicense != nil {
+			if data, err := le.readSharedLicense(ctx); err == nil {
+				return data, nil
+			} else {
+				klog.V(4).Infof("No shared license found. Reason: %v", err)
+			}
+		}
 		req := proxyserver.LicenseRequest{
 			TypeMeta: metav1.TypeMeta{},
 			Request: &proxyserver.LicenseRequestRequest{
@@ -111,7 +214,7 @@ func (le *LicenseEnforcer) getLicense() ([]byte, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "failed create client for license-proxyserver")
 		}
-		resp, err := pc.ProxyserverV1alpha1().LicenseRequests().Create(context.TODO(), &req, metav1.CreateOptions{})
+		resp, err := pc.ProxyserverV1alpha1().LicenseRequests().Create(ctx, &req, metav1.CreateOptions{})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to read license")
 		}
@@ -129,53 +232,192 @@ func (le *LicenseEnforcer) invalidLicense(license []byte) bool {
 	// We want to acquire license-proxyserver is a previously valid license has not expired.
 	// So, we don't check features in the license found is license file.
 	l, err := verifier.ParseLicense(le.opts.ParserOptions)
-	return sets.NewString(l.Features...).HasAny(info.ParseFeatures(le.opts.Features)...) && err != nil
+	return sets.NewString(le.opts.FeatureAliases.Resolve(l.Features)...).HasAny(info.ParseFeatures(le.opts.Features)...) && err != nil
+}
+
+// SetKubernetesClient makes the enforcer use an existing client, and thus its rate limits,
+// instead of creating one from the rest config.
+func (le *LicenseEnforcer) SetKubernetesClient(kc kubernetes.Interface) {
+	le.kc = kc
+}
+
+// checkRevoked makes sure that a license verified offline has not been revoked, according to the
+// revocation list, the OCSP responder and the license issuer.
+func (le *LicenseEnforcer) checkRevoked(ctx context.Context, license *v1alpha1.License, cas []*x509.Certificate) error {
+	if err := le.checkRevocation(ctx, license, cas); err != nil {
+		return err
+	}
+	if err := le.checkOCSP(ctx, license, cas); err != nil {
+		return err
+	}
+	return le.checkOnline(license)
 }
 
 func (le *LicenseEnforcer) createClients() (err error) {
-	if le.kc == nil {
+	if le.kc == nil && !le.standalone() {
 		le.kc, err = kubernetes.NewForConfig(le.config)
 	}
 	return err
 }
 
-func (le *LicenseEnforcer) acquireLicense() (err error) {
-	le.opts.License, err = le.getLicense()
+func (le *LicenseEnforcer) acquireLicense(ctx context.Context) (err error) {
+	if len(le.sources) > 0 {
+		r, err := le.ResolveLicense(ctx, le.sources...)
+		if err != nil {
+			return err
+		}
+		le.opts.License = r.Data
+		return nil
+	}
+	if le.licenseSecret != nil {
+		le.opts.License, err = le.licenseSecret.source(le.kc).Load(ctx)
+		return err
+	}
+	if len(le.licenseData) > 0 {
+		le.opts.License = le.licenseData
+		return nil
+	}
+	le.opts.License, err = le.getLicense(ctx)
 	return err
 }
 
-func (le *LicenseEnforcer) readClusterUID() (err error) {
+// verify checks the acquired license against the cluster and product and
+// then makes sure it has not been revoked.
+// Licenses bound to a cluster CA are also checked against the CA of the API server.
+func (le *LicenseEnforcer) verify(ctx context.Context) (v1alpha1.License, error) {
+	if err := le.readClusterCAFingerprint(); err != nil {
+		return verifier.BadLicense(err)
+	}
+	// the trusted CAs are local to this verification, so that the shared options are never mutated
+	opts := le.opts
+	opts.CACerts = le.loadCABundle(ctx)
+	license, err := le.checkLicense(opts)
+	if err != nil && le.canReacquire(err) {
+		license, err = le.reacquire(ctx, opts)
+	}
+	if err != nil {
+		return license, err
+	}
+	if err := le.checkRevoked(ctx, &license, opts.TrustedCAs()); err != nil {
+		return license, err
+	}
+	if err := le.checkLimits(ctx, license); err != nil {
+		license.Status = v1alpha1.LicenseInvalid
+		license.Reason = err.Error()
+		return license, err
+	}
+	license.Contract = le.contractFor(license)
+	return license, nil
+}
+
+// setContract records the contract returned by the issuer together with a newly acquired license.
+func (le *LicenseEnforcer) setContract(contract *v1alpha1.Contract) {
+	le.contract = contract
+	le.contractLicenseID = ""
+}
+
+// contractFor returns the contract of license. The contract is bound to the first license verified
+// after it was acquired and is dropped once that license is replaced, eg. by editing the license file.
+func (le *LicenseEnforcer) contractFor(license v1alpha1.License) *v1alpha1.Contract {
+	if le.contract == nil {
+		return nil
+	}
+	if le.contractLicenseID == "" {
+		le.contractLicenseID = license.ID
+	} else if le.contractLicenseID != license.ID {
+		le.setContract(nil)
+	}
+	return le.contract
+}
+
+// readClusterUID reads the cluster UID, which is the UID of the "kube-system" namespace
+// unless a fallback is configured.
+func (le *LicenseEnforcer) readClusterUID(ctx context.Context) error {
 	if le.opts.ClusterUID != "" {
-		return
+		return nil
 	}
-	le.opts.ClusterUID, err = clusterid.ClusterUID(le.kc.CoreV1().Namespaces())
-	return err
+	p := le.clusterIDProvider
+	if p == nil {
+		var err error
+		if p, err = NewClusterIDProvider(le.config, le.kc, le.clusterUIDOpts); err != nil {
+			return err
+		}
+	}
+	uid, err := p.ClusterID(ctx)
+	if err != nil {
+		return err
+	}
+	le.opts.ClusterUID = uid
+	return nil
 }
 
 func (le *LicenseEnforcer) handleLicenseVerificationFailure(licenseErr error) error {
-	// Send interrupt so that all go-routines shut-down gracefully
-	// https://pracucci.com/graceful-shutdown-of-kubernetes-pods.html
-	// https://linuxhandbook.com/sigterm-vs-sigkill/
-	// https://pracucci.com/graceful-shutdown-of-kubernetes-pods.html
-	//nolint:errcheck
-	defer func() {
-		// Need to send signal twice because
-		// we catch the first INT/TERM signal
-		// ref: https://github.com/kubernetes/apiserver/blob/8d97c871d91c75b81b8b4c438f4dd1eaa7f35052/pkg/server/signal.go#L47-L51
-		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
-		time.Sleep(30 * time.Second)
-		_ = syscall.Kill(syscall.Getpid(), syscall.SIGKILL)
-	}()
-
 	// Log licenseInfo verification failure
 	klog.Errorln("Failed to verify license. Reason: ", licenseErr.Error())
 
+	// Repeated identical failures are recorded at most once per failureEventInterval,
+	// unless the process is about to exit.
+	var err error
+	msg, count, ok := le.failureEvents.next(fmt.Sprintf("Failed to verify license. Reason: %s", licenseErr.Error()),
+		le.clock.Now(), failureEventInterval, le.crashOnFailure(licenseErr))
+	if ok {
+		// Record the event in the background, so that a slow api server does not delay enforcement
+		err = le.eventEmitter().Emit(func(ctx context.Context) error {
+			return le.recordEventN(ctx, "license", core.EventTypeWarning, EventReasonLicenseVerificationFailed, msg, count)
+		})
+	}
+	if le.crashOnFailure(licenseErr) {
+		// the process is about to exit, so give the event a bounded amount of time to be written
+		if e2 := le.FlushEvents(eventFlushTimeout); e2 != nil {
+			klog.Warningln(e2)
+		}
+		// tell post-mortem readers that the pod was terminated because of the license
+		le.markPodLicenseInvalid(licenseErr)
+	}
+
+	// Let the configured failure handler decide the fate of the process
+	le.onLicenseFailure(licenseErr)
+	return err
+}
+
+// recordLicenseVerified records a Normal event for a newly verified license, so that
+// cluster admins can audit which licenses are in use.
+func (le *LicenseEnforcer) recordLicenseVerified(license v1alpha1.License) {
+	msg := fmt.Sprintf("Verified license %s for plan %s", license.ID, license.PlanName)
+	if license.NotAfter != nil {
+		msg += fmt.Sprintf(", valid until %s", license.NotAfter.UTC().Format(time.RFC3339))
+	}
+	err := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-verified", core.EventTypeNormal, EventReasonLicenseVerificationSucceeded, msg)
+	})
+	if err != nil {
+		klog.Warningln(err)
+	}
+}
+
+// recordEvent creates or updates the event with the given name suffix against the root owner of this pod.
+func (le *LicenseEnforcer) recordEvent(ctx context.Context, suffix, eventType, reason, message string) error {
+	return le.recordEventN(ctx, suffix, eventType, reason, message, 1)
+}
+
+// recordEventN is like recordEvent but adds count occurrences to the event.
+func (le *LicenseEnforcer) recordEventN(ctx context.Context, suffix, eventType, reason, message string, count int32) error {
+	if !le.recordsEvent(eventType) {
+		klog.V(4).Infof("%s: %s", reason, message)
+		return nil
+	}
+	if le.config == nil {
+		// the owner of this pod can't be detected without a rest config
+		klog.V(4).Infof("%s: %s", reason, message)
+		return nil
+	}
+
 	// Read the namespace of current pod
 	namespace := meta.PodNamespace()
 
 	// Find the root owner of this pod
 	owner, _, err := dynamic.DetectWorkload(
-		context.TODO(),
+		ctx,
 		le.config,
 		core.SchemeGroupVersion.WithResource(core.ResourcePods.String()),
 		namespace,
@@ -189,28 +431,34 @@ func (le *LicenseEnforcer) handleLicenseVerificationFailure(licenseErr error) er
 		return err
 	}
 	eventMeta := metav1.ObjectMeta{
-		Name:      meta.NameWithSuffix(owner.GetName(), "license"),
+		Name:      meta.NameWithSuffix(owner.GetName(), suffix),
 		Namespace: namespace,
 	}
-	// Create an event against the root owner specifying that the license verification failed
-	_, _, err = core_util.CreateOrPatchEvent(context.TODO(), le.kc, eventMeta, func(in *core.Event) *core.Event {
+	// Create an event against the root owner
+	_, _, err = core_util.CreateOrPatchEvent(ctx, le.kc, eventMeta, func(in *core.Event) *core.Event {
 		in.InvolvedObject = *ref
-		in.Type = core.EventTypeWarning
+		in.Type = eventType
 		in.Source = core.EventSource{Component: EventSourceLicenseVerifier}
-		in.Reason = EventReasonLicenseVerificationFailed
-		in.Message = fmt.Sprintf("Failed to verify license. Reason: %s", licenseErr.Error())
+		in.Reason = reason
+		in.Message = message
 
 		if in.FirstTimestamp.IsZero() {
 			in.FirstTimestamp = metav1.Now()
 		}
 		in.LastTimestamp = metav1.Now()
-		in.Count = in.Count + 1
+		in.Count = in.Count + count
 
 		return in
 	}, metav1.PatchOptions{})
 	return err
 }
 
+// recordsEvent returns true if this replica records events of eventType. Every replica records
+// its own warnings, eg. failures, but only the leader records the events shared by all replicas.
+func (le *LicenseEnforcer) recordsEvent(eventType string) bool {
+	return eventType == core.EventTypeWarning || le.IsLeader()
+}
+
 // Install adds the License info handler
 func (le *LicenseEnforcer) Install(c *mux.PathRecorderMux) {
 	// Create Kubernetes client
@@ -223,32 +471,63 @@ func (le *LicenseEnforcer) Install(c *mux.PathRecorderMux) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("x-content-type-options", "nosniff")
 
-		license, _ := le.LoadLicense()
+		license, _ := le.LoadLicenseWithContext(r.Context())
 		utilruntime.Must(json.NewEncoder(w).Encode(license))
 	}))
+	c.Handle(licenseVersionPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-content-type-options", "nosniff")
+
+		utilruntime.Must(json.NewEncoder(w).Encode(info.Version()))
+	}))
 }
 
 func (le *LicenseEnforcer) LoadLicense() (v1alpha1.License, []byte) {
+	return le.LoadLicenseWithContext(context.TODO())
+}
+
+// LoadLicenseWithContext reads and verifies the license using ctx for all api calls.
+func (le *LicenseEnforcer) LoadLicenseWithContext(ctx context.Context) (v1alpha1.License, []byte) {
+	license, data, _ := le.loadLicense(ctx)
+	return license, data
+}
+
+// loadLicense is like LoadLicenseWithContext but also returns the verification error.
+func (le *LicenseEnforcer) loadLicense(ctx context.Context) (v1alpha1.License, []byte, error) {
 	utilruntime.Must(le.createClients())
+	return le.verifyLicense(ctx)
+}
+
+// verifyLicense reads the cluster UID and the license and verifies the license. Concurrent
+// verifications, eg. by the periodic verification and api requests, are serialized.
+func (le *LicenseEnforcer) verifyLicense(ctx context.Context) (v1alpha1.License, []byte, error) {
+	le.verifyMu.Lock()
+	defer le.verifyMu.Unlock()
 
 	// Read cluster UID (UID of the "kube-system" namespace)
-	err := le.readClusterUID()
+	err := le.readClusterUID(ctx)
 	if err != nil {
-		license, _ := verifier.BadLicense(err)
-		return license, nil
+		license, err := verifier.BadLicense(err)
+		return license, nil, err
 	}
 	// Read license from file
-	err = le.acquireLicense()
+	err = le.acquireLicense(ctx)
 	if err != nil {
-		license, _ := verifier.BadLicense(err)
-		return license, nil
+		license, err := verifier.BadLicense(err)
+		return license, nil, err
 	}
-	license, _ := verifier.CheckLicense(le.opts)
-	return license, le.opts.License
+	license, err := le.verify(ctx)
+	return license, le.opts.License, err
 }
 
 // VerifyLicensePeriodically periodically verifies whether the provided license is valid for the current cluster or not.
 func VerifyLicensePeriodically(config *rest.Config, licenseFile string, stopCh <-chan struct{}) error {
+	return VerifyLicensePeriodicallyWithContext(wait.ContextForChannel(stopCh), config, licenseFile)
+}
+
+// VerifyLicensePeriodicallyWithContext periodically verifies whether the provided license is valid
+// for the current cluster or not, until ctx is cancelled.
+func VerifyLicensePeriodicallyWithContext(ctx context.Context, config *rest.Config, licenseFile string) error {
 	if info.SkipLicenseVerification() {
 		klog.Infoln("License verification skipped")
 		return nil
@@ -258,47 +537,171 @@ func VerifyLicensePeriodically(config *rest.Config, licenseFile string, stopCh <
 	if err != nil {
 		return le.handleLicenseVerificationFailure(err)
 	}
-	if err := verifyLicensePeriodically(le, licenseFile, stopCh); err != nil {
+	return le.RunWithContext(ctx)
+}
+
+// Run periodically verifies whether the configured license is valid for the current cluster or not.
+func (le *LicenseEnforcer) Run(stopCh <-chan struct{}) error {
+	return le.RunWithContext(wait.ContextForChannel(stopCh))
+}
+
+// RunWithContext periodically verifies whether the configured license is valid for the current cluster or not,
+// until ctx is cancelled.
+func (le *LicenseEnforcer) RunWithContext(ctx context.Context) error {
+	if info.SkipLicenseVerification() {
+		klog.Infoln("License verification skipped")
+		return nil
+	}
+
+	if err := verifyLicensePeriodically(ctx, le); err != nil {
 		return le.handleLicenseVerificationFailure(err)
 	}
 	return nil
 }
 
-func verifyLicensePeriodically(le *LicenseEnforcer, licenseFile string, stopCh <-chan struct{}) error {
+func verifyLicensePeriodically(ctx context.Context, le *LicenseEnforcer) error {
 	// Create Kubernetes client
 	err := le.createClients()
 	if err != nil {
 		return err
 	}
 	// Read cluster UID (UID of the "kube-system" namespace)
-	err = le.readClusterUID()
-	if err != nil {
+	err = le.readClusterUID(ctx)
+	if err != nil && le.failureStreak == nil {
 		return err
 	}
 
-	// Periodically verify license with 1 hour interval
+	changed := make(chan struct{}, 1)
+	removed := make(chan struct{}, 1)
+
+	// Periodically verify license with the configured interval (1 hour by default)
 	fn := func(ctx context.Context) (done bool, err error) {
 		klog.V(8).Infoln("Verifying license.......")
-		// Read license from file
-		err = le.acquireLicense()
-		if err != nil {
-			return false, err
+		start := le.clock.Now()
+		// Read cluster UID again, if it could not be read on startup, and the license from file
+		license, _, err := le.verifyLicense(ctx)
+		if ctx.Err() != nil {
+			// shutting down, the failure is not caused by the license
+			return false, ctx.Err()
+		}
+		result := le.recordVerification(license, err, start)
+		if delay, ok := le.withinFailureThreshold(license, err); ok {
+			klog.Warningf("Failed to verify license, retrying in %s. Reason: %v", delay, err)
+			le.retryAfter(ctx, delay, changed)
+			return false, nil
+		}
+		if le.withinErrorBudget(err) {
+			klog.Warningf("Failed to verify license, tolerated by error budget. Reason: %v", err)
+			return false, nil
+		}
+		if le.damped(err) {
+			klog.Warningf("License verification status is flapping, keeping the previous status. Last error: %v", err)
+			return false, nil
+		}
+		le.actOn(result)
+		le.notifyLicenseChange(license, err)
+		if le.maintenance != nil && !le.dryRun {
+			le.maintenance.SetLicenseError(err)
+			if err != nil {
+				klog.Errorln("Failed to verify license, serving maintenance response. Reason: ", err.Error())
+				return false, nil
+			}
 		}
-		// Validate license
-		_, err = verifier.CheckLicense(le.opts)
 		if err != nil {
+			if !le.crashOnFailure(err) {
+				// the process survives the failure, so keep verifying to notice a fixed license
+				_ = le.handleLicenseVerificationFailure(err)
+				return false, nil
+			}
 			return false, err
 		}
+		if le.lastLicenseID != "" && le.lastLicenseID != license.ID {
+			klog.Infof("License %s has been replaced by license %s", le.lastLicenseID, license.ID)
+		}
+		if le.lastLicenseID != license.ID {
+			le.recordLicenseVerified(license)
+			le.reportLicenseFormat(license)
+			le.shareLicense(ctx, license)
+		}
+		le.lastLicenseID = license.ID
+		if license.Status == v1alpha1.LicenseGracePeriod {
+			le.warnGracePeriod(license)
+			return false, nil
+		}
 		klog.Infoln("Successfully verified license!")
+		le.warnExpiry(license)
+		if license.NotAfter != nil {
+			klog.V(4).Infof("License %s is valid until %s", license.ID, license.NotAfter.UTC().Format(time.RFC3339))
+		}
 		// return false so that the loop never ends
 		return false, nil
 	}
 
-	return wait.PollUntilContextCancel(wait.ContextForChannel(stopCh), licenseCheckInterval, true, fn)
+	if le.licenseSecret != nil {
+		le.watchLicenseSecret(ctx, changed, removed)
+	} else if (le.watchLicenseFile || le.removalPolicy != "") && le.licenseFile != "" {
+		if err := le.watchLicense(ctx, changed, removed); err != nil {
+			// the hourly check still picks up a renewed license
+			klog.Warningf("Failed to watch license file %s. Reason: %v", le.licenseFile, err)
+		}
+	}
+
+	le.startLeaderElection(ctx)
+	go le.handleLicenseRemoval(ctx, removed, changed)
+	go le.recheckCapacity(ctx, changed)
+	go le.reportUsagePeriodically(ctx)
+
+	err = pollWithJitter(ctx, le.checkInterval, le.jitter, le.verifyOnStartup, changed, fn)
+	if wait.Interrupted(err) {
+		// ctx was cancelled, so the process is shutting down
+		return nil
+	}
+	return err
+}
+
+// pollWithJitter calls fn every interval, stretched by a random factor of up to jitter,
+// until fn returns true or an error, or ctx is cancelled. Jitter spreads the re-verification
+// of many operator pods started at the same time. A jitter of 0 disables it.
+// A value received from trigger calls fn early.
+func pollWithJitter(ctx context.Context, interval time.Duration, jitter float64, immediate bool, trigger <-chan struct{}, fn wait.ConditionWithContextFunc) error {
+	if !immediate {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-trigger:
+		case <-time.After(jittered(interval, jitter)):
+		}
+	}
+	for {
+		if done, err := fn(ctx); err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-trigger:
+		case <-time.After(jittered(interval, jitter)):
+		}
+	}
+}
+
+// jittered returns interval stretched by a random factor of up to jitter. Unlike wait.Jitter,
+// which uses a factor of 1.0 for a jitter <= 0, a jitter <= 0 returns interval unchanged.
+func jittered(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return wait.Jitter(interval, jitter)
 }
 
 // CheckLicenseFile verifies whether the provided license is valid for the current cluster or not.
 func CheckLicenseFile(config *rest.Config, licenseFile string) error {
+	return VerifyLicenseWithContext(context.TODO(), config, licenseFile)
+}
+
+// VerifyLicenseWithContext verifies whether the provided license is valid for the current cluster or not.
+// ctx is used for all api calls and file reads, so its deadline bounds the verification.
+func VerifyLicenseWithContext(ctx context.Context, config *rest.Config, licenseFile string) error {
 	if info.SkipLicenseVerification() {
 		klog.Infoln("License verification skipped")
 		return nil
@@ -309,30 +712,50 @@ func CheckLicenseFile(config *rest.Config, licenseFile string) error {
 	if err != nil {
 		return le.handleLicenseVerificationFailure(err)
 	}
-	if err := checkLicenseFile(le); err != nil {
+	if err := checkLicenseFile(ctx, le); err != nil {
 		return le.handleLicenseVerificationFailure(err)
 	}
 	return nil
 }
 
-func checkLicenseFile(le *LicenseEnforcer) error {
+// CheckLicenseFileQuiet verifies whether the provided license is valid for the current cluster or not.
+// Unlike CheckLicenseFile, it never sends events or shuts down the process on failure, so it is
+// safe to use for read-only callers like status endpoints.
+func CheckLicenseFileQuiet(config *rest.Config, licenseFile string) (v1alpha1.License, error) {
+	le, err := NewLicenseEnforcer(config, licenseFile)
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	if err = le.createClients(); err != nil {
+		return verifier.BadLicense(err)
+	}
+	if err = le.readClusterUID(context.TODO()); err != nil {
+		return verifier.BadLicense(err)
+	}
+	if err = le.acquireLicense(context.TODO()); err != nil {
+		return verifier.BadLicense(err)
+	}
+	return le.verify(context.TODO())
+}
+
+func checkLicenseFile(ctx context.Context, le *LicenseEnforcer) error {
 	// Create Kubernetes client
 	err := le.createClients()
 	if err != nil {
 		return err
 	}
 	// Read cluster UID (UID of the "kube-system" namespace)
-	err = le.readClusterUID()
+	err = le.readClusterUID(ctx)
 	if err != nil {
 		return err
 	}
 	// Read license from file
-	err = le.acquireLicense()
+	err = le.acquireLicense(ctx)
 	if err != nil {
 		return err
 	}
 	// Validate license
-	_, err = verifier.CheckLicense(le.opts)
+	_, err = le.verify(ctx)
 	if err != nil {
 		return err
 	}
@@ -387,7 +810,7 @@ func CheckLicenseEndpoint(config *rest.Config, apiServiceName string, features [
 		return err
 	}
 
-	if license.Status != v1alpha1.LicenseActive {
+	if license.Status != v1alpha1.LicenseActive && license.Status != v1alpha1.LicenseGracePeriod {
 		return fmt.Errorf("license %s is not active, status: %s, reason: %s", license.ID, license.Status, license.Reason)
 	}
 
@@ -398,7 +821,7 @@ func CheckLicenseEndpoint(config *rest.Config, apiServiceName string, features [
 }
 
 func LicenseProvided(cfg *rest.Config, licenseFile string) bool {
-	if licenseFile != "" {
+	if licenseFile != "" || len(licenseFromEnv()) > 0 {
 		return true
 	}
 
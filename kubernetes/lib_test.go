@@ -0,0 +1,58 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+func TestJittered(t *testing.T) {
+	const interval = time.Hour
+	for _, jitter := range []float64{0, -1} {
+		if got := jittered(interval, jitter); got != interval {
+			t.Errorf("jittered(%v, %v) = %v, want %v", interval, jitter, got, interval)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if got := jittered(interval, 0.1); got < interval || got > interval+interval/10 {
+			t.Fatalf("jittered(%v, 0.1) = %v, want within [%v, %v]", interval, got, interval, interval+interval/10)
+		}
+	}
+}
+
+func TestContractFor(t *testing.T) {
+	contract := &v1alpha1.Contract{ID: "contract-1"}
+	le := &LicenseEnforcer{}
+	le.setContract(contract)
+
+	acquired := v1alpha1.License{ID: "license-1"}
+	if got := le.contractFor(acquired); got != contract {
+		t.Fatalf("contractFor(acquired license) = %v, want %v", got, contract)
+	}
+	if got := le.contractFor(acquired); got != contract {
+		t.Fatalf("contractFor(acquired license) on re-verification = %v, want %v", got, contract)
+	}
+	if got := le.contractFor(v1alpha1.License{ID: "license-2"}); got != nil {
+		t.Fatalf("contractFor(replacement license) = %v, want nil", got)
+	}
+	if got := le.contractFor(acquired); got != nil {
+		t.Fatalf("contractFor(acquired license) after replacement = %v, want nil", got)
+	}
+}
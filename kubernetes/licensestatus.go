@@ -0,0 +1,116 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	"go.bytebuilders.dev/license-verifier/apis/status/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// LicenseStatusStorage serves the licensestatuses virtual resource of the status.licenses.appscode.com api group.
+// The status of the last periodic verification is served, so no license material is stored in etcd
+// and requests don't reach the license issuer.
+type LicenseStatusStorage struct {
+	le *LicenseEnforcer
+	rest.TableConvertor
+}
+
+var (
+	_ rest.Storage              = &LicenseStatusStorage{}
+	_ rest.Scoper               = &LicenseStatusStorage{}
+	_ rest.Getter               = &LicenseStatusStorage{}
+	_ rest.Lister               = &LicenseStatusStorage{}
+	_ rest.SingularNameProvider = &LicenseStatusStorage{}
+)
+
+// NewLicenseStatusStorage returns the REST storage of licensestatuses. Add it to the
+// VersionedResourcesStorageMap of the aggregated api server of the product, eg.
+// apiGroupInfo.VersionedResourcesStorageMap["v1alpha1"] = map[string]rest.Storage{"licensestatuses": storage}
+func (le *LicenseEnforcer) NewLicenseStatusStorage() *LicenseStatusStorage {
+	return &LicenseStatusStorage{
+		le:             le,
+		TableConvertor: rest.NewDefaultTableConvertor(v1alpha1.Resource(v1alpha1.ResourceLicenseStatuses)),
+	}
+}
+
+func (s *LicenseStatusStorage) New() runtime.Object {
+	return &v1alpha1.LicenseStatus{}
+}
+
+func (s *LicenseStatusStorage) Destroy() {}
+
+func (s *LicenseStatusStorage) NamespaceScoped() bool {
+	return false
+}
+
+func (s *LicenseStatusStorage) GetSingularName() string {
+	return v1alpha1.ResourceLicenseStatus
+}
+
+// Get returns the license status of the product. name must be the product.
+func (s *LicenseStatusStorage) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	if name != s.product() {
+		return nil, kerr.NewNotFound(v1alpha1.Resource(v1alpha1.ResourceLicenseStatuses), name)
+	}
+	return s.licenseStatus(ctx), nil
+}
+
+func (s *LicenseStatusStorage) NewList() runtime.Object {
+	return &v1alpha1.LicenseStatusList{}
+}
+
+// List returns the license status of the product.
+func (s *LicenseStatusStorage) List(ctx context.Context, _ *metainternalversion.ListOptions) (runtime.Object, error) {
+	return &v1alpha1.LicenseStatusList{
+		Items: []v1alpha1.LicenseStatus{*s.licenseStatus(ctx)},
+	}, nil
+}
+
+// product returns the first feature of the product, which names its license status.
+func (s *LicenseStatusStorage) product() string {
+	if features := info.ParseFeatures(s.le.opts.Features); len(features) > 0 {
+		return features[0]
+	}
+	return info.ProductName
+}
+
+func (s *LicenseStatusStorage) licenseStatus(ctx context.Context) *v1alpha1.LicenseStatus {
+	result, ok := s.le.EffectiveVerification()
+	if !ok {
+		// the periodic verification is not running or has not finished yet
+		result.License, _, result.Err = s.le.loadLicense(ctx)
+		result.Time = s.le.clock.Now()
+	}
+	status := verifier.NewLicenseStatus(result.License, result.Err, result.Time)
+	return &v1alpha1.LicenseStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: s.product(),
+		},
+		Valid:      result.Err == nil,
+		License:    status.License,
+		Conditions: status.Conditions,
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+// MaintenanceHandler serves the wrapped handler while the license is valid and a
+// maintenance response while it is not. This lets HTTP serving products keep
+// answering their users with a clear message instead of connection errors.
+type MaintenanceHandler struct {
+	next        http.Handler
+	page        []byte
+	contentType string
+
+	mu     sync.RWMutex
+	reason string
+}
+
+// NewMaintenanceHandler returns a handler that serves next until the license verification fails.
+// If page is empty, a json response containing the failure reason is served.
+func NewMaintenanceHandler(next http.Handler, page []byte, contentType string) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		next:        next,
+		page:        page,
+		contentType: contentType,
+	}
+}
+
+// SetLicenseError switches to the maintenance response if err is not nil and back to the wrapped handler otherwise.
+func (h *MaintenanceHandler) SetLicenseError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.reason = err.Error()
+	} else {
+		h.reason = ""
+	}
+}
+
+func (h *MaintenanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	reason := h.reason
+	h.mu.RUnlock()
+
+	if reason == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("x-content-type-options", "nosniff")
+	w.Header().Set("Retry-After", "3600")
+	if len(h.page) > 0 {
+		w.Header().Set("Content-Type", h.contentType)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write(h.page)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	utilruntime.Must(json.NewEncoder(w).Encode(map[string]string{
+		"status": "license invalid",
+		"reason": reason,
+	}))
+}
+
+// ServeMaintenanceWhileUnlicensed makes the enforcer switch h to its maintenance response
+// instead of shutting down the process when the periodic license verification fails.
+func (le *LicenseEnforcer) ServeMaintenanceWhileUnlicensed(h *MaintenanceHandler) {
+	le.maintenance = h
+}
@@ -0,0 +1,113 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	verifier "go.bytebuilders.dev/license-verifier"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "license"
+
+// Metrics exports the result of license verifications to Prometheus.
+// Metrics implements prometheus.Collector, so it can be registered into an existing registry.
+type Metrics struct {
+	verifications *prometheus.CounterVec
+	expiry        prometheus.Gauge
+	features      *prometheus.GaugeVec
+	duration      prometheus.Gauge
+	checks        *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = &Metrics{}
+
+// NewMetrics returns unregistered license metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "verification_total",
+			Help:      "Total number of license verifications, partitioned by result.",
+		}, []string{"result"}),
+		expiry: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "expiry_timestamp_seconds",
+			Help:      "Unix timestamp at which the current license expires.",
+		}),
+		features: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "features",
+			Help:      "Features enabled by the current license. Value is always 1.",
+		}, []string{"id", "plan", "feature"}),
+		duration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_verification_duration_seconds",
+			Help:      "Duration of the last license verification in seconds.",
+		}),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "check_total",
+			Help:      "Total number of license verification checks, partitioned by check and result.",
+		}, []string{"check", "result"}),
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.verifications.Describe(ch)
+	m.expiry.Describe(ch)
+	m.features.Describe(ch)
+	m.duration.Describe(ch)
+	m.checks.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.verifications.Collect(ch)
+	m.expiry.Collect(ch)
+	m.features.Collect(ch)
+	m.duration.Collect(ch)
+	m.checks.Collect(ch)
+}
+
+func (m *Metrics) observe(result VerificationResult) {
+	m.duration.Set(result.Duration.Seconds())
+	if result.Err != nil {
+		m.verifications.WithLabelValues("failure").Inc()
+		return
+	}
+	m.verifications.WithLabelValues("success").Inc()
+
+	license := result.License
+	if license.NotAfter != nil {
+		m.expiry.Set(float64(license.NotAfter.Unix()))
+	}
+	m.features.Reset()
+	for _, feature := range license.Features {
+		m.features.WithLabelValues(license.ID, license.PlanName, feature).Set(1)
+	}
+}
+
+func (m *Metrics) observeChecks(checks []verifier.CheckResult) {
+	for _, c := range checks {
+		m.checks.WithLabelValues(c.Name, c.Outcome()).Inc()
+	}
+}
+
+// SetMetrics makes the enforcer record the result of every verification into m.
+func (le *LicenseEnforcer) SetMetrics(m *Metrics) {
+	le.metrics = m
+}
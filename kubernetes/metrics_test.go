@@ -0,0 +1,56 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveChecks(t *testing.T) {
+	le := &LicenseEnforcer{}
+	le.SetMetrics(NewMetrics())
+
+	checks := []verifier.CheckResult{
+		{Name: verifier.CheckParse, Passed: true},
+		{Name: verifier.CheckClusterBinding, Error: "license was not issued for this cluster"},
+		{Name: verifier.CheckExpiry, Skipped: true},
+	}
+	le.recordChecks(checks)
+	le.recordChecks(checks[:1])
+
+	tests := []struct {
+		check, result string
+		want          float64
+	}{
+		{verifier.CheckParse, verifier.CheckPassed, 2},
+		{verifier.CheckClusterBinding, verifier.CheckFailed, 1},
+		{verifier.CheckClusterBinding, verifier.CheckPassed, 0},
+		{verifier.CheckExpiry, verifier.CheckSkipped, 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(le.metrics.checks.WithLabelValues(tt.check, tt.result)); got != tt.want {
+			t.Errorf("check_total{check=%q,result=%q} = %v, want %v", tt.check, tt.result, got, tt.want)
+		}
+	}
+	if got := le.LastChecks(); len(got) != 1 || got[0].Name != verifier.CheckParse {
+		t.Errorf("LastChecks() = %+v, want the checks of the last verification", got)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ErrLicenseRequired is returned for requests to premium endpoints rejected by the license middleware.
+// Requests rejected because the license has expired also match verifier.ErrLicenseExpired.
+var ErrLicenseRequired = errors.New("license required")
+
+// CheckRequest returns why a request to a premium endpoint must be rejected, or nil if the
+// last verified license is valid and includes all the given features. A license in its grace
+// period is valid.
+func (le *LicenseEnforcer) CheckRequest(features ...string) error {
+	if le.dryRun {
+		return nil
+	}
+	s, ok := le.LicenseStatus()
+	if !ok {
+		return fmt.Errorf("%w: license has not been verified yet", ErrLicenseRequired)
+	}
+	if c := s.FindCondition(verifier.ConditionValid); c == nil || c.Status != metav1.ConditionTrue {
+		if c := s.FindCondition(verifier.ConditionExpired); c != nil && c.Status == metav1.ConditionTrue {
+			return fmt.Errorf("%w: %w", ErrLicenseRequired, verifier.ErrLicenseExpired)
+		}
+		msg := "license is invalid"
+		if c != nil && c.Message != "" {
+			msg = c.Message
+		}
+		return fmt.Errorf("%w: %s", ErrLicenseRequired, msg)
+	}
+	covered := sets.NewString(le.opts.FeatureAliases.Resolve(s.License.Features)...)
+	for _, f := range features {
+		if !covered.Has(f) {
+			return fmt.Errorf("%w: license does not include feature %s", ErrLicenseRequired, f)
+		}
+	}
+	return nil
+}
+
+// HTTPMiddleware returns a net/http middleware that rejects requests with 403 Forbidden unless the
+// last verified license is valid and includes all the given features. Wrap the handlers of premium
+// endpoints with it to keep the rest of the API server available without a license.
+// The gRPC interceptors are in the grpcauth package, so that products without gRPC don't depend on it.
+func (le *LicenseEnforcer) HTTPMiddleware(features ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := le.CheckRequest(features...); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestCheckRequest(t *testing.T) {
+	now := time.Now()
+	active := testLicense("a", now.AddDate(1, 0, 0))
+	active.Features = []string{"kubedb-enterprise"}
+	grace := active
+	grace.Status = v1alpha1.LicenseGracePeriod
+	grace.Reason = "license expired, grace period ends soon"
+	expired, expiredErr := verifier.BadLicense(verifier.ErrLicenseExpired)
+	expired.Status = v1alpha1.LicenseInvalid
+
+	tests := []struct {
+		name    string
+		license *v1alpha1.License
+		err     error
+		dryRun  bool
+		want    []error
+	}{
+		{name: "not verified", want: []error{ErrLicenseRequired}},
+		{name: "active", license: &active},
+		{name: "grace period", license: &grace},
+		{name: "expired", license: &expired, err: expiredErr, want: []error{ErrLicenseRequired, verifier.ErrLicenseExpired}},
+		{name: "invalid", license: &v1alpha1.License{}, err: verifier.ErrWrongCluster, want: []error{ErrLicenseRequired}},
+		{name: "dry run", license: &v1alpha1.License{}, err: verifier.ErrWrongCluster, dryRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			le := &LicenseEnforcer{clock: clocktesting.NewFakeClock(now), dryRun: tt.dryRun}
+			if tt.license != nil {
+				le.actOn(le.recordVerification(*tt.license, tt.err, now))
+			}
+			err := le.CheckRequest("kubedb-enterprise")
+			if len(tt.want) == 0 && err != nil {
+				t.Errorf("CheckRequest() error = %v, want nil", err)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("CheckRequest() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+
+	le := &LicenseEnforcer{clock: clocktesting.NewFakeClock(now)}
+	le.actOn(le.recordVerification(active, nil, now))
+	if err := le.CheckRequest("kubedb-autoscaler"); !errors.Is(err, ErrLicenseRequired) {
+		t.Errorf("CheckRequest() for a missing feature error = %v, want %v", err, ErrLicenseRequired)
+	}
+}
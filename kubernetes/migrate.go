@@ -0,0 +1,44 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// reportLicenseFormat records the format of a newly verified license, so that the
+// migration from x509 to JWT licenses can be tracked across clusters.
+func (le *LicenseEnforcer) reportLicenseFormat(license v1alpha1.License) {
+	msg := fmt.Sprintf("License %s uses the %s format", license.ID, license.Format)
+	if license.Format == info.LicenseFormatX509 {
+		msg += "; x509 licenses will be replaced by JWT licenses"
+	}
+	klog.Infoln(msg)
+	err := le.eventEmitter().Emit(func(ctx context.Context) error {
+		return le.recordEvent(ctx, "license-format", core.EventTypeNormal, EventReasonLicenseFormat, msg)
+	})
+	if err != nil {
+		klog.Warningln(err)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses"
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const defaultNamespaceLabelInterval = 5 * time.Minute
+
+// NamespaceLabelKey returns the label set on namespaces covered by a license of the given product line.
+func NamespaceLabelKey(productLine string) string {
+	return licenses.GroupName + "/" + productLine
+}
+
+// RunNamespaceLabeler periodically labels the namespaces covered by the license with
+// licenses.appscode.com/<product-line>=true and removes the label from namespaces that are
+// no longer covered, so that other policy engines can key off license coverage.
+func (le *LicenseEnforcer) RunNamespaceLabeler(interval time.Duration, stopCh <-chan struct{}) error {
+	if err := le.createClients(); err != nil {
+		return err
+	}
+	if interval == 0 {
+		interval = defaultNamespaceLabelInterval
+	}
+
+	ctx := wait.ContextForChannel(stopCh)
+	wait.Until(func() {
+		license, _ := le.LoadLicenseWithContext(ctx)
+		if err := le.labelNamespaces(ctx, license); err != nil {
+			klog.Errorln("Failed to label licensed namespaces. Reason: ", err.Error())
+		}
+	}, interval, stopCh)
+	return nil
+}
+
+func (le *LicenseEnforcer) labelNamespaces(ctx context.Context, license v1alpha1.License) error {
+	productLines := le.productLines(license)
+	if len(productLines) == 0 {
+		return nil
+	}
+
+	namespaces, err := le.kc.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list namespaces")
+	}
+	active := license.Status == v1alpha1.LicenseActive || license.Status == v1alpha1.LicenseGracePeriod
+	for _, ns := range namespaces.Items {
+		labels := map[string]*string{}
+		for _, productLine := range productLines {
+			key := NamespaceLabelKey(productLine)
+			covered := active && license.ProductLine == productLine && license.CoversNamespace(ns.Name)
+			_, labeled := ns.Labels[key]
+			if covered && !labeled {
+				v := "true"
+				labels[key] = &v
+			} else if !covered && labeled {
+				labels[key] = nil
+			}
+		}
+		if len(labels) == 0 {
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"labels": labels,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = le.kc.CoreV1().Namespaces().Patch(ctx, ns.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to patch namespace %s", ns.Name)
+		}
+	}
+	return nil
+}
+
+// productLines returns the product lines whose namespace labels are managed by the enforcer.
+// They are taken from the features of the product, so that the labels are also removed when
+// the license can't be read, plus the product line of the license.
+func (le *LicenseEnforcer) productLines(license v1alpha1.License) []string {
+	out := sets.NewString()
+	if license.ProductLine != "" {
+		out.Insert(license.ProductLine)
+	}
+	for _, f := range info.ParseFeatures(le.opts.Features) {
+		if productLine, _, _ := strings.Cut(f, "-"); productLine != "" {
+			out.Insert(productLine)
+		}
+	}
+	return out.List()
+}
@@ -0,0 +1,71 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLabelNamespaces(t *testing.T) {
+	ctx := context.Background()
+	key := NamespaceLabelKey("kubedb")
+	kc := fake.NewSimpleClientset(
+		&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+	)
+	le := &LicenseEnforcer{kc: kc}
+	le.opts.Features = "kubedb-enterprise"
+
+	labeled := func() map[string]bool {
+		out := map[string]bool{}
+		namespaces, err := kc.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ns := range namespaces.Items {
+			_, out[ns.Name] = ns.Labels[key]
+		}
+		return out
+	}
+
+	license := v1alpha1.License{
+		ProductLine:  "kubedb",
+		Status:       v1alpha1.LicenseActive,
+		FeatureFlags: map[string]string{v1alpha1.FeatureFlagNamespaces: "team-a"},
+	}
+	if err := le.labelNamespaces(ctx, license); err != nil {
+		t.Fatal(err)
+	}
+	if got := labeled(); !got["team-a"] || got["team-b"] {
+		t.Errorf("labeled namespaces = %v, want only team-a", got)
+	}
+
+	// the license can't be read, so it has no product line, but the labels must still be removed
+	if err := le.labelNamespaces(ctx, v1alpha1.License{Status: v1alpha1.LicenseUnknown}); err != nil {
+		t.Fatal(err)
+	}
+	if got := labeled(); got["team-a"] || got["team-b"] {
+		t.Errorf("labeled namespaces = %v, want none", got)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NodeRestrictionPolicy decides what happens when nodes are not permitted by the license.
+type NodeRestrictionPolicy string
+
+const (
+	// NodeRestrictionAdvisory only reports nodes not permitted by the license.
+	NodeRestrictionAdvisory NodeRestrictionPolicy = "Advisory"
+	// NodeRestrictionEnforce fails verification if the cluster has nodes not permitted by the license.
+	NodeRestrictionEnforce NodeRestrictionPolicy = "Enforce"
+)
+
+// LicensedNodeAffinity returns a node affinity that schedules workloads only on the nodes permitted by the license.
+// It returns nil if the license does not restrict nodes.
+func LicensedNodeAffinity(license v1alpha1.License) (*core.NodeAffinity, error) {
+	sel := license.NodeSelector()
+	if sel == "" {
+		return nil, nil
+	}
+	reqs, err := nodeSelectorRequirements(sel)
+	if err != nil {
+		return nil, err
+	}
+	return &core.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &core.NodeSelector{
+			NodeSelectorTerms: []core.NodeSelectorTerm{
+				{MatchExpressions: reqs},
+			},
+		},
+	}, nil
+}
+
+// CheckNodeEntitlements returns a warning for every node not permitted by the license.
+// With NodeRestrictionEnforce, an error is also returned if any such node is found.
+func CheckNodeEntitlements(ctx context.Context, kc kubernetes.Interface, license v1alpha1.License, policy NodeRestrictionPolicy) ([]string, error) {
+	sel := license.NodeSelector()
+	if sel == "" {
+		return nil, nil
+	}
+	selector, err := labels.Parse(sel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "license %s has invalid node selector", license.ID)
+	}
+
+	nodes, err := kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	var warnings []string
+	for _, node := range nodes.Items {
+		if !selector.Matches(labels.Set(node.Labels)) {
+			warnings = append(warnings, fmt.Sprintf("node %s is not permitted by license %s (plan %s), permitted nodes must match %q", node.Name, license.ID, license.PlanName, sel))
+		}
+	}
+	if policy == NodeRestrictionEnforce && len(warnings) > 0 {
+		return warnings, fmt.Errorf("%d node(s) are not permitted by license %s", len(warnings), license.ID)
+	}
+	return warnings, nil
+}
+
+func nodeSelectorRequirements(sel string) ([]core.NodeSelectorRequirement, error) {
+	selector, err := labels.Parse(sel)
+	if err != nil {
+		return nil, err
+	}
+	reqs, _ := selector.Requirements()
+
+	out := make([]core.NodeSelectorRequirement, 0, len(reqs))
+	for _, r := range reqs {
+		nr := core.NodeSelectorRequirement{
+			Key:    r.Key(),
+			Values: r.Values().List(),
+		}
+		switch r.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			nr.Operator = core.NodeSelectorOpIn
+		case selection.NotIn, selection.NotEquals:
+			nr.Operator = core.NodeSelectorOpNotIn
+		case selection.Exists:
+			nr.Operator = core.NodeSelectorOpExists
+		case selection.DoesNotExist:
+			nr.Operator = core.NodeSelectorOpDoesNotExist
+		case selection.GreaterThan:
+			nr.Operator = core.NodeSelectorOpGt
+		case selection.LessThan:
+			nr.Operator = core.NodeSelectorOpLt
+		default:
+			return nil, fmt.Errorf("unsupported node selector operator %s", r.Operator())
+		}
+		out = append(out, nr)
+	}
+	return out, nil
+}
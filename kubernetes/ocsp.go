@@ -0,0 +1,183 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"golang.org/x/crypto/ocsp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// DefaultOCSPTimeout is the timeout of requests to the OCSP responder.
+const DefaultOCSPTimeout = 10 * time.Second
+
+// OCSPConfig enables revocation checks against the OCSP responder embedded in the license certificate.
+// Licenses without an OCSP responder url and JWT licenses are not checked.
+type OCSPConfig struct {
+	// Timeout of requests to the OCSP responder. Defaults to DefaultOCSPTimeout.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// Policy decides what happens when the OCSP responder is unreachable or does not know the license.
+	// Defaults to FailOpen.
+	Policy verifier.RevocationPolicy `json:"policy,omitempty"`
+}
+
+// ocspCache holds the OCSP responses by license ID until their NextUpdate time.
+type ocspCache struct {
+	mu        sync.Mutex
+	responses map[string]*ocsp.Response
+}
+
+// SetOCSP configures the enforcer to check licenses with the OCSP responder of the license issuer.
+func (le *LicenseEnforcer) SetOCSP(c *OCSPConfig) {
+	le.ocsp = c
+}
+
+// checkOCSP checks the license with the OCSP responder named in the license. cas are the trusted license CAs.
+func (le *LicenseEnforcer) checkOCSP(ctx context.Context, license *v1alpha1.License, cas []*x509.Certificate) error {
+	if le.ocsp == nil || verifier.IsJWT(license.Data) {
+		return nil
+	}
+	certs, err := info.ParseCertificates(license.Data)
+	if err != nil {
+		return err
+	}
+	cert := certs[0]
+	if len(cert.OCSPServer) == 0 {
+		return nil
+	}
+	var issuer *x509.Certificate
+	if len(cas) > 0 {
+		issuer = cas[0]
+	}
+	if len(certs) > 1 {
+		issuer = certs[1]
+	} else {
+		// during a CA rotation, the license may be issued by any of the trusted CAs
+		for _, ca := range cas {
+			if bytes.Equal(ca.RawSubject, cert.RawIssuer) {
+				issuer = ca
+				break
+			}
+		}
+	}
+
+	err = le.checkOCSPResponse(ctx, license, cert, issuer)
+	if err == nil || license.Status == v1alpha1.LicenseCanceled || errors.Is(err, verifier.ErrOCSPNonceMismatch) {
+		// a mismatched nonce means the response was replayed, which must not be skipped by FailOpen
+		return err
+	}
+	if le.ocsp.Policy == verifier.RevocationPolicyFailClosed {
+		license.Status = v1alpha1.LicenseUnknown
+		license.Reason = err.Error()
+		return err
+	}
+	klog.Warningf("Skipping OCSP check of license %s. Reason: %v", license.ID, err)
+	return nil
+}
+
+func (le *LicenseEnforcer) checkOCSPResponse(ctx context.Context, license *v1alpha1.License, cert, issuer *x509.Certificate) error {
+	now := le.clock.Now()
+	c := &le.ocspResponses
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp, ok := c.responses[license.ID]; ok && now.Before(resp.NextUpdate) {
+		return verifier.CheckOCSPStatus(license, resp, now)
+	}
+
+	resp, err := le.queryOCSP(ctx, license, cert, issuer)
+	if err != nil {
+		return err
+	}
+	if c.responses == nil {
+		c.responses = map[string]*ocsp.Response{}
+	}
+	if !resp.NextUpdate.IsZero() {
+		c.responses[license.ID] = resp
+	}
+	return verifier.CheckOCSPStatus(license, resp, now)
+}
+
+// queryOCSP sends an OCSP request with a fresh nonce to the responders of the license certificate
+// and returns the first verified response.
+func (le *LicenseEnforcer) queryOCSP(ctx context.Context, license *v1alpha1.License, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	req, nonce, err := verifier.NewOCSPRequest(cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+	timeout := le.ocsp.Timeout.Duration
+	if timeout <= 0 {
+		timeout = DefaultOCSPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// the responders are run by the license issuer, so they are reached like the issuer, eg. through its proxy
+	c, err := le.newIssuerClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, server := range cert.OCSPServer {
+		data, err := postOCSPRequest(ctx, c.HTTPClient(), server, req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// the status is checked by the caller, so that cached responses are checked the same way
+		var scratch v1alpha1.License
+		resp, err := verifier.CheckOCSPResponse(&scratch, data, cert, issuer, nonce, le.clock.Now())
+		if resp == nil {
+			errs = append(errs, err)
+			continue
+		}
+		return resp, nil
+	}
+	return nil, errors.Wrapf(utilerrors.NewAggregate(errs), "failed to check license %s with the OCSP responder", license.ID)
+}
+
+func postOCSPRequest(ctx context.Context, hc *http.Client, server string, req []byte) ([]byte, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/ocsp-request")
+	r.Header.Set("Accept", "application/ocsp-response")
+	resp, err := hc.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("OCSP responder %s returned status %s", server, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/klog/v2"
+)
+
+// checkOnline confirms with the license issuer that the license has not been revoked or transferred
+// to another cluster. If the issuer can't be reached, the result of the offline verification is kept.
+func (le *LicenseEnforcer) checkOnline(license *v1alpha1.License) error {
+	if le.issuer == nil || !le.issuer.OnlineVerification || !le.IsLeader() {
+		return nil
+	}
+	c, err := le.newIssuerClient()
+	if err != nil {
+		return err
+	}
+	result, err := c.VerifyLicense(license.Data)
+	if err != nil {
+		klog.Warningf("Failed to verify license %s with the license issuer, falling back to offline verification. Reason: %v", license.ID, err)
+		return nil
+	}
+
+	switch {
+	case result.Cluster != "" && result.Cluster != le.opts.ClusterUID:
+		err = fmt.Errorf("%w: license %s has been transferred to cluster %s", verifier.ErrWrongCluster, license.ID, result.Cluster)
+		license.Status = v1alpha1.LicenseInvalid
+	case result.Status == v1alpha1.LicenseCanceled, result.Status == v1alpha1.LicenseInvalid:
+		reason := result.Reason
+		if reason == "" {
+			reason = "license is " + string(result.Status)
+		}
+		err = fmt.Errorf("license %s was rejected by the license issuer: %s", license.ID, reason)
+		license.Status = result.Status
+	case result.Status == v1alpha1.LicenseUnknown:
+		klog.Warningf("License issuer does not know license %s, falling back to offline verification", license.ID)
+		return nil
+	default:
+		return nil
+	}
+	license.Reason = err.Error()
+	return err
+}
@@ -0,0 +1,69 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"kmodules.xyz/client-go/meta"
+)
+
+const (
+	// PodConditionLicenseInvalid is set on the pod before it is terminated because of an invalid license,
+	// so that `kubectl describe pod` shows why the pod died. Requires permission to patch pods/status.
+	PodConditionLicenseInvalid core.PodConditionType = "LicenseInvalid"
+
+	podConditionReasonLicenseVerificationFailed = "LicenseVerificationFailed"
+	podConditionTimeout                         = 5 * time.Second
+)
+
+// markPodLicenseInvalid records the license failure as a condition of the current pod.
+func (le *LicenseEnforcer) markPodLicenseInvalid(licenseErr error) {
+	if le.kc == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), podConditionTimeout)
+	defer cancel()
+
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": []core.PodCondition{
+				{
+					Type:               PodConditionLicenseInvalid,
+					Status:             core.ConditionTrue,
+					Reason:             podConditionReasonLicenseVerificationFailed,
+					Message:            licenseErr.Error(),
+					LastTransitionTime: metav1.NewTime(le.clock.Now()),
+				},
+			},
+		},
+	})
+	if err != nil {
+		klog.Warningln(err)
+		return
+	}
+	_, err = le.kc.CoreV1().Pods(meta.PodNamespace()).Patch(ctx, meta.PodName(), types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		klog.Warningf("Failed to set %s condition on pod. Reason: %v", PodConditionLicenseInvalid, err)
+	}
+}
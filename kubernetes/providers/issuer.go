@@ -0,0 +1,45 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ServiceAccountIssuer returns the issuer of the service account tokens of the cluster, read from the
+// service account issuer discovery of the API server. Managed platforms assign the issuer when the
+// cluster is created, eg. https://oidc.eks.<region>.amazonaws.com/id/<id> on EKS.
+func ServiceAccountIssuer(ctx context.Context, kc kubernetes.Interface) (string, error) {
+	data, err := kc.Discovery().RESTClient().Get().AbsPath("/.well-known/openid-configuration").DoRaw(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the service account issuer")
+	}
+	var cfg struct {
+		Issuer string `json:"issuer"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", errors.Wrap(err, "failed to parse the service account issuer discovery document")
+	}
+	if cfg.Issuer == "" {
+		return "", errors.New("service account issuer discovery document has no issuer")
+	}
+	return cfg.Issuer, nil
+}
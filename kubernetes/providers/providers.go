@@ -0,0 +1,142 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package providers detects the managed Kubernetes platform a cluster runs on, so that
+// licenses can be bound to an identity that the platform keeps stable across cluster rebuilds.
+package providers
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Provider is a Kubernetes platform. The values match the cluster id providers of the license enforcer.
+type Provider string
+
+const (
+	// Generic is a self managed cluster; the kube-system namespace UID identifies it.
+	Generic   Provider = "kube-system"
+	OpenShift Provider = "openshift"
+	EKS       Provider = "eks"
+	GKE       Provider = "gke"
+	AKS       Provider = "aks"
+)
+
+const openShiftConfigGroup = "config.openshift.io"
+
+// detectNodeLimit is the number of nodes inspected, all nodes of a cluster run on the same platform.
+const detectNodeLimit = 5
+
+// Detect returns the platform of the cluster kc is connected to. A managed platform is only detected
+// if both the cloud provider of the nodes and the service account issuer of the API server belong to
+// it, because node labels, node names and provider ids are also set by installers like kOps.
+// If the nodes or the issuer can't be read, eg. because of missing permissions, Generic is returned.
+func Detect(ctx context.Context, kc kubernetes.Interface) (Provider, error) {
+	return detect(ctx, kc, ServiceAccountIssuer)
+}
+
+func detect(ctx context.Context, kc kubernetes.Interface, issuerFn func(context.Context, kubernetes.Interface) (string, error)) (Provider, error) {
+	ok, err := hasGroup(kc.Discovery(), openShiftConfigGroup)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return OpenShift, nil
+	}
+
+	nodes, err := kc.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: detectNodeLimit})
+	if unreadable(err) {
+		return Generic, nil
+	} else if err != nil {
+		return "", err
+	}
+	p := DetectFromNodes(nodes.Items)
+	if p == Generic {
+		return Generic, nil
+	}
+	issuer, err := issuerFn(ctx, kc)
+	if unreadable(err) {
+		return Generic, nil
+	} else if err != nil {
+		return "", err
+	}
+	if DetectFromIssuer(issuer) != p {
+		return Generic, nil
+	}
+	return p, nil
+}
+
+// unreadable returns true if err means that the platform can't be detected, so that the kube-system
+// namespace UID is used instead of failing.
+func unreadable(err error) bool {
+	return kerr.IsForbidden(err) || kerr.IsUnauthorized(err) || kerr.IsNotFound(err)
+}
+
+// DetectFromNodes returns the cloud provider the provider ids of the nodes belong to, or Generic if
+// there is none. Control planes of managed platforms are hidden, so only worker nodes are inspected.
+// The nodes of self managed clusters on a cloud have the same provider ids, so use Detect to tell them apart.
+func DetectFromNodes(nodes []core.Node) Provider {
+	for _, node := range nodes {
+		switch {
+		case strings.HasPrefix(node.Spec.ProviderID, "aws://"):
+			return EKS
+		case strings.HasPrefix(node.Spec.ProviderID, "gce://"):
+			return GKE
+		case strings.HasPrefix(node.Spec.ProviderID, "azure://"):
+			return AKS
+		}
+	}
+	return Generic
+}
+
+// DetectFromIssuer returns the managed platform that assigned the service account issuer, or Generic
+// if there is none. Managed platforms don't allow to change the issuer.
+func DetectFromIssuer(issuer string) Provider {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme != "https" {
+		return Generic
+	}
+	host := u.Hostname()
+	switch {
+	case strings.HasPrefix(host, "oidc.eks.") && (strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn")):
+		return EKS
+	case host == "container.googleapis.com":
+		return GKE
+	case strings.HasSuffix(host, ".azmk8s.io") || strings.HasSuffix(host, ".oic.prod-aks.azure.com"):
+		return AKS
+	}
+	return Generic
+}
+
+func hasGroup(dc discovery.DiscoveryInterface, group string) (bool, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	for _, g := range groups.Groups {
+		if g.Name == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func node(name, providerID string, labels map[string]string) core.Node {
+	return core.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       core.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestDetectFromNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []core.Node
+		want  Provider
+	}{
+		{"eks label only", []core.Node{node("n1", "", map[string]string{"eks.amazonaws.com/nodegroup": "ng-1"})}, Generic},
+		{"aws", []core.Node{node("ip-10-0-0-1.ec2.compute.internal", "aws:///us-east-1a/i-0123", nil)}, EKS},
+		{"gce", []core.Node{node("gke-c1-default-pool-1", "gce://project/us-central1-a/gke-c1-default-pool-1", nil)}, GKE},
+		{"azure", []core.Node{node("aks-nodepool1-1-vmss000000", "azure:///subscriptions/s/resourceGroups/rg", nil)}, AKS},
+		{"kind", []core.Node{node("kind-control-plane", "kind://docker/kind/kind-control-plane", nil)}, Generic},
+		{"no nodes", nil, Generic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFromNodes(tt.nodes); got != tt.want {
+				t.Errorf("DetectFromNodes() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFromIssuer(t *testing.T) {
+	tests := []struct {
+		issuer string
+		want   Provider
+	}{
+		{"https://oidc.eks.us-east-1.amazonaws.com/id/EXAMPLED539D4633E53DE1B71EXAMPLE", EKS},
+		{"https://container.googleapis.com/v1/projects/p/locations/us-central1/clusters/c1", GKE},
+		{"https://c1-dns-1a2b3c4d.hcp.eastus.azmk8s.io", AKS},
+		{"https://eastus.oic.prod-aks.azure.com/tenant/uuid/", AKS},
+		{"https://api.internal.c1.k8s.local", Generic},
+		{"https://kubernetes.default.svc.cluster.local", Generic},
+		{"http://oidc.eks.us-east-1.amazonaws.com/id/X", Generic},
+	}
+	for _, tt := range tests {
+		if got := DetectFromIssuer(tt.issuer); got != tt.want {
+			t.Errorf("DetectFromIssuer(%s) = %s, want %s", tt.issuer, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	aws := node("ip-10-0-0-1.ec2.compute.internal", "aws:///us-east-1a/i-0123", nil)
+	issuer := func(iss string, err error) func(context.Context, kubernetes.Interface) (string, error) {
+		return func(context.Context, kubernetes.Interface) (string, error) { return iss, err }
+	}
+	forbidden := kerr.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("denied"))
+
+	tests := []struct {
+		name   string
+		kc     *fake.Clientset
+		issuer func(context.Context, kubernetes.Interface) (string, error)
+		want   Provider
+	}{
+		{"eks", fake.NewSimpleClientset(&aws), issuer("https://oidc.eks.us-east-1.amazonaws.com/id/X", nil), EKS},
+		{"kops on aws", fake.NewSimpleClientset(&aws), issuer("https://api.internal.c1.k8s.local", nil), Generic},
+		{"issuer forbidden", fake.NewSimpleClientset(&aws), issuer("", forbidden), Generic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := detect(context.TODO(), tt.kc, tt.issuer); err != nil || p != tt.want {
+				t.Errorf("detect() = %s, %v, want %s", p, err, tt.want)
+			}
+		})
+	}
+
+	kc := fake.NewSimpleClientset()
+	kc.PrependReactor("list", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, forbidden
+	})
+	if p, err := detect(context.TODO(), kc, issuer("", nil)); err != nil || p != Generic {
+		t.Errorf("detect() with nodes forbidden = %s, %v, want %s", p, err, Generic)
+	}
+}
+
+func TestDetectOpenShift(t *testing.T) {
+	kc := fake.NewSimpleClientset()
+	kc.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: openShiftConfigGroup + "/v1"},
+	}
+	if p, err := Detect(context.TODO(), kc); err != nil || p != OpenShift {
+		t.Fatalf("Detect() = %s, %v, want %s", p, err, OpenShift)
+	}
+}
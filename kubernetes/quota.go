@@ -0,0 +1,74 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.bytebuilders.dev/license-verifier/client"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/server/mux"
+	"k8s.io/klog/v2"
+)
+
+const licenseQuotaPath = licensePath + "/quota"
+
+// InstallQuota adds a handler that reports the utilization of the license contract.
+// issuerURL may be empty to use the default license issuer.
+func (le *LicenseEnforcer) InstallQuota(c *mux.PathRecorderMux, issuerURL, token string) {
+	err := le.createClients()
+	if err != nil {
+		klog.Fatal(err)
+		return
+	}
+	c.Handle(licenseQuotaPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-content-type-options", "nosniff")
+
+		uid, err := le.cachedClusterUID(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lc, err := client.NewClient(issuerURL, token, uid)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		quota, err := lc.GetQuota()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		utilruntime.Must(json.NewEncoder(w).Encode(quota))
+	}))
+}
+
+// cachedClusterUID returns the cluster UID, reading it if no verification has done so yet.
+// It holds verifyMu, because verifications read and cache the cluster UID concurrently.
+func (le *LicenseEnforcer) cachedClusterUID(ctx context.Context) (string, error) {
+	le.verifyMu.Lock()
+	defer le.verifyMu.Unlock()
+
+	if err := le.readClusterUID(ctx); err != nil {
+		return "", err
+	}
+	return le.opts.ClusterUID, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCachedClusterUID(t *testing.T) {
+	var calls int32
+	le := &LicenseEnforcer{
+		clusterIDProvider: ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "cluster-1", nil
+		}),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uid, err := le.cachedClusterUID(context.TODO())
+			if err != nil || uid != "cluster-1" {
+				t.Errorf("cachedClusterUID() = %q, %v, want cluster-1", uid, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("cluster UID read %d times, want once", calls)
+	}
+}
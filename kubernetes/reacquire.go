@@ -0,0 +1,116 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// IssuerConfig configures access to the license issuer.
+type IssuerConfig struct {
+	// URL of the license issuer. Defaults to the AppsCode license server.
+	URL   string `json:"url,omitempty"`
+	Token string `json:"token,omitempty"`
+	// ReacquireOnWrongCluster requests a license for the current cluster from the issuer
+	// when the license was issued for a different cluster, eg. after a DR failover.
+	ReacquireOnWrongCluster bool `json:"reacquireOnWrongCluster,omitempty"`
+	// ProxyCAFile is a PEM encoded CA bundle of a TLS intercepting proxy between the cluster and the issuer.
+	ProxyCAFile string `json:"proxyCAFile,omitempty"`
+	// OnlineVerification confirms with the issuer that a locally verified license has not been revoked
+	// or transferred to another cluster. Offline verification is used while the issuer is unreachable.
+	OnlineVerification bool `json:"onlineVerification,omitempty"`
+	// Timeout limits each request to the issuer. Defaults to client.DefaultTimeout.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// SetIssuer configures access to the license issuer.
+func (le *LicenseEnforcer) SetIssuer(issuer *IssuerConfig) {
+	le.issuer = issuer
+}
+
+func (le *LicenseEnforcer) canReacquire(err error) bool {
+	return le.issuer != nil &&
+		le.IsLeader() &&
+		le.issuer.ReacquireOnWrongCluster &&
+		le.issuer.Token != "" &&
+		errors.Is(err, verifier.ErrWrongCluster)
+}
+
+// reacquire requests a license for the current cluster from the issuer and verifies it.
+// The new license is written to the license file, if possible.
+func (le *LicenseEnforcer) reacquire(ctx context.Context, opts verifier.VerifyOptions) (v1alpha1.License, error) {
+	klog.Infoln("License was issued for a different cluster, requesting a new license for cluster", le.opts.ClusterUID)
+
+	c, err := le.newIssuerClient()
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	data, contract, err := c.AcquireLicense(ctx, info.ParseFeatures(le.opts.Features))
+	if err != nil {
+		return verifier.BadLicense(errors.Wrap(err, "failed to reacquire license"))
+	}
+	le.opts.License = data
+	le.setContract(contract)
+
+	opts.License = data
+	license, err := le.runChecks(opts)
+	if err != nil {
+		return license, err
+	}
+	if le.licenseFile != "" {
+		if err := os.WriteFile(le.licenseFile, data, 0o600); err != nil {
+			klog.Warningf("failed to write reacquired license to %s. Reason: %v", le.licenseFile, err)
+		}
+	}
+	return license, nil
+}
+
+// newIssuerClient returns a client for the configured license issuer, or the default issuer.
+func (le *LicenseEnforcer) newIssuerClient() (*client.Client, error) {
+	issuer := le.issuer
+	if issuer == nil {
+		issuer = &IssuerConfig{}
+	}
+	return issuer.newClient(le.opts.ClusterUID)
+}
+
+// newClient returns a client of the license issuer for the given cluster.
+func (issuer *IssuerConfig) newClient(clusterUID string) (*client.Client, error) {
+	c, err := client.NewClient(issuer.URL, issuer.Token, clusterUID)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.ProxyCAFile != "" {
+		if err := c.AddProxyCAFile(issuer.ProxyCAFile); err != nil {
+			return nil, err
+		}
+	}
+	if issuer.Timeout.Duration > 0 {
+		c.SetTimeout(issuer.Timeout.Duration)
+	}
+	return c, nil
+}
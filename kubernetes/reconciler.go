@@ -0,0 +1,87 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EventReasonReconciliationPaused is recorded when reconcile requests are requeued because the license is invalid.
+const EventReasonReconciliationPaused = "Reconciliation Paused"
+
+// DefaultReconcileRequeueAfter is the default delay before a reconcile request skipped
+// because of an invalid license is retried.
+const DefaultReconcileRequeueAfter = time.Minute
+
+// LicensedReconciler wraps a controller-runtime Reconciler and requeues reconcile requests
+// instead of reconciling them while the license is invalid. This stops the product from
+// mutating resources without crash-looping the whole operator.
+type LicensedReconciler struct {
+	le           *LicenseEnforcer
+	r            reconcile.Reconciler
+	requeueAfter time.Duration
+	events       eventDeduper
+}
+
+var _ reconcile.Reconciler = &LicensedReconciler{}
+
+// WrapReconciler returns a reconciler that only calls r while the license is valid.
+// Use it with a failure policy that does not exit the process, eg. FailurePolicyLogOnly.
+func (le *LicenseEnforcer) WrapReconciler(r reconcile.Reconciler) *LicensedReconciler {
+	return &LicensedReconciler{
+		le:           le,
+		r:            r,
+		requeueAfter: DefaultReconcileRequeueAfter,
+	}
+}
+
+// SetRequeueAfter sets the delay before a reconcile request skipped because of an invalid license is retried.
+func (lr *LicensedReconciler) SetRequeueAfter(d time.Duration) *LicensedReconciler {
+	if d > 0 {
+		lr.requeueAfter = d
+	}
+	return lr
+}
+
+// Reconcile calls the wrapped reconciler if the license is valid. Otherwise it records an event
+// and requeues the request, so that reconciliation resumes once a valid license is installed.
+func (lr *LicensedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	licenseErr := lr.le.licenseError()
+	if licenseErr == nil {
+		return lr.r.Reconcile(ctx, req)
+	}
+	klog.V(4).Infof("Skipped reconciling %s. Reason: %v", req, licenseErr)
+
+	// Skipped requests are recorded at most once per failureEventInterval.
+	msg, count, ok := lr.events.next(fmt.Sprintf("Paused reconciliation because the license is invalid. Reason: %s", licenseErr.Error()),
+		lr.le.clock.Now(), failureEventInterval, false)
+	if ok {
+		err := lr.le.eventEmitter().Emit(func(ctx context.Context) error {
+			return lr.le.recordEventN(ctx, "license-reconcile", core.EventTypeWarning, EventReasonReconciliationPaused, msg, count)
+		})
+		if err != nil {
+			klog.Warningln(err)
+		}
+	}
+	return reconcile.Result{RequeueAfter: lr.requeueAfter}, nil
+}
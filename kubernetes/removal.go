@@ -0,0 +1,86 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// LicenseRemovalPolicy decides what happens when the license file or Secret is deleted at runtime.
+type LicenseRemovalPolicy string
+
+const (
+	// LicenseRemovalEnforce re-verifies the license immediately. This is the default.
+	LicenseRemovalEnforce LicenseRemovalPolicy = "Enforce"
+	// LicenseRemovalWarn only records an event. The license is re-verified on the next check.
+	LicenseRemovalWarn LicenseRemovalPolicy = "Warn"
+	// LicenseRemovalGrace records an event and re-verifies the license after a grace period,
+	// so that the license can be restored in the meantime.
+	LicenseRemovalGrace LicenseRemovalPolicy = "Grace"
+)
+
+// SetLicenseRemovalPolicy sets the response to the deletion of the license file or Secret.
+// grace is only used by LicenseRemovalGrace.
+func (le *LicenseEnforcer) SetLicenseRemovalPolicy(policy LicenseRemovalPolicy, grace time.Duration) {
+	le.removalPolicy = policy
+	le.removalGrace = grace
+}
+
+// handleLicenseRemoval responds to deletions reported on removed by scheduling a
+// re-verification on changed, according to the removal policy.
+func (le *LicenseEnforcer) handleLicenseRemoval(ctx context.Context, removed <-chan struct{}, changed chan<- struct{}) {
+	var enforce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-enforce:
+			enforce = nil
+			notify(changed)
+		case <-removed:
+			policy := le.removalPolicy
+			if policy == "" {
+				policy = LicenseRemovalEnforce
+			}
+
+			msg := "License has been deleted"
+			switch policy {
+			case LicenseRemovalWarn:
+				msg += ", it will be verified again on the next check"
+			case LicenseRemovalGrace:
+				msg += fmt.Sprintf(", it must be restored within %s", le.removalGrace)
+				if enforce == nil {
+					enforce = time.After(le.removalGrace)
+				}
+			default:
+				notify(changed)
+			}
+			klog.Warningln(msg)
+			err := le.eventEmitter().Emit(func(ctx context.Context) error {
+				return le.recordEvent(ctx, "license-removed", core.EventTypeWarning, EventReasonLicenseRemoved, msg)
+			})
+			if err != nil {
+				klog.Warningln(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	RevocationListKindConfigMap = "ConfigMap"
+	RevocationListKindSecret    = "Secret"
+	// RevocationListKindIssuer downloads the CRL from the license issuer configured using SetIssuer.
+	RevocationListKindIssuer = "Issuer"
+
+	// RevocationListKey is the default data key holding the PEM encoded CRL
+	RevocationListKey = "ca.crl"
+
+	// DefaultRevocationListRefreshInterval is how often the CRL is downloaded from the license issuer.
+	DefaultRevocationListRefreshInterval = 6 * time.Hour
+)
+
+// RevocationListSource points to a ConfigMap or Secret carrying a CRL signed by the license CA,
+// which is used by air-gapped clusters that can't reach the issuer to check for revoked licenses,
+// or to the license issuer.
+type RevocationListSource struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key,omitempty"`
+	// RefreshInterval is how often the CRL is downloaded from the license issuer.
+	// Defaults to DefaultRevocationListRefreshInterval.
+	RefreshInterval metav1.Duration `json:"refreshInterval,omitempty"`
+	// CacheFile stores the newest CRL seen, so that it is used after a restart while the issuer
+	// is unreachable or the ConfigMap or Secret can't be read, and an older CRL is never accepted again.
+	CacheFile string `json:"cacheFile,omitempty"`
+	// Policy decides what happens when the revocation list is stale or can't be read.
+	// Defaults to FailOpen.
+	Policy verifier.RevocationPolicy `json:"policy,omitempty"`
+}
+
+// revocationListCache holds the newest CRL seen and when it was last downloaded from the license issuer.
+// A CRL with a lower CRL number or an earlier ThisUpdate time is never accepted instead.
+type revocationListCache struct {
+	mu      sync.Mutex
+	list    *x509.RevocationList
+	fetched time.Time
+}
+
+// SetRevocationListSource configures the enforcer to reject licenses listed in the given revocation list.
+func (le *LicenseEnforcer) SetRevocationListSource(src *RevocationListSource) {
+	le.revocation = src
+}
+
+func (le *LicenseEnforcer) loadRevocationList(ctx context.Context, cas []*x509.Certificate) (*x509.RevocationList, error) {
+	if le.revocation.Kind == RevocationListKindIssuer {
+		return le.fetchRevocationList(cas)
+	}
+	crl, data, err := le.readRevocationList(ctx, cas)
+
+	c := &le.crl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	le.loadCachedRevocationList(cas)
+	if err != nil {
+		// a list that was deleted or corrupted must not un-revoke the licenses it listed
+		if c.list != nil {
+			klog.Warningf("Failed to read license revocation list, using the newest list seen. Reason: %v", err)
+			return c.list, nil
+		}
+		return nil, err
+	}
+	return le.acceptRevocationList(crl, data), nil
+}
+
+// readRevocationList reads the CRL from the configured ConfigMap or Secret.
+func (le *LicenseEnforcer) readRevocationList(ctx context.Context, cas []*x509.Certificate) (*x509.RevocationList, []byte, error) {
+	src := le.revocation
+	key := src.Key
+	if key == "" {
+		key = RevocationListKey
+	}
+
+	var data []byte
+	switch src.Kind {
+	case RevocationListKindSecret:
+		s, err := le.kc.CoreV1().Secrets(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read license revocation list")
+		}
+		data = s.Data[key]
+	case RevocationListKindConfigMap, "":
+		cm, err := le.kc.CoreV1().ConfigMaps(src.Namespace).Get(ctx, src.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read license revocation list")
+		}
+		if v, ok := cm.BinaryData[key]; ok {
+			data = v
+		} else {
+			data = []byte(cm.Data[key])
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown license revocation list source kind %q", src.Kind)
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("license revocation list %s %s/%s is missing key %s", src.Kind, src.Namespace, src.Name, key)
+	}
+	crl, err := verifier.ParseRevocationList(data, cas...)
+	return crl, data, err
+}
+
+// fetchRevocationList returns the cached CRL of the license issuer and downloads it again once the
+// refresh interval has passed. If the download fails, the cached CRL is used until it becomes stale.
+func (le *LicenseEnforcer) fetchRevocationList(cas []*x509.Certificate) (*x509.RevocationList, error) {
+	src := le.revocation
+	c := &le.crl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the cached list is used as a fallback, but downloaded again immediately
+	le.loadCachedRevocationList(cas)
+	refresh := src.RefreshInterval.Duration
+	if refresh <= 0 {
+		refresh = DefaultRevocationListRefreshInterval
+	}
+	now := le.clock.Now()
+	if c.list != nil && !c.fetched.IsZero() && now.Sub(c.fetched) < refresh {
+		return c.list, nil
+	}
+
+	crl, data, err := le.downloadRevocationList(cas)
+	if err != nil {
+		if c.list != nil {
+			klog.Warningf("Failed to download license revocation list, using the cached list. Reason: %v", err)
+			return c.list, nil
+		}
+		return nil, err
+	}
+	c.fetched = now
+	return le.acceptRevocationList(crl, data), nil
+}
+
+// loadCachedRevocationList restores the newest CRL seen from the cache file after a restart.
+// le.crl.mu must be held.
+func (le *LicenseEnforcer) loadCachedRevocationList(cas []*x509.Certificate) {
+	c := &le.crl
+	if c.list != nil || le.revocation.CacheFile == "" {
+		return
+	}
+	if data, err := os.ReadFile(le.revocation.CacheFile); err == nil {
+		c.list, _ = verifier.ParseRevocationList(data, cas...)
+	}
+}
+
+// acceptRevocationList returns crl and records it as the newest CRL seen, unless it is older than the
+// newest CRL seen, in which case the newest CRL is returned instead. le.crl.mu must be held.
+func (le *LicenseEnforcer) acceptRevocationList(crl *x509.RevocationList, data []byte) *x509.RevocationList {
+	c := &le.crl
+	if err := verifier.CheckRevocationListOrder(crl, c.list); err != nil {
+		klog.Warningf("Ignoring license revocation list, using the newest list seen. Reason: %v", err)
+		return c.list
+	}
+	if c.list != nil && bytes.Equal(c.list.Raw, crl.Raw) {
+		return c.list
+	}
+	c.list = crl
+	if src := le.revocation; src.CacheFile != "" {
+		if err := os.WriteFile(src.CacheFile, data, 0o644); err != nil {
+			klog.Warningf("Failed to cache license revocation list in %s. Reason: %v", src.CacheFile, err)
+		}
+	}
+	return crl
+}
+
+func (le *LicenseEnforcer) downloadRevocationList(cas []*x509.Certificate) (*x509.RevocationList, []byte, error) {
+	c, err := le.newIssuerClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := c.GetRevocationList()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to download license revocation list")
+	}
+	crl, err := verifier.ParseRevocationList(data, cas...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crl, data, nil
+}
+
+func (le *LicenseEnforcer) checkRevocation(ctx context.Context, license *v1alpha1.License, cas []*x509.Certificate) error {
+	if le.revocation == nil {
+		return nil
+	}
+
+	crl, err := le.loadRevocationList(ctx, cas)
+	if err == nil {
+		err = verifier.CheckRevocation(license, crl, le.clock.Now())
+		if err == nil || !errors.Is(err, verifier.ErrRevocationListStale) {
+			return err
+		}
+	}
+
+	if le.revocation.Policy == verifier.RevocationPolicyFailClosed {
+		license.Status = v1alpha1.LicenseUnknown
+		license.Reason = err.Error()
+		return err
+	}
+	klog.Warningf("Skipping license revocation check. Reason: %v", err)
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+	"time"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestRevocationListRollback(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	data, err := issuer.IssueProfile("8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11", licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := info.ParseCertificates(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	crl := func(number int64, thisUpdate time.Time, revoked ...[]byte) string {
+		data, err := issuer.RevocationList(number, thisUpdate, revoked...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	cm := &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "license-crl"}}
+	kc := fake.NewSimpleClientset(cm)
+	cacheFile := filepath.Join(t.TempDir(), "ca.crl")
+	newEnforcer := func() *LicenseEnforcer {
+		le := &LicenseEnforcer{kc: kc, clock: clocktesting.NewFakeClock(now)}
+		le.SetRevocationListSource(&RevocationListSource{
+			Namespace: cm.Namespace,
+			Name:      cm.Name,
+			CacheFile: cacheFile,
+			Policy:    verifier.RevocationPolicyFailClosed,
+		})
+		return le
+	}
+
+	tests := []struct {
+		name    string
+		crl     string
+		restart bool
+		revoked bool
+	}{
+		{"revoked", crl(2, now.Add(-time.Hour), data), false, true},
+		{"lower number", crl(1, now), false, true},
+		{"same number issued earlier", crl(2, now.Add(-2*time.Hour)), false, true},
+		{"lower number after restart", crl(1, now), true, true},
+		{"higher number", crl(3, now), false, false},
+		{"same number issued later", crl(3, now.Add(time.Minute), data), false, true},
+	}
+	le := newEnforcer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm.Data = map[string]string{RevocationListKey: tt.crl}
+			if _, err := kc.CoreV1().ConfigMaps(cm.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.restart {
+				le = newEnforcer()
+			}
+			license := v1alpha1.License{ID: certs[0].SerialNumber.String(), Status: v1alpha1.LicenseActive}
+			err := le.checkRevocation(context.TODO(), &license, []*x509.Certificate{issuer.CACert})
+			if revoked := license.Status == v1alpha1.LicenseCanceled; revoked != tt.revoked {
+				t.Errorf("checkRevocation() error = %v, revoked = %v, want %v", err, revoked, tt.revoked)
+			}
+		})
+	}
+}
+
+func TestRevocationListDeleted(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	data, err := issuer.IssueProfile("8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11", licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := info.ParseCertificates(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := issuer.RevocationList(1, time.Now(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "license-crl"},
+		Data:       map[string]string{RevocationListKey: string(crl)},
+	}
+	kc := fake.NewSimpleClientset(cm)
+	cacheFile := filepath.Join(t.TempDir(), "ca.crl")
+	newEnforcer := func() *LicenseEnforcer {
+		le := &LicenseEnforcer{kc: kc, clock: clocktesting.NewFakeClock(time.Now())}
+		le.SetRevocationListSource(&RevocationListSource{
+			Namespace: cm.Namespace,
+			Name:      cm.Name,
+			CacheFile: cacheFile,
+			Policy:    verifier.RevocationPolicyFailOpen,
+		})
+		return le
+	}
+	checkRevoked := func(le *LicenseEnforcer) {
+		t.Helper()
+		license := v1alpha1.License{ID: certs[0].SerialNumber.String(), Status: v1alpha1.LicenseActive}
+		if err := le.checkRevocation(context.TODO(), &license, []*x509.Certificate{issuer.CACert}); err == nil || license.Status != v1alpha1.LicenseCanceled {
+			t.Errorf("checkRevocation() error = %v, status = %s, want the license revoked", err, license.Status)
+		}
+	}
+
+	le := newEnforcer()
+	checkRevoked(le)
+
+	if err := kc.CoreV1().ConfigMaps(cm.Namespace).Delete(context.TODO(), cm.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkRevoked(le)
+	// the cache file remembers the list after a restart
+	checkRevoked(newEnforcer())
+
+	cm.Data = map[string]string{RevocationListKey: "corrupted"}
+	if _, err := kc.CoreV1().ConfigMaps(cm.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkRevoked(le)
+}
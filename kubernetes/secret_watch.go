@@ -0,0 +1,85 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// LicenseSecretReference points to a Secret holding the license.
+type LicenseSecretReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Key defaults to key.txt
+	Key string `json:"key,omitempty"`
+}
+
+func (r LicenseSecretReference) source(kc kubernetes.Interface) SecretSource {
+	return SecretSource{
+		Client:    kc,
+		Namespace: r.Namespace,
+		Name:      r.Name,
+		Key:       r.Key,
+	}
+}
+
+// SetLicenseSecret reads the license from a Secret instead of the license file, so that
+// products don't have to mount the license. The license is re-verified whenever the Secret changes.
+func (le *LicenseEnforcer) SetLicenseSecret(ref *LicenseSecretReference) {
+	le.licenseSecret = ref
+}
+
+// watchLicenseSecret notifies changed whenever the license Secret is created or updated
+// and removed when it is deleted.
+func (le *LicenseEnforcer) watchLicenseSecret(ctx context.Context, changed, removed chan<- struct{}) {
+	ref := le.licenseSecret
+	factory := informers.NewSharedInformerFactoryWithOptions(le.kc, 0,
+		informers.WithNamespace(ref.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", ref.Name).String()
+		}),
+	)
+	informer := factory.Core().V1().Secrets().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			// the license is verified on startup anyway
+			if !isInInitialList {
+				notify(changed)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			klog.V(4).Infof("License secret %s/%s changed", ref.Namespace, ref.Name)
+			notify(changed)
+		},
+		DeleteFunc: func(obj interface{}) {
+			klog.V(4).Infof("License secret %s/%s deleted", ref.Namespace, ref.Name)
+			notify(removed)
+		},
+	})
+	if err != nil {
+		klog.Warningf("Failed to watch license secret %s/%s. Reason: %v", ref.Namespace, ref.Name, err)
+		return
+	}
+	factory.Start(ctx.Done())
+}
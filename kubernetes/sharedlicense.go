@@ -0,0 +1,157 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	clientretry "k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SharedLicenseNamespace is the default namespace of the shared license Secret
+	SharedLicenseNamespace = metav1.NamespaceSystem
+	// SharedLicenseName is the default name of the shared license Secret
+	SharedLicenseName = "appscode-license"
+)
+
+// SharedLicense points to a Secret shared by all AppsCode products in a cluster.
+// Every verified license is stored in it under its license ID, so that other products
+// can pick up a license without contacting the license-proxyserver or the license issuer.
+type SharedLicense struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+func (s SharedLicense) namespace() string {
+	if s.Namespace == "" {
+		return SharedLicenseNamespace
+	}
+	return s.Namespace
+}
+
+func (s SharedLicense) name() string {
+	if s.Name == "" {
+		return SharedLicenseName
+	}
+	return s.Name
+}
+
+// SetSharedLicense configures the enforcer to read licenses from and publish verified licenses
+// to the shared license Secret.
+func (le *LicenseEnforcer) SetSharedLicense(s *SharedLicense) {
+	le.sharedLicense = s
+}
+
+// readSharedLicense returns a license from the shared license Secret that is valid for this product.
+func (le *LicenseEnforcer) readSharedLicense(ctx context.Context) ([]byte, error) {
+	s, err := le.kc.CoreV1().Secrets(le.sharedLicense.namespace()).Get(ctx, le.sharedLicense.name(), metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read shared license")
+	}
+	keys := make([]string, 0, len(s.Data))
+	for k := range s.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	opts := le.opts
+	for _, k := range keys {
+		opts.License = s.Data[k]
+		if _, err := verifier.CheckLicense(opts); err == nil {
+			return s.Data[k], nil
+		}
+	}
+	return nil, errors.Errorf("shared license secret %s/%s has no valid license", s.Namespace, s.Name)
+}
+
+// shareLicense stores a verified license in the shared license Secret and drops expired licenses from it.
+// Only the leader writes, and failures only produce a warning.
+func (le *LicenseEnforcer) shareLicense(ctx context.Context, license v1alpha1.License) {
+	if le.sharedLicense == nil || !le.IsLeader() || license.ID == "" || len(license.Data) == 0 {
+		return
+	}
+	if err := StoreSharedLicense(ctx, le.kc, *le.sharedLicense, license); err != nil {
+		klog.Warningf("Failed to share license %s. Reason: %v", license.ID, err)
+	}
+}
+
+// StoreSharedLicense stores a license in the shared license Secret under its license ID and drops expired licenses from it.
+// The Secret is created if it does not exist.
+func StoreSharedLicense(ctx context.Context, kc kubernetes.Interface, shared SharedLicense, license v1alpha1.License) error {
+	if license.ID == "" {
+		return errors.New("license has no ID")
+	}
+	ns, name := shared.namespace(), shared.name()
+	return clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		s, err := kc.CoreV1().Secrets(ns).Get(ctx, name, metav1.GetOptions{})
+		if kerr.IsNotFound(err) {
+			s = &core.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: ns,
+					Name:      name,
+				},
+				Type: core.SecretTypeOpaque,
+				Data: map[string][]byte{
+					license.ID: license.Data,
+				},
+			}
+			_, err = kc.CoreV1().Secrets(ns).Create(ctx, s, metav1.CreateOptions{})
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		changed := pruneSharedLicenses(s.Data, time.Now())
+		if string(s.Data[license.ID]) != string(license.Data) {
+			if s.Data == nil {
+				s.Data = map[string][]byte{}
+			}
+			s.Data[license.ID] = license.Data
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		_, err = kc.CoreV1().Secrets(ns).Update(ctx, s, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// pruneSharedLicenses removes undecodable and expired licenses and reports whether any were removed.
+func pruneSharedLicenses(data map[string][]byte, now time.Time) bool {
+	var pruned bool
+	for k, v := range data {
+		l, err := verifier.DecodeLicense(v)
+		if err != nil || (l.NotAfter != nil && l.NotAfter.Time.Before(now)) {
+			delete(data, k)
+			pruned = true
+		}
+	}
+	return pruned
+}
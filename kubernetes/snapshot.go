@@ -0,0 +1,29 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// Snapshot verifies the license and records the result, so that it can be signed and archived.
+func (le *LicenseEnforcer) Snapshot(ctx context.Context) verifier.Snapshot {
+	license, _ := le.LoadLicenseWithContext(ctx)
+	return verifier.NewSnapshot(license, le.opts.ClusterUID, le.clock.Now())
+}
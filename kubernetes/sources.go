@@ -0,0 +1,201 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/client"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// LicenseSecretKey is the key of the license in license Secrets.
+const LicenseSecretKey = "key.txt"
+
+// LicenseSource loads a license from some location.
+type LicenseSource interface {
+	// String returns a human readable name of the source, used in logs and errors.
+	String() string
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileSource reads the license from a file.
+type FileSource struct {
+	Path string
+}
+
+var _ LicenseSource = FileSource{}
+
+func (s FileSource) String() string {
+	return "file:" + s.Path
+}
+
+func (s FileSource) Load(_ context.Context) ([]byte, error) {
+	return os.ReadFile(s.Path)
+}
+
+// SecretSource reads the license from a Secret.
+type SecretSource struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	// Key defaults to key.txt
+	Key string
+}
+
+var _ LicenseSource = SecretSource{}
+
+func (s SecretSource) String() string {
+	return fmt.Sprintf("secret:%s/%s", s.Namespace, s.Name)
+}
+
+func (s SecretSource) Load(ctx context.Context) ([]byte, error) {
+	secret, err := s.Client.CoreV1().Secrets(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	key := s.Key
+	if key == "" {
+		key = LicenseSecretKey
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s is missing key %s", s.Namespace, s.Name, key)
+	}
+	return data, nil
+}
+
+// IssuerSource acquires a license from the license issuer.
+type IssuerSource struct {
+	Client   *client.Client
+	Features []string
+}
+
+var _ LicenseSource = IssuerSource{}
+
+func (s IssuerSource) String() string {
+	return "issuer"
+}
+
+func (s IssuerSource) Load(ctx context.Context) ([]byte, error) {
+	data, _, err := s.Client.AcquireLicense(ctx, s.Features)
+	return data, err
+}
+
+// Resolution is the result of resolving a license from multiple sources.
+type Resolution struct {
+	// Source is the name of the source that provided the chosen license
+	Source  string
+	License v1alpha1.License
+	Data    []byte
+	// Errors contains the failures of the other sources, keyed by source name
+	Errors map[string]error
+}
+
+// ResolveLicense loads licenses from all the sources concurrently and picks the valid license
+// with the latest expiry. If multiple valid licenses expire at the same time, the one from the
+// source listed first wins. A license is only valid if it also has not been revoked, so that a
+// revoked license never wins over a valid license that expires earlier.
+func (le *LicenseEnforcer) ResolveLicense(ctx context.Context, sources ...LicenseSource) (*Resolution, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no license source configured")
+	}
+	if err := le.readClusterCAFingerprint(); err != nil {
+		return nil, err
+	}
+	// the trusted CAs are local to this resolution, so that the shared options are never mutated
+	opts := le.opts
+	opts.CACerts = le.loadCABundle(ctx)
+	cas := opts.TrustedCAs()
+
+	type result struct {
+		license v1alpha1.License
+		data    []byte
+		err     error
+	}
+	results := make([]result, len(sources))
+
+	var wg sync.WaitGroup
+	for i := range sources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := sources[i].Load(ctx)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			opts := opts
+			opts.License = data
+			results[i].data = data
+			results[i].license, results[i].err = verifier.CheckLicense(opts)
+			if results[i].err == nil {
+				results[i].err = le.checkRevoked(ctx, &results[i].license, cas)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	r := Resolution{
+		Errors: map[string]error{},
+	}
+	winner := -1
+	for i, res := range results {
+		if res.err != nil {
+			r.Errors[sources[i].String()] = res.err
+			continue
+		}
+		if winner == -1 || expiresAfter(res.license, results[winner].license) {
+			winner = i
+		}
+	}
+	if winner == -1 {
+		var errs []string
+		for _, s := range sources {
+			errs = append(errs, fmt.Sprintf("%s: %v", s.String(), r.Errors[s.String()]))
+		}
+		return &r, fmt.Errorf("no valid license found: %v", errs)
+	}
+
+	r.Source = sources[winner].String()
+	r.License = results[winner].license
+	r.Data = results[winner].data
+	klog.V(4).Infof("Using license %s from %s", r.License.ID, r.Source)
+	return &r, nil
+}
+
+func expiresAfter(a, b v1alpha1.License) bool {
+	if a.NotAfter == nil || b.NotAfter == nil {
+		return false
+	}
+	return a.NotAfter.After(b.NotAfter.Time)
+}
+
+// SetLicenseSources makes the enforcer resolve the license from the given sources,
+// instead of the license file and the license-proxyserver.
+func (le *LicenseEnforcer) SetLicenseSources(sources ...LicenseSource) {
+	le.sources = sources
+}
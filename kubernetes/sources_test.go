@@ -0,0 +1,86 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// dataSource is a license source serving a fixed license.
+type dataSource struct {
+	name string
+	data []byte
+}
+
+func (s dataSource) String() string                         { return s.name }
+func (s dataSource) Load(_ context.Context) ([]byte, error) { return s.data, nil }
+
+func TestResolveLicenseSkipsRevoked(t *testing.T) {
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	issuer := licensetest.NewTestIssuer(t)
+	revoked, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	crl, err := issuer.RevocationList(1, now, revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "license-crl"},
+		Data:       map[string]string{RevocationListKey: string(crl)},
+	}
+	le := &LicenseEnforcer{kc: fake.NewSimpleClientset(cm), clock: clocktesting.NewFakeClock(now)}
+	le.opts.ClusterUID = clusterUID
+	le.opts.Features = "kubedb-enterprise"
+	le.opts.CACert = issuer.CACert
+	le.SetRevocationListSource(&RevocationListSource{Namespace: cm.Namespace, Name: cm.Name})
+
+	r, err := le.ResolveLicense(context.TODO(),
+		dataSource{name: "revoked", data: revoked},
+		dataSource{name: "valid", data: valid},
+	)
+	if err != nil {
+		t.Fatalf("ResolveLicense() error = %v", err)
+	}
+	certs, err := info.ParseCertificates(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Source != "valid" || r.License.ID != certs[0].SerialNumber.String() {
+		t.Errorf("ResolveLicense() picked license %s from %s, want the license from valid", r.License.ID, r.Source)
+	}
+	if r.Errors["revoked"] == nil {
+		t.Errorf("ResolveLicense() errors = %v, want the revocation of the license from revoked", r.Errors)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/klog/v2"
+)
+
+// NewStandaloneLicenseEnforcer returns a license enforcer that does not talk to a Kubernetes cluster.
+// The license is bound to clusterUID, which can be any opaque identifier, eg. the machine id of a VM.
+// Events are only logged, and licenses can't be acquired from the license-proxyserver or read from Secrets.
+func NewStandaloneLicenseEnforcer(clusterUID, licenseFile string) (*LicenseEnforcer, error) {
+	le, err := NewLicenseEnforcer(nil, licenseFile)
+	if err != nil {
+		return le, err
+	}
+	if clusterUID == "" {
+		return le, errors.New("missing cluster UID for standalone license verification")
+	}
+	le.SetClusterUID(clusterUID)
+	return le, nil
+}
+
+// SetClusterUID sets the identifier the license must be issued for, instead of reading the cluster UID.
+func (le *LicenseEnforcer) SetClusterUID(uid string) {
+	le.opts.ClusterUID = uid
+}
+
+// standalone returns true if the enforcer runs without a Kubernetes cluster.
+func (le *LicenseEnforcer) standalone() bool {
+	return le.config == nil && le.kc == nil
+}
+
+// VerifyStandaloneLicense verifies whether the provided license was issued for clusterUID without
+// contacting a Kubernetes cluster. It is meant for CLI tools, agents on VMs and tests.
+func VerifyStandaloneLicense(ctx context.Context, clusterUID, licenseFile string) (v1alpha1.License, error) {
+	if info.SkipLicenseVerification() {
+		klog.Infoln("License verification skipped")
+		return v1alpha1.License{}, nil
+	}
+
+	le, err := NewStandaloneLicenseEnforcer(clusterUID, licenseFile)
+	if err != nil {
+		return verifier.BadLicense(err)
+	}
+	if err = le.acquireLicense(ctx); err != nil {
+		return verifier.BadLicense(err)
+	}
+	return le.verify(ctx)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// LicenseStatusFieldOwner is the default field manager used to apply the license status.
+const LicenseStatusFieldOwner = "license-verifier"
+
+// SetLicenseStatus mirrors the license status into the status.license field of obj using server side apply.
+// Only the status.license field is owned by fieldOwner, so the rest of the status written by the operator is left intact.
+// The status subresource must be enabled for the custom resource.
+func SetLicenseStatus(ctx context.Context, kc client.Client, obj client.Object, status v1alpha1.LicenseSummary, fieldOwner string) error {
+	if fieldOwner == "" {
+		fieldOwner = LicenseStatusFieldOwner
+	}
+	gvk, err := apiutil.GVKForObject(obj, kc.Scheme())
+	if err != nil {
+		return err
+	}
+
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	if err = unstructured.SetNestedMap(u.Object, data, "status", "license"); err != nil {
+		return err
+	}
+	return kc.Status().Patch(ctx, u, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership)
+}
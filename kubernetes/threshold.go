@@ -0,0 +1,163 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultFailureThresholdConsecutiveFailures = 3
+	DefaultFailureThresholdInitialBackoff      = 10 * time.Second
+	DefaultFailureThresholdMaxBackoff          = 5 * time.Minute
+)
+
+// FailureThreshold keeps transient failures to verify the license, eg. api server errors while
+// reading the kube-system namespace, from being enforced immediately. Such failures are retried with
+// exponential backoff and only enforced after ConsecutiveFailures failed attempts spanning at least
+// Duration. A license that was verified and rejected is enforced immediately.
+//
+// The enforcer uses the default threshold unless configured otherwise. Set ConsecutiveFailures
+// to 1 to enforce transient failures immediately.
+//
+// The failure policies apply in order: a transient failure within the FailureThreshold is retried and
+// never counts against the ErrorBudget. Failures that exceed the threshold and rejected licenses are
+// recorded in the ErrorBudget, which may tolerate them. Only failures that exhaust the budget are
+// subject to FlapDamping, which may still keep the previous status.
+type FailureThreshold struct {
+	// ConsecutiveFailures defaults to 3.
+	ConsecutiveFailures int             `json:"consecutiveFailures,omitempty"`
+	Duration            metav1.Duration `json:"duration,omitempty"`
+	// InitialBackoff is the delay before the first retry, doubled for every retry up to MaxBackoff.
+	// Defaults to 10s and 5m.
+	InitialBackoff metav1.Duration `json:"initialBackoff,omitempty"`
+	MaxBackoff     metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+func (t FailureThreshold) Validate() error {
+	if t.ConsecutiveFailures < 0 {
+		return fmt.Errorf("failureThreshold.consecutiveFailures must not be negative, found %d", t.ConsecutiveFailures)
+	}
+	if t.Duration.Duration < 0 {
+		return fmt.Errorf("failureThreshold.duration must not be negative, found %s", t.Duration.Duration)
+	}
+	if t.InitialBackoff.Duration < 0 || t.MaxBackoff.Duration < 0 {
+		return fmt.Errorf("failureThreshold.initialBackoff and failureThreshold.maxBackoff must not be negative")
+	}
+	if t.MaxBackoff.Duration > 0 && t.MaxBackoff.Duration < t.InitialBackoff.Duration {
+		return fmt.Errorf("failureThreshold.maxBackoff must not be less than failureThreshold.initialBackoff")
+	}
+	return nil
+}
+
+// failureStreak tracks consecutive transient verification failures.
+type failureStreak struct {
+	opts FailureThreshold
+
+	mu      sync.Mutex
+	count   int
+	since   time.Time
+	backoff time.Duration
+}
+
+// observe records a transient failure and returns the delay before the next attempt.
+// ok is false once the threshold has been reached.
+func (s *failureStreak) observe(now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maxBackoff := s.opts.MaxBackoff.Duration
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultFailureThresholdMaxBackoff
+	}
+	if s.count == 0 {
+		s.since = now
+		s.backoff = s.opts.InitialBackoff.Duration
+		if s.backoff <= 0 {
+			s.backoff = DefaultFailureThresholdInitialBackoff
+		}
+	} else {
+		s.backoff *= 2
+	}
+	if s.backoff > maxBackoff {
+		s.backoff = maxBackoff
+	}
+	s.count++
+
+	n := s.opts.ConsecutiveFailures
+	if n <= 0 {
+		n = DefaultFailureThresholdConsecutiveFailures
+	}
+	if s.count >= n && now.Sub(s.since) >= s.opts.Duration.Duration {
+		return 0, false
+	}
+	return s.backoff, true
+}
+
+func (s *failureStreak) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count = 0
+}
+
+// SetFailureThreshold makes the enforcer retry transient verification failures with exponential
+// backoff and only enforce them once they exceed the threshold. nil restores the default threshold.
+func (le *LicenseEnforcer) SetFailureThreshold(t *FailureThreshold) {
+	le.failureStreak = &failureStreak{}
+	if t != nil {
+		le.failureStreak.opts = *t
+	}
+}
+
+// transientFailure returns true if the license could not be verified, as opposed to a license that
+// was verified and rejected.
+func transientFailure(license v1alpha1.License, err error) bool {
+	return err != nil && license.Status == v1alpha1.LicenseUnknown && !errors.Is(err, verifier.ErrMalformedLicense)
+}
+
+// withinFailureThreshold records the outcome of a verification attempt and returns the delay after
+// which a tolerated failure is retried. ok is false if the failure must be enforced.
+func (le *LicenseEnforcer) withinFailureThreshold(license v1alpha1.License, err error) (delay time.Duration, ok bool) {
+	if le.failureStreak == nil {
+		return 0, false
+	}
+	if !transientFailure(license, err) {
+		le.failureStreak.reset()
+		return 0, false
+	}
+	return le.failureStreak.observe(le.clock.Now())
+}
+
+// retryAfter triggers another verification attempt after the delay, unless ctx is cancelled.
+func (le *LicenseEnforcer) retryAfter(ctx context.Context, delay time.Duration, trigger chan<- struct{}) {
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-le.clock.After(delay):
+			notify(trigger)
+		}
+	}()
+}
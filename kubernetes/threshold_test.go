@@ -0,0 +1,122 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestFailureStreakObserve(t *testing.T) {
+	type attempt struct {
+		after     time.Duration
+		wantDelay time.Duration
+		wantOK    bool
+	}
+	tests := []struct {
+		name     string
+		opts     FailureThreshold
+		attempts []attempt
+	}{
+		{
+			name: "defaults",
+			attempts: []attempt{
+				{0, 10 * time.Second, true},
+				{10 * time.Second, 20 * time.Second, true},
+				{20 * time.Second, 0, false},
+			},
+		},
+		{
+			name: "enforce immediately",
+			opts: FailureThreshold{ConsecutiveFailures: 1},
+			attempts: []attempt{
+				{0, 0, false},
+			},
+		},
+		{
+			name: "backoff capped",
+			opts: FailureThreshold{
+				ConsecutiveFailures: 5,
+				InitialBackoff:      metav1.Duration{Duration: time.Minute},
+				MaxBackoff:          metav1.Duration{Duration: 3 * time.Minute},
+			},
+			attempts: []attempt{
+				{0, time.Minute, true},
+				{time.Minute, 2 * time.Minute, true},
+				{2 * time.Minute, 3 * time.Minute, true},
+				{3 * time.Minute, 3 * time.Minute, true},
+				{3 * time.Minute, 0, false},
+			},
+		},
+		{
+			name: "duration not elapsed",
+			opts: FailureThreshold{
+				ConsecutiveFailures: 2,
+				Duration:            metav1.Duration{Duration: time.Hour},
+			},
+			attempts: []attempt{
+				{0, 10 * time.Second, true},
+				{10 * time.Second, 20 * time.Second, true},
+				{20 * time.Second, 40 * time.Second, true},
+				{time.Hour, 0, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &failureStreak{opts: tt.opts}
+			now := time.Now()
+			for i, a := range tt.attempts {
+				now = now.Add(a.after)
+				delay, ok := s.observe(now)
+				if delay != a.wantDelay || ok != a.wantOK {
+					t.Errorf("attempt %d: observe() = %s, %v, want %s, %v", i, delay, ok, a.wantDelay, a.wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestWithinFailureThreshold(t *testing.T) {
+	le := &LicenseEnforcer{clock: clocktesting.NewFakeClock(time.Now())}
+	le.SetFailureThreshold(nil)
+
+	errTransient := errors.New("issuer unavailable")
+	unknown := v1alpha1.License{Status: v1alpha1.LicenseUnknown}
+	if _, ok := le.withinFailureThreshold(unknown, errTransient); !ok {
+		t.Errorf("withinFailureThreshold() with the default threshold = false, want true")
+	}
+	invalid := v1alpha1.License{Status: v1alpha1.LicenseInvalid}
+	if _, ok := le.withinFailureThreshold(invalid, errTransient); ok {
+		t.Errorf("withinFailureThreshold() for a rejected license = true, want false")
+	}
+	// the rejected license reset the streak
+	for i := 0; i < DefaultFailureThresholdConsecutiveFailures-1; i++ {
+		if _, ok := le.withinFailureThreshold(unknown, errTransient); !ok {
+			t.Errorf("attempt %d: withinFailureThreshold() = false, want true", i)
+		}
+	}
+	if _, ok := le.withinFailureThreshold(unknown, errTransient); ok {
+		t.Errorf("withinFailureThreshold() after %d failures = true, want false", DefaultFailureThresholdConsecutiveFailures)
+	}
+}
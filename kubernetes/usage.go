@@ -0,0 +1,166 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// EnvUsageReporting disables usage reporting if set to false.
+	EnvUsageReporting = "LICENSE_USAGE_REPORTING"
+
+	// DefaultUsageReportInterval is how often usage is reported to the license issuer.
+	DefaultUsageReportInterval = 24 * time.Hour
+	// maxUsageReportInterval bounds the backoff while the license issuer is unreachable.
+	maxUsageReportInterval = 7 * 24 * time.Hour
+)
+
+// UsageReporting configures the periodic report of anonymized usage to the license issuer.
+// The report contains a hash of the cluster UID, the product features, the node count, unless
+// nodes can't be listed, and the license ID. Reporting is paused while the license issuer is unreachable, eg. in air-gapped clusters.
+type UsageReporting struct {
+	// Disabled opts out of usage reporting.
+	Disabled bool `json:"disabled,omitempty"`
+	// Interval between reports. Defaults to DefaultUsageReportInterval.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// ClusterProfile adds the cluster profile collected with the given options to the report,
+	// so that the license issuer can detect cloned clusters. The profile is not sent if nil.
+	ClusterProfile *ClusterProfileOptions `json:"clusterProfile,omitempty"`
+}
+
+func (u UsageReporting) Validate() error {
+	if d := u.Interval.Duration; d != 0 && d < time.Hour {
+		return fmt.Errorf("usageReporting.interval must be at least 1h, found %s", d)
+	}
+	return nil
+}
+
+// SetUsageReporting configures usage reporting. Usage is reported by default.
+func (le *LicenseEnforcer) SetUsageReporting(u UsageReporting) {
+	le.usageReporting = u
+}
+
+func (le *LicenseEnforcer) reportUsagePeriodically(ctx context.Context) {
+	if le.usageReporting.Disabled || le.standalone() {
+		return
+	}
+	interval := le.usageReporting.Interval.Duration
+	if interval <= 0 {
+		interval = DefaultUsageReportInterval
+	}
+
+	wait := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-le.clock.After(wait):
+		}
+		if !le.IsLeader() {
+			wait = interval
+			continue
+		}
+
+		err := le.reportUsage(ctx)
+		switch {
+		case err == nil:
+			wait = interval
+		case unreachable(err):
+			if wait == interval {
+				klog.Infof("License issuer is unreachable, the cluster appears to be air-gapped. Pausing usage reporting. Reason: %v", err)
+			}
+			wait = min(2*wait, maxUsageReportInterval)
+		default:
+			klog.Warningf("Failed to report usage to the license issuer. Reason: %v", err)
+			wait = interval
+		}
+	}
+}
+
+func (le *LicenseEnforcer) reportUsage(ctx context.Context) error {
+	// the report is skipped until the cluster UID is known, so that the hash identifies the cluster
+	uid, err := le.cachedClusterUID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to read cluster uid")
+	}
+	le.verifyMu.Lock()
+	features := le.opts.Features
+	le.verifyMu.Unlock()
+
+	h := sha256.Sum256([]byte(uid))
+	report := client.UsageReport{
+		ClusterHash: hex.EncodeToString(h[:]),
+		Features:    info.ParseFeatures(features),
+	}
+	if len(report.Features) > 0 {
+		report.Product = report.Features[0]
+	}
+	if result, ok := le.LastVerification(); ok && result.Err == nil {
+		report.LicenseID = result.License.ID
+	}
+	if le.kc != nil {
+		nodes, err := le.countNodes(ctx)
+		switch {
+		case kerr.IsForbidden(err) || kerr.IsNotFound(err):
+			// the node count is optional, report the rest without it
+			klog.V(4).Infof("Omitting the node count from the usage report. Reason: %v", err)
+		case err != nil:
+			return err
+		default:
+			report.Nodes = nodes
+		}
+		if opts := le.usageReporting.ClusterProfile; opts != nil {
+			profile, err := CollectClusterProfile(ctx, le.kc, *opts)
+			switch {
+			case kerr.IsForbidden(err) || kerr.IsNotFound(err):
+				klog.V(4).Infof("Omitting the cluster profile from the usage report. Reason: %v", err)
+			case err != nil:
+				return err
+			default:
+				report.Profile = profile
+			}
+		}
+	}
+
+	c, err := le.newIssuerClient()
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(c.ReportUsage(report), "failed to report usage")
+}
+
+// unreachable returns true if err was caused by the network, rather than a response of the license issuer.
+func unreachable(err error) bool {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	return errors.As(err, &netErr) || errors.As(err, &dnsErr) || errors.As(err, &opErr)
+}
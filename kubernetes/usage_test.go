@@ -0,0 +1,157 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/client"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestReportUsage(t *testing.T) {
+	tests := []struct {
+		name      string
+		listErr   error
+		wantErr   bool
+		wantNodes int64
+	}{
+		{name: "nodes counted", wantNodes: 1},
+		{name: "nodes forbidden", listErr: kerr.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("denied"))},
+		{name: "nodes not found", listErr: kerr.NewNotFound(schema.GroupResource{Resource: "nodes"}, "")},
+		{name: "api server unavailable", listErr: kerr.NewServiceUnavailable("unavailable"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []client.UsageReport
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var report client.UsageReport
+				if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+					t.Errorf("failed to decode usage report: %v", err)
+				}
+				reports = append(reports, report)
+			}))
+			defer srv.Close()
+
+			kc := fake.NewSimpleClientset(&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+			if tt.listErr != nil {
+				kc.PrependReactor("list", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.listErr
+				})
+			}
+			le := &LicenseEnforcer{kc: kc, issuer: &IssuerConfig{URL: srv.URL}}
+			le.opts.ClusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+			le.opts.Features = "kubedb-enterprise"
+
+			err := le.reportUsage(context.TODO())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reportUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(reports) != 0 {
+					t.Errorf("reportUsage() sent %d reports, want none", len(reports))
+				}
+				return
+			}
+			if len(reports) != 1 || reports[0].Nodes != tt.wantNodes || reports[0].Product != "kubedb-enterprise" {
+				t.Errorf("reportUsage() sent %+v, want one report with %d nodes", reports, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestReportUsageClusterProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *ClusterProfileOptions
+		wantProfile bool
+		wantNodes   int
+	}{
+		{name: "opted out"},
+		{name: "cluster uid only", opts: &ClusterProfileOptions{}, wantProfile: true},
+		{name: "node uids", opts: &ClusterProfileOptions{NodeUIDs: true}, wantProfile: true, wantNodes: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []client.UsageReport
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var report client.UsageReport
+				if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+					t.Errorf("failed to decode usage report: %v", err)
+				}
+				reports = append(reports, report)
+			}))
+			defer srv.Close()
+
+			kc := fake.NewSimpleClientset(
+				&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem, UID: "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"}},
+				&core.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "node-uid-1"}},
+			)
+			le := &LicenseEnforcer{kc: kc, issuer: &IssuerConfig{URL: srv.URL}}
+			le.opts.ClusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+			le.SetUsageReporting(UsageReporting{ClusterProfile: tt.opts})
+
+			if err := le.reportUsage(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			if len(reports) != 1 {
+				t.Fatalf("reportUsage() sent %d reports, want one", len(reports))
+			}
+			profile := reports[0].Profile
+			if (profile != nil) != tt.wantProfile {
+				t.Fatalf("reportUsage() profile = %+v, want profile %v", profile, tt.wantProfile)
+			}
+			if profile != nil && (profile.ClusterUID != le.opts.ClusterUID || profile.Hash == "" || len(profile.NodeUIDs) != tt.wantNodes) {
+				t.Errorf("reportUsage() profile = %+v, want %d node UIDs", profile, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestReportUsageUnknownClusterUID(t *testing.T) {
+	var reports int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reports++
+	}))
+	defer srv.Close()
+
+	le := &LicenseEnforcer{
+		kc:     fake.NewSimpleClientset(),
+		issuer: &IssuerConfig{URL: srv.URL},
+		clusterIDProvider: ClusterIDProviderFunc(func(ctx context.Context) (string, error) {
+			return "", errors.New("api server unavailable")
+		}),
+	}
+	if err := le.reportUsage(context.TODO()); err == nil {
+		t.Fatal("reportUsage() error = nil, want the cluster UID error")
+	}
+	if reports != 0 || le.opts.ClusterUID != "" {
+		t.Errorf("reportUsage() sent %d reports without a cluster UID, want none", reports)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	core_util "kmodules.xyz/client-go/core/v1"
+	"kmodules.xyz/client-go/meta"
+)
+
+// VerificationResult is the outcome of a license verification.
+type VerificationResult struct {
+	License    v1alpha1.License
+	ClusterUID string
+	Err        error
+	Time       time.Time
+	Duration   time.Duration
+}
+
+// verificationState holds the result of the last license verification and the result
+// the enforcer acts on.
+type verificationState struct {
+	mu     sync.RWMutex
+	last   VerificationResult
+	exists bool
+	// effective is last, unless the failure policies tolerated the failures since
+	effective VerificationResult
+	decided   bool
+	// checks is the result of each check run by the last verification
+	checks []verifier.CheckResult
+}
+
+// LastVerification returns the result of the last periodic license verification.
+// ok is false if the license has not been verified yet.
+func (le *LicenseEnforcer) LastVerification() (result VerificationResult, ok bool) {
+	le.state.mu.RLock()
+	defer le.state.mu.RUnlock()
+	return le.state.last, le.state.exists
+}
+
+// EffectiveVerification returns the result of the last periodic license verification the enforcer
+// acted on. Unlike LastVerification, a failure tolerated by the failure threshold, the error budget or
+// flap damping doesn't replace the previous result. ok is false if no result has been acted on yet.
+func (le *LicenseEnforcer) EffectiveVerification() (result VerificationResult, ok bool) {
+	le.state.mu.RLock()
+	defer le.state.mu.RUnlock()
+	return le.state.effective, le.state.decided
+}
+
+// LicenseStatus returns the effective result of the periodic license verification as conditions,
+// which products can copy into the status of their custom resources.
+// ok is false if no result has been acted on yet.
+func (le *LicenseEnforcer) LicenseStatus() (status verifier.LicenseStatus, ok bool) {
+	result, ok := le.EffectiveVerification()
+	if !ok {
+		return verifier.LicenseStatus{}, false
+	}
+	return verifier.NewLicenseStatus(result.License, result.Err, result.Time), true
+}
+
+func (le *LicenseEnforcer) recordChecks(checks []verifier.CheckResult) {
+	le.state.mu.Lock()
+	le.state.checks = checks
+	le.state.mu.Unlock()
+
+	if le.metrics != nil {
+		le.metrics.observeChecks(checks)
+	}
+}
+
+// LastChecks returns the result of each check run by the last license verification.
+// It is empty if the license has not been verified yet or add-on licenses are stacked.
+func (le *LicenseEnforcer) LastChecks() []verifier.CheckResult {
+	le.state.mu.RLock()
+	defer le.state.mu.RUnlock()
+	return le.state.checks
+}
+
+func (le *LicenseEnforcer) recordVerification(license v1alpha1.License, err error, start time.Time) VerificationResult {
+	now := le.clock.Now()
+	result := VerificationResult{
+		License:    license,
+		ClusterUID: le.opts.ClusterUID,
+		Err:        err,
+		Time:       now,
+		Duration:   now.Sub(start),
+	}
+	le.state.mu.Lock()
+	le.state.last, le.state.exists = result, true
+	le.state.mu.Unlock()
+
+	if le.metrics != nil {
+		le.metrics.observe(result)
+	}
+	if le.statusConfigMap != nil && le.IsLeader() {
+		e2 := le.eventEmitter().Emit(func(ctx context.Context) error {
+			return le.publishStatus(ctx, result)
+		})
+		if e2 != nil {
+			klog.Warningln(e2)
+		}
+	}
+	return result
+}
+
+// actOn makes result the effective verification result, once the failure policies did not tolerate it.
+func (le *LicenseEnforcer) actOn(result VerificationResult) {
+	le.state.mu.Lock()
+	le.state.effective, le.state.decided = result, true
+	le.state.mu.Unlock()
+}
+
+// StatusConfigMap identifies the ConfigMap the license status is published to, so that
+// dashboards and support tooling can read it without parsing the license.
+type StatusConfigMap struct {
+	// Namespace defaults to the namespace of the pod.
+	Namespace string `json:"namespace,omitempty"`
+	// Name defaults to <product>-license-status.
+	Name string `json:"name,omitempty"`
+}
+
+func (s StatusConfigMap) objectMeta() metav1.ObjectMeta {
+	if s.Namespace == "" {
+		s.Namespace = meta.PodNamespace()
+	}
+	if s.Name == "" {
+		product := "license"
+		if features := info.Features(); len(features) > 0 {
+			product = features[0]
+		}
+		s.Name = product + "-license-status"
+	}
+	return metav1.ObjectMeta{Namespace: s.Namespace, Name: s.Name}
+}
+
+// SetStatusConfigMap makes the enforcer publish the license status to a ConfigMap after every verification.
+func (le *LicenseEnforcer) SetStatusConfigMap(s *StatusConfigMap) {
+	le.statusConfigMap = s
+}
+
+func (le *LicenseEnforcer) publishStatus(ctx context.Context, result VerificationResult) error {
+	if le.kc == nil {
+		return nil
+	}
+	license := result.License
+	data := map[string]string{
+		"id":           license.ID,
+		"status":       string(license.Status),
+		"reason":       license.Reason,
+		"plan":         license.PlanName,
+		"features":     strings.Join(license.Features, ","),
+		"lastVerified": result.Time.UTC().Format(time.RFC3339),
+	}
+	if license.NotAfter != nil {
+		data["notAfter"] = license.NotAfter.UTC().Format(time.RFC3339)
+	}
+	_, _, err := core_util.CreateOrPatchConfigMap(ctx, le.kc, le.statusConfigMap.objectMeta(), func(in *core.ConfigMap) *core.ConfigMap {
+		in.Data = data
+		return in
+	}, metav1.PatchOptions{})
+	return errors.Wrap(err, "failed to publish license status")
+}
@@ -0,0 +1,50 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+// Verifier verifies the license of a product. Code gated on the license should depend on Verifier
+// instead of LicenseEnforcer, so that it can be tested with the fake package.
+type Verifier interface {
+	// Verify returns the status of the verified license, including the grace period and expiry
+	// conditions. If the license is invalid, the conditions explain why and the error is not nil.
+	Verify(ctx context.Context) (*verifier.LicenseStatus, error)
+}
+
+var _ Verifier = &LicenseEnforcer{}
+
+// Verify verifies the license once.
+func (le *LicenseEnforcer) Verify(ctx context.Context) (*verifier.LicenseStatus, error) {
+	license, err := le.verifyOnce(ctx)
+	status := verifier.NewLicenseStatus(license, err, le.clock.Now())
+	return &status, err
+}
+
+func (le *LicenseEnforcer) verifyOnce(ctx context.Context) (v1alpha1.License, error) {
+	if err := le.createClients(); err != nil {
+		return verifier.BadLicense(err)
+	}
+	license, _, err := le.verifyLicense(ctx)
+	return license, err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+)
+
+// licenseReloadDelay batches the burst of file events caused by a single update of a mounted Secret.
+const licenseReloadDelay = 2 * time.Second
+
+// SetWatchLicenseFile re-verifies the license within seconds of the license file being
+// updated, eg. after a renewed license is written to the mounted Secret.
+func (le *LicenseEnforcer) SetWatchLicenseFile(watch bool) {
+	le.watchLicenseFile = watch
+}
+
+// watchLicense notifies changed whenever the license file changes and removed when it is deleted.
+// The parent directory is watched, since Secret and ConfigMap volumes update files by
+// atomically swapping the ..data symlink.
+func (le *LicenseEnforcer) watchLicense(ctx context.Context, changed, removed chan<- struct{}) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dir, name := filepath.Dir(le.licenseFile), filepath.Base(le.licenseFile)
+	if fi, err := os.Stat(le.licenseFile); err == nil && fi.IsDir() {
+		// any file in a license directory may hold the license
+		dir, name = le.licenseFile, ""
+	}
+	if err := w.Add(dir); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	go func() {
+		defer w.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if base := filepath.Base(e.Name); name == "" || base == name || base == "..data" {
+					reload = time.After(licenseReloadDelay)
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				klog.Warningf("Failed to watch license file %s. Reason: %v", le.licenseFile, err)
+			case <-reload:
+				reload = nil
+				if _, err := os.Stat(le.licenseFile); errors.Is(err, os.ErrNotExist) {
+					klog.V(4).Infof("License file %s deleted", le.licenseFile)
+					notify(removed)
+					continue
+				}
+				klog.V(4).Infof("License file %s changed", le.licenseFile)
+				notify(changed)
+			}
+		}
+	}()
+	return nil
+}
+
+// notify sends a value on ch without blocking. Pending notifications are coalesced.
+func notify(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
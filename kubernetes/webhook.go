@@ -0,0 +1,111 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultLicenseWebhookPath is the default path the license admission webhook is served at.
+const DefaultLicenseWebhookPath = "/validate-license"
+
+// PremiumResource is a custom resource that can only be created with a license that includes Feature.
+// An empty Kind matches every kind of the group.
+type PremiumResource struct {
+	Group   string `json:"group"`
+	Kind    string `json:"kind,omitempty"`
+	Feature string `json:"feature"`
+}
+
+// LicenseWebhook configures the validating admission webhook server that rejects creating
+// premium custom resources without a valid license.
+type LicenseWebhook struct {
+	// Port defaults to the controller-runtime webhook port 9443.
+	Port int `json:"port,omitempty"`
+	// CertDir holds the serving certificate, eg. mounted from a cert-manager Secret.
+	// It defaults to <temp-dir>/k8s-webhook-server/serving-certs.
+	CertDir  string `json:"certDir,omitempty"`
+	CertName string `json:"certName,omitempty"`
+	KeyName  string `json:"keyName,omitempty"`
+	// Path defaults to DefaultLicenseWebhookPath.
+	Path      string            `json:"path,omitempty"`
+	Resources []PremiumResource `json:"resources"`
+}
+
+// LicenseValidator is an admission handler that rejects creating premium custom resources
+// unless the last verified license is valid and includes their feature.
+type LicenseValidator struct {
+	le        *LicenseEnforcer
+	resources []PremiumResource
+}
+
+var _ admission.Handler = &LicenseValidator{}
+
+// NewLicenseValidator returns an admission handler for the given premium resources.
+// Register it with the webhook server of the manager of the product.
+func (le *LicenseEnforcer) NewLicenseValidator(resources ...PremiumResource) *LicenseValidator {
+	return &LicenseValidator{le: le, resources: resources}
+}
+
+// NewLicenseWebhookServer returns a webhook server that serves the license admission webhook.
+// Add it to the manager of the product using mgr.Add .
+func (le *LicenseEnforcer) NewLicenseWebhookServer(w LicenseWebhook) webhook.Server {
+	srv := webhook.NewServer(webhook.Options{
+		Port:     w.Port,
+		CertDir:  w.CertDir,
+		CertName: w.CertName,
+		KeyName:  w.KeyName,
+	})
+	path := w.Path
+	if path == "" {
+		path = DefaultLicenseWebhookPath
+	}
+	srv.Register(path, &webhook.Admission{Handler: le.NewLicenseValidator(w.Resources...)})
+	return srv
+}
+
+// Handle allows every request except creating a premium resource without a license for its feature.
+func (v *LicenseValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	r, ok := v.premiumResource(req.Kind.Group, req.Kind.Kind)
+	if !ok {
+		return admission.Allowed("")
+	}
+	if err := v.le.CheckRequest(r.Feature); err != nil {
+		klog.V(4).Infof("Denied creating %s %s/%s. Reason: %v", req.Kind.Kind, req.Namespace, req.Name, err)
+		return admission.Denied(fmt.Sprintf("%s requires a license for %s (%v). Install a license that includes %s to create it.",
+			req.Kind.Kind, r.Feature, err, r.Feature))
+	}
+	return admission.Allowed("")
+}
+
+func (v *LicenseValidator) premiumResource(group, kind string) (PremiumResource, bool) {
+	for _, r := range v.resources {
+		if r.Group == group && (r.Kind == "" || r.Kind == kind) {
+			return r, true
+		}
+	}
+	return PremiumResource{}, false
+}
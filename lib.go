@@ -17,16 +17,23 @@ limitations under the License.
 package verifier
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
 	"go.bytebuilders.dev/license-verifier/info"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
 )
 
 type Options struct {
@@ -39,57 +46,102 @@ type Options struct {
 type ParserOptions struct {
 	ClusterUID string
 	CACert     *x509.Certificate
-	License    []byte
+	// CACerts are additional trusted license CAs, eg. the new CA while the license CA is rotated.
+	CACerts []*x509.Certificate
+	License []byte
+	// Clock is used to check the validity period of the license.
+	// Defaults to the wall clock.
+	Clock clock.PassiveClock
+	// GracePeriod keeps accepting a license for the given duration after it has expired.
+	// The status of such a license is LicenseGracePeriod.
+	GracePeriod time.Duration
+	// ClockSkew tolerates a clock that is off by up to the given duration when
+	// checking the validity period of the license.
+	ClockSkew time.Duration
+	// Cache skips decoding and verifying the signature of a license that was verified before.
+	Cache *LicenseCache
 }
 
-type VerifyOptions struct {
-	ParserOptions
-	Features string
-}
+// DefaultClockSkew is the clock skew tolerated by the license enforcer.
+const DefaultClockSkew = 5 * time.Minute
 
-func ParseLicense(opts ParserOptions) (v1alpha1.License, error) {
-	cert, err := info.ParseCertificate(opts.License)
-	if err != nil {
-		return BadLicense(err)
+func (opts ParserOptions) now() time.Time {
+	if opts.Clock == nil {
+		return time.Now()
 	}
+	return opts.Clock.Now()
+}
 
-	roots := x509.NewCertPool()
-	roots.AddCert(opts.CACert)
-
-	crtopts := x509.VerifyOptions{
-		DNSName: opts.ClusterUID,
-		Roots:   roots,
-		KeyUsages: []x509.ExtKeyUsage{
-			x509.ExtKeyUsageClientAuth,
-		},
+// TrustedCAs returns CACert followed by CACerts.
+func (opts ParserOptions) TrustedCAs() []*x509.Certificate {
+	cas := make([]*x509.Certificate, 0, 1+len(opts.CACerts))
+	if opts.CACert != nil {
+		cas = append(cas, opts.CACert)
 	}
-
-	// wildcard certificate
-	if strings.HasPrefix(cert.Subject.CommonName, "*.") {
-		if len(opts.CACert.Subject.Organization) > 0 {
-			crtopts.DNSName = "*." + opts.CACert.Subject.Organization[0]
+	for _, ca := range opts.CACerts {
+		if ca != nil {
+			cas = append(cas, ca)
 		}
 	}
+	return cas
+}
+
+type VerifyOptions struct {
+	ParserOptions
+	Features string
+	// FeaturePolicy decides how many of the Features must be covered by the license.
+	// Defaults to FeaturePolicyAny.
+	FeaturePolicy FeaturePolicy
+	// Quorum is the number of Features required by FeaturePolicyQuorum.
+	Quorum int
+	// FeatureAliases resolves the features of the license before they are compared with Features.
+	FeatureAliases FeatureAliases
+	// ProductVersion is the version of the product. It must satisfy the version constraint
+	// of the license. A license with a version constraint is rejected if it is unset.
+	ProductVersion string
+	// Namespaces the product runs in or manages. A license restricted to namespaces must
+	// cover all of them and is rejected if they are unset.
+	Namespaces []string
+	// ClusterCAFingerprint is the sha256 fingerprint of the cluster CA certificate.
+	// It is checked if the license is bound to a cluster CA.
+	ClusterCAFingerprint string
+	// Checks run by CheckLicense. Defaults to the DefaultPipeline.
+	Checks Pipeline
+}
 
+// ParseLicense parses and verifies a license. Both PEM encoded x509 certificates and
+// JWT encoded licenses are supported.
+func ParseLicense(opts ParserOptions) (v1alpha1.License, error) {
+	license, _, err := parserPipeline().Run(VerifyOptions{ParserOptions: opts})
+	return license, err
+}
+
+// licenseFromCertificate extracts the license details encoded in the certificate fields.
+func licenseFromCertificate(data []byte, cert *x509.Certificate) (v1alpha1.License, error) {
 	license := v1alpha1.License{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: v1alpha1.SchemeGroupVersion.String(),
 			Kind:       "License",
 		},
-		Data:      opts.License,
+		Data:      data,
+		Format:    info.LicenseFormatX509,
 		Issuer:    info.ProdDomain,
 		Clusters:  cert.DNSNames,
 		NotBefore: &metav1.Time{Time: cert.NotBefore},
 		NotAfter:  &metav1.Time{Time: cert.NotAfter},
 		ID:        cert.SerialNumber.String(),
-		Features:  cert.Subject.Organization,
 	}
+	features, err := certificateFeatures(cert)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	license.Features = features
 	if len(cert.Subject.OrganizationalUnit) > 0 {
 		license.PlanName = cert.Subject.OrganizationalUnit[0]
 	} else {
 		// old certificate, so plan name auto detected from feature
 		// ref: https://github.com/appscode/offline-license-server/blob/v0.0.20/pkg/server/constants.go#L50-L59
-		features := sets.NewString(cert.Subject.Organization...)
+		features := sets.NewString(license.Features...)
 		if features.Has("kubedb-enterprise") {
 			license.PlanName = "kubedb-enterprise"
 		} else if features.Has("kubedb-community") {
@@ -122,6 +174,40 @@ func ParseLicense(opts ParserOptions) (v1alpha1.License, error) {
 			license.FeatureFlags[parts[0]] = parts[1]
 		}
 	}
+	namespaces, ok, err := stringsExtension(cert, OIDLicenseNamespaces)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	if ok {
+		// the extension takes precedence over the feature flag used by older issuers
+		license.FeatureFlags[v1alpha1.FeatureFlagNamespaces] = strings.Join(namespaces, ",")
+	}
+	version, ok, err := stringExtension(cert, OIDLicenseProductVersion)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	if ok {
+		license.FeatureFlags[v1alpha1.FeatureFlagProductVersion] = version
+	}
+	trial, ok, err := boolExtension(cert, OIDLicenseTrial)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	if ok {
+		license.FeatureFlags[v1alpha1.FeatureFlagTrial] = strconv.FormatBool(trial)
+	}
+	addOn, ok, err := boolExtension(cert, OIDLicenseAddOn)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	if ok {
+		license.FeatureFlags[v1alpha1.FeatureFlagAddOn] = strconv.FormatBool(addOn)
+	}
+	limits, err := limitsFromFeatureFlags(license.FeatureFlags)
+	if err != nil {
+		return BadLicense(withCause(ErrMalformedLicense, err))
+	}
+	license.Limits = limits
 
 	var user *v1alpha1.User
 	for _, e := range cert.EmailAddresses {
@@ -140,7 +226,7 @@ func ParseLicense(opts ParserOptions) (v1alpha1.License, error) {
 					Email: email,
 				}
 			} else if user.Email != email {
-				return BadLicense(fmt.Errorf("license issued to multiple emails %s", strings.Join(cert.EmailAddresses, ";")))
+				return BadLicense(withCause(ErrMalformedLicense, fmt.Errorf("license issued to multiple emails %s", strings.Join(cert.EmailAddresses, ";"))))
 			}
 		} else { // == 2
 			email := strings.TrimSpace(parts[1])
@@ -150,52 +236,107 @@ func ParseLicense(opts ParserOptions) (v1alpha1.License, error) {
 					Email: email,
 				}
 			} else if user.Email != email {
-				return BadLicense(fmt.Errorf("license issued to multiple emails %s", strings.Join(cert.EmailAddresses, ";")))
+				return BadLicense(withCause(ErrMalformedLicense, fmt.Errorf("license issued to multiple emails %s", strings.Join(cert.EmailAddresses, ";"))))
 			}
 		}
 	}
 	license.User = user
+	return license, nil
+}
 
-	// ref: https://github.com/appscode/gitea/blob/master/models/stripe_license.go#L117-L126
-	if _, err := cert.Verify(crtopts); err != nil {
-		e2 := errors.Wrap(err, "failed to verify certificate")
-		license.Status = v1alpha1.LicenseInvalid
-		license.Reason = e2.Error()
-		return license, e2
+// certificateFeatures returns the features of a license certificate. In order of precedence, they are
+// read from the features extension, the subject organization or the subject organizational unit,
+// so that the issuer can change the certificate layout without breaking older verifiers.
+func certificateFeatures(cert *x509.Certificate) ([]string, error) {
+	features, ok, err := stringsExtension(cert, OIDLicenseFeatures)
+	if err != nil || ok {
+		return features, err
 	}
-	license.Status = v1alpha1.LicenseActive
-	return license, nil
+	if len(cert.Subject.Organization) > 0 {
+		return cert.Subject.Organization, nil
+	}
+	return cert.Subject.OrganizationalUnit, nil
 }
 
+// CheckLicense verifies a license using opts.Checks or, if unset, the DefaultPipeline.
 func CheckLicense(opts VerifyOptions) (v1alpha1.License, error) {
-	license, err := ParseLicense(opts.ParserOptions)
-	if err != nil {
-		return license, err
+	license, _, err := RunChecks(opts)
+	return license, err
+}
+
+// RunChecks is like CheckLicense but also returns the result of each check.
+func RunChecks(opts VerifyOptions) (v1alpha1.License, []CheckResult, error) {
+	checks := opts.Checks
+	if checks == nil {
+		checks = DefaultPipeline()
 	}
-	if !sets.NewString(license.Features...).HasAny(info.ParseFeatures(opts.Features)...) {
-		e2 := fmt.Errorf("license was not issued for %s", opts.Features)
-		license.Status = v1alpha1.LicenseInvalid
-		license.Reason = e2.Error()
-		return license, e2
+	return checks.Run(opts)
+}
+
+// DecodeLicense extracts the details of a PEM or JWT encoded license without verifying it,
+// so that products can display them. The status of the returned license is Unknown;
+// use ParseLicense or CheckLicense to find out whether the license is valid.
+func DecodeLicense(data []byte) (v1alpha1.License, error) {
+	var license v1alpha1.License
+	if IsJWT(data) {
+		var claims LicenseClaims
+		if _, _, err := jwt.NewParser().ParseUnverified(string(bytes.TrimSpace(data)), &claims); err != nil {
+			return BadLicense(withCause(ErrMalformedLicense, errors.Wrap(err, "failed to parse license token")))
+		}
+		license = licenseFromClaims(data, claims)
+	} else {
+		cert, err := info.ParseCertificate(data)
+		if err != nil {
+			return BadLicense(withCause(ErrMalformedLicense, err))
+		}
+		license, err = licenseFromCertificate(data, cert)
+		if err != nil {
+			return license, err
+		}
 	}
-	license.Status = v1alpha1.LicenseActive
+	license.Status = v1alpha1.LicenseUnknown
 	return license, nil
 }
 
+// CertificateFingerprint returns the hex encoded sha256 fingerprint of the certificate.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(h[:])
+}
+
+// VerifyLicense verifies a license issued for opts.ClusterUID, which can be any opaque identifier.
+// It does not need a Kubernetes cluster. The license CA of the product is used, if opts.CACert is empty.
+// opts.CACert may be a bundle of license CAs, all of which are trusted.
 func VerifyLicense(opts Options) (v1alpha1.License, error) {
-	caCert, err := info.ParseCertificate(opts.CACert)
+	vopts, err := opts.verifyOptions()
 	if err != nil {
 		return BadLicense(err)
 	}
+	return CheckLicense(vopts)
+}
 
-	return CheckLicense(VerifyOptions{
+// verifyOptions loads the license CA, unless it is set, and returns the options of CheckLicense.
+func (opts Options) verifyOptions() (VerifyOptions, error) {
+	caData := opts.CACert
+	if len(caData) == 0 {
+		var err error
+		if caData, err = info.LoadLicenseCA(); err != nil {
+			return VerifyOptions{}, err
+		}
+	}
+	caCerts, err := info.ParseCertificates(caData)
+	if err != nil {
+		return VerifyOptions{}, err
+	}
+	return VerifyOptions{
 		ParserOptions: ParserOptions{
 			ClusterUID: opts.ClusterUID,
-			CACert:     caCert,
+			CACert:     caCerts[0],
+			CACerts:    caCerts[1:],
 			License:    opts.License,
 		},
 		Features: opts.Features,
-	})
+	}, nil
 }
 
 func BadLicense(err error) (v1alpha1.License, error) {
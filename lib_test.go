@@ -0,0 +1,267 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestClockSkew(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := verifier.DecodeLicense(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, now := range []time.Time{decoded.NotBefore.Add(-2 * time.Minute), decoded.NotAfter.Add(2 * time.Minute)} {
+		opts := verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     issuer.CACert,
+			License:    data,
+			Clock:      clocktesting.NewFakePassiveClock(now),
+		}
+		if _, err := verifier.ParseLicense(opts); !errors.Is(err, verifier.ErrLicenseExpired) {
+			t.Errorf("ParseLicense() at %s error = %v, want ErrLicenseExpired", now, err)
+		}
+		opts.ClockSkew = verifier.DefaultClockSkew
+		if _, err := verifier.ParseLicense(opts); err != nil {
+			t.Errorf("ParseLicense() at %s with clock skew error = %v", now, err)
+		}
+	}
+}
+
+func TestMultiClusterLicense(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	p, _ := licensetest.GetProfile(licensetest.ProfileEnterprise)
+	p.Clusters = []string{"8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a12", "fleet-a-*"}
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.Issue(clusterUID, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := verifier.ConvertLicense(verifier.ParserOptions{
+		ClusterUID: clusterUID,
+		CACert:     issuer.CACert,
+		License:    data,
+	}, issuer.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		clusterUID string
+		want       error
+	}{
+		{clusterUID, nil},
+		{"8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a12", nil},
+		{"fleet-a-17", nil},
+		{"fleet-b-17", verifier.ErrWrongCluster},
+		{licensetest.WrongClusterUID, verifier.ErrWrongCluster},
+	}
+	for _, tt := range tests {
+		for _, license := range [][]byte{data, token} {
+			_, err := verifier.ParseLicense(verifier.ParserOptions{
+				ClusterUID: tt.clusterUID,
+				CACert:     issuer.CACert,
+				License:    license,
+			})
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("ParseLicense() for cluster %s (%s) error = %v, want %v", tt.clusterUID, verifier.LicenseFormat(license), err, tt.want)
+			}
+		}
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	oldCA := licensetest.NewTestIssuer(t)
+	newCA := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	var licenses [][]byte
+	for _, ca := range []*licensetest.Issuer{oldCA, newCA} {
+		data, err := ca.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := verifier.ConvertLicense(verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     ca.CACert,
+			License:    data,
+		}, ca.Key())
+		if err != nil {
+			t.Fatal(err)
+		}
+		licenses = append(licenses, data, token)
+	}
+
+	for i, license := range licenses {
+		_, err := verifier.ParseLicense(verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     oldCA.CACert,
+			CACerts:    []*x509.Certificate{newCA.CACert},
+			License:    license,
+		})
+		if err != nil {
+			t.Errorf("ParseLicense() of license %d (%s) with CA bundle error = %v", i, verifier.LicenseFormat(license), err)
+		}
+
+		_, err = verifier.ParseLicense(verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     oldCA.CACert,
+			License:    license,
+		})
+		if issuedByNewCA := i >= 2; issuedByNewCA != errors.Is(err, verifier.ErrBadSignature) {
+			t.Errorf("ParseLicense() of license %d (%s) with old CA error = %v", i, verifier.LicenseFormat(license), err)
+		}
+
+		bundle := append(append([]byte{}, newCA.CACertPEM()...), oldCA.CACertPEM()...)
+		l, err := verifier.VerifyLicense(verifier.Options{
+			ClusterUID: clusterUID,
+			Features:   "kubedb-enterprise",
+			CACert:     bundle,
+			License:    license,
+		})
+		if err != nil || l.Status != v1alpha1.LicenseActive {
+			t.Errorf("VerifyLicense() of license %d (%s) with PEM bundle status = %s, error = %v", i, verifier.LicenseFormat(license), l.Status, err)
+		}
+	}
+}
+
+func TestTrialLicense(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := verifier.ConvertLicense(verifier.ParserOptions{
+		ClusterUID: clusterUID,
+		CACert:     issuer.CACert,
+		License:    data,
+	}, issuer.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := verifier.DecodeLicense(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.IsTrial() {
+		t.Fatalf("DecodeLicense() is not a trial license")
+	}
+
+	tests := []struct {
+		name        string
+		afterExpiry time.Duration
+		wantDays    int
+		wantErr     error
+	}{
+		{"first day", -7*24*time.Hour + time.Minute, 7, nil},
+		{"last day", -time.Hour, 1, nil},
+		{"expired", time.Hour, 0, verifier.ErrTrialExpired},
+	}
+	for _, tt := range tests {
+		for _, license := range [][]byte{data, token} {
+			l, err := verifier.ParseLicense(verifier.ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     issuer.CACert,
+				License:    license,
+				Clock:      clocktesting.NewFakePassiveClock(decoded.NotAfter.Add(tt.afterExpiry)),
+			})
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseLicense() %s (%s) error = %v, want %v", tt.name, verifier.LicenseFormat(license), err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, verifier.ErrLicenseExpired) {
+				t.Errorf("ParseLicense() %s (%s) error = %v, want %v", tt.name, verifier.LicenseFormat(license), err, verifier.ErrLicenseExpired)
+			}
+			if !l.Trial || l.TrialDaysRemaining != tt.wantDays {
+				t.Errorf("ParseLicense() %s (%s) trial = %v with %d days remaining, want %d days", tt.name, verifier.LicenseFormat(license), l.Trial, l.TrialDaysRemaining, tt.wantDays)
+			}
+		}
+	}
+
+	expired, err := issuer.IssueProfile(clusterUID, licensetest.ProfileExpired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = verifier.ParseLicense(verifier.ParserOptions{
+		ClusterUID: clusterUID,
+		CACert:     issuer.CACert,
+		License:    expired,
+	})
+	if !errors.Is(err, verifier.ErrLicenseExpired) || errors.Is(err, verifier.ErrTrialExpired) {
+		t.Errorf("ParseLicense() of an expired license error = %v, want %v only", err, verifier.ErrLicenseExpired)
+	}
+}
+
+func TestFeatureLayouts(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	enterprise, _ := licensetest.GetProfile(licensetest.ProfileEnterprise)
+	extension := enterprise
+	extension.Features = []string{"stash-community"}
+	extension.ExtensionFeatures = []string{"kubedb-enterprise"}
+	unit := enterprise
+	unit.Features = nil
+
+	tests := []struct {
+		name    string
+		profile licensetest.Profile
+		want    []string
+	}{
+		{"organization", enterprise, enterprise.Features},
+		{"extension", extension, extension.ExtensionFeatures},
+		{"organizational unit", unit, []string{enterprise.PlanName}},
+	}
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := issuer.Issue(clusterUID, tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			license, err := verifier.CheckLicense(verifier.VerifyOptions{
+				ParserOptions: verifier.ParserOptions{
+					ClusterUID: clusterUID,
+					CACert:     issuer.CACert,
+					License:    data,
+				},
+				Features: "kubedb-enterprise",
+			})
+			if err != nil {
+				t.Fatalf("CheckLicense() error = %v", err)
+			}
+			if !sets.NewString(license.Features...).Equal(sets.NewString(tt.want...)) {
+				t.Errorf("license features = %v, want %v", license.Features, tt.want)
+			}
+		})
+	}
+}
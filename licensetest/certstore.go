@@ -0,0 +1,140 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CACertFileName is the name of the CA certificate file written by a CertStore.
+	CACertFileName = "ca.crt"
+	// CAKeyFileName is the name of the CA private key file written by a CertStore.
+	CAKeyFileName = "ca.key"
+)
+
+// CertStore is an Issuer that keeps its CA and the licenses it issues in a directory,
+// so that e2e tests can hand license files to the product under test without a license server.
+type CertStore struct {
+	*Issuer
+	// Dir holds the CA files and the issued licenses.
+	Dir string
+}
+
+// NewCertStore generates a self signed CA and writes it to dir.
+// A new temporary directory is created if dir is empty. Call Cleanup to remove it.
+func NewCertStore(dir string) (*CertStore, error) {
+	issuer, err := NewIssuer()
+	if err != nil {
+		return nil, err
+	}
+	return NewCertStoreForIssuer(dir, issuer)
+}
+
+// NewCertStoreForIssuer writes the CA of issuer to dir.
+// A new temporary directory is created if dir is empty. Call Cleanup to remove it.
+func NewCertStoreForIssuer(dir string, issuer *Issuer) (*CertStore, error) {
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "license-certstore-"); err != nil {
+			return nil, errors.Wrap(err, "failed to create cert store directory")
+		}
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create cert store directory %s", dir)
+	}
+	s := &CertStore{Issuer: issuer, Dir: dir}
+
+	if err := os.WriteFile(s.CACertFile(), issuer.CACertPEM(), 0o644); err != nil {
+		return nil, errors.Wrap(err, "failed to write CA certificate")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(issuer.Key())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode CA key")
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(s.CAKeyFile(), key, 0o600); err != nil {
+		return nil, errors.Wrap(err, "failed to write CA key")
+	}
+	return s, nil
+}
+
+// CACertFile returns the path of the PEM encoded CA certificate.
+func (s *CertStore) CACertFile() string {
+	return filepath.Join(s.Dir, CACertFileName)
+}
+
+// CAKeyFile returns the path of the PEM encoded PKCS #8 CA private key.
+func (s *CertStore) CAKeyFile() string {
+	return filepath.Join(s.Dir, CAKeyFileName)
+}
+
+// IssueLicenseFile issues a license for the cluster with the given features that expires at notAfter,
+// and returns the path of the license file. The rest of the license is taken from the enterprise profile.
+// A notAfter in the past issues an already expired license.
+func (s *CertStore) IssueLicenseFile(clusterUID string, features []string, notAfter time.Time) (string, error) {
+	p, _ := GetProfile(ProfileEnterprise)
+	p.Features = append([]string(nil), features...)
+	p.Validity = notAfter.Sub(s.Clock.Now())
+	return s.IssueFile(clusterUID, p)
+}
+
+// IssueFile issues a license for the cluster with the shape described by the profile,
+// and returns the path of the license file.
+func (s *CertStore) IssueFile(clusterUID string, p Profile) (string, error) {
+	data, err := s.Issue(clusterUID, p)
+	if err != nil {
+		return "", err
+	}
+	return s.WriteLicense(data)
+}
+
+// IssueProfileFile issues a license for the cluster with the named profile,
+// and returns the path of the license file.
+func (s *CertStore) IssueProfileFile(clusterUID, name string) (string, error) {
+	data, err := s.IssueProfile(clusterUID, name)
+	if err != nil {
+		return "", err
+	}
+	return s.WriteLicense(data)
+}
+
+// WriteLicense writes a license to a new file in the store and returns its path.
+func (s *CertStore) WriteLicense(data []byte) (string, error) {
+	f, err := os.CreateTemp(s.Dir, "license-*.txt")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create license file")
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return "", errors.Wrapf(err, "failed to write license file %s", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		return "", errors.Wrapf(err, "failed to write license file %s", f.Name())
+	}
+	return f.Name(), nil
+}
+
+// Cleanup removes the store directory with the CA and all issued licenses.
+func (s *CertStore) Cleanup() error {
+	return os.RemoveAll(s.Dir)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestCertStore(t *testing.T) {
+	store, err := licensetest.NewCertStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := os.ReadFile(store.CACertFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(store.CAKeyFile()); err != nil {
+		t.Fatal(err)
+	}
+
+	const clusterUID = "2b1c5a8e-5a8e-4b1c-9d2f-0c4e1f7a9b3d"
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		wantErr  bool
+	}{
+		{"valid", time.Now().Add(30 * 24 * time.Hour), false},
+		{"expired", time.Now().Add(-time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := store.IssueLicenseFile(clusterUID, []string{"stash-enterprise"}, tt.notAfter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Dir(file) != store.Dir {
+				t.Errorf("IssueLicenseFile() = %s, want a file in %s", file, store.Dir)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := verifier.VerifyLicense(verifier.Options{
+				ClusterUID: clusterUID,
+				Features:   "stash-enterprise",
+				CACert:     caCert,
+				License:    data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyLicense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && l.Status != v1alpha1.LicenseActive {
+				t.Errorf("VerifyLicense() status = %s, want %s", l.Status, v1alpha1.LicenseActive)
+			}
+		})
+	}
+}
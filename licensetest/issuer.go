@@ -0,0 +1,361 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package licensetest issues licenses signed by a throwaway CA, so that test
+// suites can exercise license verification without a real license server.
+package licensetest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	verifier "go.bytebuilders.dev/license-verifier"
+	"golang.org/x/crypto/ocsp"
+	"k8s.io/utils/clock"
+)
+
+// Issuer signs licenses with a self signed CA.
+type Issuer struct {
+	CACert *x509.Certificate
+	// Clock is used to compute the validity period of issued licenses.
+	// Defaults to the wall clock.
+	Clock clock.PassiveClock
+	// OCSPServer is embedded as the OCSP responder URL in issued licenses.
+	OCSPServer string
+
+	key       crypto.Signer
+	algorithm KeyAlgorithm
+	// chain holds the intermediate certificates appended to issued licenses
+	chain []*x509.Certificate
+}
+
+// KeyAlgorithm is the algorithm of the keys generated by an Issuer.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	KeyAlgorithmEd25519   KeyAlgorithm = "Ed25519"
+	KeyAlgorithmRSA2048   KeyAlgorithm = "RSA-2048"
+)
+
+// KeyAlgorithms lists the key algorithms supported for license issuers.
+var KeyAlgorithms = []KeyAlgorithm{KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384, KeyAlgorithmEd25519, KeyAlgorithmRSA2048}
+
+func (alg KeyAlgorithm) generateKey() (crypto.Signer, error) {
+	switch alg {
+	case KeyAlgorithmECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyAlgorithmECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyAlgorithmEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case KeyAlgorithmRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	return nil, fmt.Errorf("unsupported key algorithm %q", alg)
+}
+
+// NewIssuer returns an issuer backed by a newly generated ECDSA P-256 CA.
+func NewIssuer() (*Issuer, error) {
+	return NewIssuerWithKeyAlgorithm(KeyAlgorithmECDSAP256)
+}
+
+// NewTestIssuer returns an issuer backed by a newly generated ECDSA P-256 CA.
+// It fails the test if the CA can't be generated.
+func NewTestIssuer(tb testing.TB) *Issuer {
+	tb.Helper()
+	issuer, err := NewIssuer()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return issuer
+}
+
+// NewIssuerWithKeyAlgorithm returns an issuer backed by a newly generated CA.
+// The CA, its intermediates and the issued licenses use keys of the given algorithm.
+func NewIssuerWithKeyAlgorithm(alg KeyAlgorithm) (*Issuer, error) {
+	key, err := alg.generateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CA key")
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "license-issuer", Organization: []string{"appscode.com"}},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{CACert: cert, Clock: clock.RealClock{}, key: key, algorithm: alg}, nil
+}
+
+// NewIntermediate returns an issuer backed by an intermediate CA signed by this issuer.
+// Licenses issued by it carry the intermediate certificates after the license certificate.
+func (i *Issuer) NewIntermediate(name string) (*Issuer, error) {
+	key, err := i.algorithm.generateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate intermediate CA key")
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name, Organization: i.CACert.Subject.Organization},
+		NotBefore:             i.CACert.NotBefore,
+		NotAfter:              i.CACert.NotAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, i.CACert, key.Public(), i.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create intermediate CA certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{
+		CACert:    cert,
+		Clock:     i.Clock,
+		key:       key,
+		algorithm: i.algorithm,
+		chain:     append([]*x509.Certificate{cert}, i.chain...),
+	}, nil
+}
+
+// CACertPEM returns the PEM encoded CA certificate.
+func (i *Issuer) CACertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: i.CACert.Raw})
+}
+
+// Key returns the private key of the issuer CA.
+func (i *Issuer) Key() crypto.Signer {
+	return i.key
+}
+
+// Issue returns a PEM encoded license for the cluster with the shape described by the profile.
+func (i *Issuer) Issue(clusterUID string, p Profile) ([]byte, error) {
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	if p.ClusterUID != "" {
+		clusterUID = p.ClusterUID
+	}
+
+	now := i.Clock.Now()
+	notBefore := now.Add(-time.Hour)
+	notAfter := now.Add(p.Validity)
+	if p.Validity <= 0 {
+		// already expired
+		notBefore = now.Add(p.Validity - 24*time.Hour)
+		notAfter = now.Add(p.Validity)
+	}
+
+	flags := make([]string, 0, len(p.FeatureFlags))
+	for k, v := range p.FeatureFlags {
+		flags = append(flags, k+"="+v)
+	}
+	sort.Strings(flags)
+
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         clusterUID,
+			Organization:       p.Features,
+			OrganizationalUnit: []string{p.PlanName},
+			Country:            []string{p.ProductLine},
+			Province:           []string{p.TierName},
+			Locality:           flags,
+		},
+		DNSNames:    append([]string{clusterUID}, p.Clusters...),
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if p.User != "" {
+		tmpl.EmailAddresses = []string{p.User}
+	}
+	if i.OCSPServer != "" {
+		tmpl.OCSPServer = []string{i.OCSPServer}
+	}
+	if len(p.Namespaces) > 0 {
+		value, err := asn1.Marshal(p.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: verifier.OIDLicenseNamespaces, Value: value})
+	}
+	if len(p.ExtensionFeatures) > 0 {
+		value, err := asn1.Marshal(p.ExtensionFeatures)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: verifier.OIDLicenseFeatures, Value: value})
+	}
+	if p.Trial {
+		value, err := asn1.Marshal(true)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: verifier.OIDLicenseTrial, Value: value})
+	}
+	if p.AddOn {
+		value, err := asn1.Marshal(true)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = append(tmpl.ExtraExtensions, pkix.Extension{Id: verifier.OIDLicenseAddOn, Value: value})
+	}
+
+	key, err := i.algorithm.generateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate license key")
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, i.CACert, key.Public(), i.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign license")
+	}
+	out := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	for _, c := range i.chain {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return out, nil
+}
+
+// OCSPResponse returns a DER encoded OCSP response with the given status for a license issued by this issuer.
+// If nonce is not nil, it is included in the response as the nonce extension.
+func (i *Issuer) OCSPResponse(license []byte, status int, nonce []byte) ([]byte, error) {
+	certs, err := info.ParseCertificates(license)
+	if err != nil {
+		return nil, err
+	}
+	now := i.Clock.Now()
+	tmpl := ocsp.Response{
+		Status:       status,
+		SerialNumber: certs[0].SerialNumber,
+		ThisUpdate:   now.Add(-time.Minute),
+		NextUpdate:   now.Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		tmpl.RevokedAt = now.Add(-time.Minute)
+	}
+	if nonce != nil {
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: oidOCSPNonce, Value: nonce}}
+	}
+	return ocsp.CreateResponse(i.CACert, i.CACert, tmpl, i.key)
+}
+
+// RevocationList returns a PEM encoded CRL with the given number, issued at thisUpdate and valid for a day,
+// that revokes the given licenses issued by this issuer.
+func (i *Issuer) RevocationList(number int64, thisUpdate time.Time, licenses ...[]byte) ([]byte, error) {
+	tmpl := x509.RevocationList{
+		Number:     big.NewInt(number),
+		ThisUpdate: thisUpdate,
+		NextUpdate: thisUpdate.Add(24 * time.Hour),
+	}
+	for _, license := range licenses {
+		certs, err := info.ParseCertificates(license)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   certs[0].SerialNumber,
+			RevocationTime: thisUpdate,
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &tmpl, i.CACert, i.key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+// OCSPNonce returns the nonce extension of a DER encoded OCSP request, if any.
+func OCSPNonce(request []byte) []byte {
+	var req struct {
+		TBSRequest struct {
+			Version           int              `asn1:"explicit,tag:0,default:0,optional"`
+			RequestorName     asn1.RawValue    `asn1:"explicit,tag:1,optional"`
+			RequestList       []asn1.RawValue  `asn1:""`
+			RequestExtensions []pkix.Extension `asn1:"explicit,tag:2,optional"`
+		}
+	}
+	if _, err := asn1.Unmarshal(request, &req); err != nil {
+		return nil
+	}
+	for _, ext := range req.TBSRequest.RequestExtensions {
+		if ext.Id.Equal(oidOCSPNonce) {
+			return ext.Value
+		}
+	}
+	return nil
+}
+
+var oidOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+// IssueProfile returns a PEM encoded license for the cluster using the named profile.
+func (i *Issuer) IssueProfile(clusterUID, name string) ([]byte, error) {
+	p, ok := GetProfile(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown license profile %q", name)
+	}
+	return i.Issue(clusterUID, p)
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+	return serial, nil
+}
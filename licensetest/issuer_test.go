@@ -0,0 +1,142 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestProfiles(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+
+	tests := []struct {
+		profile  string
+		features string
+		wantErr  error
+	}{
+		{licensetest.ProfileCommunity, "kubedb-community", nil},
+		{licensetest.ProfileEnterprise, "stash-enterprise", nil},
+		{licensetest.ProfileTrial7d, "kubedb-enterprise", nil},
+		{licensetest.ProfileExpired, "kubedb-enterprise", verifier.ErrLicenseExpired},
+		{licensetest.ProfileWrongCluster, "kubedb-enterprise", verifier.ErrWrongCluster},
+		{licensetest.ProfileFeatureLimited, "kubedb-community", verifier.ErrProductMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			data, err := issuer.IssueProfile(clusterUID, tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			license, err := verifier.CheckLicense(verifier.VerifyOptions{
+				ParserOptions: verifier.ParserOptions{
+					ClusterUID: clusterUID,
+					CACert:     issuer.CACert,
+					License:    data,
+				},
+				Features: tt.features,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckLicense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && license.Status != v1alpha1.LicenseActive {
+				t.Errorf("CheckLicense() status = %s, want %s", license.Status, v1alpha1.LicenseActive)
+			}
+
+			decoded, err := verifier.DecodeLicense(data)
+			if err != nil {
+				t.Fatalf("DecodeLicense() error = %v", err)
+			}
+			if decoded.ID != license.ID || decoded.PlanName != license.PlanName || decoded.Status != v1alpha1.LicenseUnknown {
+				t.Errorf("DecodeLicense() returned unexpected license %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestIntermediateIssuer(t *testing.T) {
+	root := licensetest.NewTestIssuer(t)
+	intermediate, err := root.NewIntermediate("license-issuer-intermediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+
+	data, err := intermediate.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	license, err := verifier.ParseLicense(verifier.ParserOptions{
+		ClusterUID: clusterUID,
+		CACert:     root.CACert,
+		License:    data,
+	})
+	if err != nil {
+		t.Fatalf("ParseLicense() error = %v", err)
+	}
+	if license.Status != v1alpha1.LicenseActive {
+		t.Errorf("ParseLicense() status = %s, want %s", license.Status, v1alpha1.LicenseActive)
+	}
+}
+
+func TestKeyAlgorithms(t *testing.T) {
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	for _, alg := range licensetest.KeyAlgorithms {
+		t.Run(string(alg), func(t *testing.T) {
+			root, err := licensetest.NewIssuerWithKeyAlgorithm(alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			intermediate, err := root.NewIntermediate("license-issuer-intermediate")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, issuer := range []*licensetest.Issuer{root, intermediate} {
+				data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts := verifier.ParserOptions{
+					ClusterUID: clusterUID,
+					CACert:     root.CACert,
+					License:    data,
+				}
+				if _, err := verifier.ParseLicense(opts); err != nil {
+					t.Fatalf("ParseLicense() error = %v", err)
+				}
+				if issuer != root {
+					continue
+				}
+
+				token, err := verifier.ConvertLicense(opts, issuer.Key())
+				if err != nil {
+					t.Fatalf("ConvertLicense() error = %v", err)
+				}
+				opts.License = token
+				if _, err := verifier.ParseLicense(opts); err != nil {
+					t.Fatalf("ParseLicense() of JWT license error = %v", err)
+				}
+			}
+		})
+	}
+}
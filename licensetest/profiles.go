@@ -0,0 +1,140 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	ProfileCommunity      = "community"
+	ProfileEnterprise     = "enterprise"
+	ProfileTrial7d        = "trial-7d"
+	ProfileExpired        = "expired"
+	ProfileWrongCluster   = "wrong-cluster"
+	ProfileFeatureLimited = "feature-limited"
+
+	// WrongClusterUID is the cluster the wrong-cluster profile is issued for.
+	WrongClusterUID = "00000000-0000-0000-0000-000000000000"
+)
+
+// Profile describes the shape of a license.
+type Profile struct {
+	ProductLine  string
+	TierName     string
+	PlanName     string
+	Features     []string
+	FeatureFlags map[string]string
+	User         string
+	// Validity is the remaining lifetime of the license. Licenses with a non positive
+	// validity are issued already expired.
+	Validity time.Duration
+	// ClusterUID overrides the cluster the license is issued for.
+	ClusterUID string
+	// Clusters are additional cluster UIDs or wildcard patterns covered by a multi-cluster license.
+	Clusters []string
+	// Namespaces restricts the license to the namespaces using the namespaces certificate extension.
+	Namespaces []string
+	// ExtensionFeatures are encoded in the features certificate extension, which takes precedence
+	// over the Features encoded in the subject organization.
+	ExtensionFeatures []string
+	// Trial marks the license as a trial license using the trial certificate extension.
+	Trial bool
+	// AddOn marks the license as an add-on license using the add-on certificate extension.
+	AddOn bool
+}
+
+var profiles = map[string]Profile{
+	ProfileCommunity: {
+		ProductLine: "kubedb",
+		TierName:    "community",
+		PlanName:    "kubedb-community",
+		Features:    []string{"kubedb-community"},
+		User:        "Test User <test@example.com>",
+		Validity:    365 * 24 * time.Hour,
+	},
+	ProfileEnterprise: {
+		ProductLine: "kubedb",
+		TierName:    "enterprise",
+		PlanName:    "kubedb-enterprise",
+		Features:    []string{"kubedb-enterprise", "kubedb-community", "stash-enterprise", "stash-community"},
+		User:        "Test User <test@example.com>",
+		Validity:    365 * 24 * time.Hour,
+	},
+	ProfileTrial7d: {
+		ProductLine: "kubedb",
+		TierName:    "enterprise",
+		PlanName:    "kubedb-enterprise",
+		Features:    []string{"kubedb-enterprise", "kubedb-community"},
+		User:        "Test User <test@example.com>",
+		Validity:    7 * 24 * time.Hour,
+		Trial:       true,
+	},
+	ProfileExpired: {
+		ProductLine: "kubedb",
+		TierName:    "enterprise",
+		PlanName:    "kubedb-enterprise",
+		Features:    []string{"kubedb-enterprise", "kubedb-community"},
+		User:        "Test User <test@example.com>",
+		Validity:    -24 * time.Hour,
+	},
+	ProfileWrongCluster: {
+		ProductLine: "kubedb",
+		TierName:    "enterprise",
+		PlanName:    "kubedb-enterprise",
+		Features:    []string{"kubedb-enterprise", "kubedb-community"},
+		User:        "Test User <test@example.com>",
+		Validity:    365 * 24 * time.Hour,
+		ClusterUID:  WrongClusterUID,
+	},
+	ProfileFeatureLimited: {
+		ProductLine: "kubedb",
+		TierName:    "enterprise",
+		PlanName:    "kubedb-enterprise",
+		Features:    []string{"kubedb-enterprise"},
+		User:        "Test User <test@example.com>",
+		Validity:    365 * 24 * time.Hour,
+	},
+}
+
+// GetProfile returns a copy of the named profile.
+func GetProfile(name string) (Profile, bool) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, false
+	}
+	p.Features = append([]string(nil), p.Features...)
+	if p.FeatureFlags != nil {
+		flags := make(map[string]string, len(p.FeatureFlags))
+		for k, v := range p.FeatureFlags {
+			flags[k] = v
+		}
+		p.FeatureFlags = flags
+	}
+	return p, true
+}
+
+// Profiles returns the names of all known profiles.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,294 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Response selects how an IssuerServer answers requests.
+type Response string
+
+const (
+	// ResponseSuccess issues licenses and registers clusters.
+	ResponseSuccess Response = "Success"
+	// ResponseForbidden rejects requests with 403 Forbidden, eg. for an invalid token or an exhausted quota.
+	ResponseForbidden Response = "Forbidden"
+	// ResponseTooManyRequests rejects requests with 429 Too Many Requests and a Retry-After header.
+	ResponseTooManyRequests Response = "TooManyRequests"
+	// ResponseRevoked issues licenses that are revoked right away. They are listed in the
+	// revocation list and reported canceled by the verify endpoint.
+	ResponseRevoked Response = "Revoked"
+)
+
+// DefaultRetryAfter is the Retry-After duration sent with ResponseTooManyRequests.
+const DefaultRetryAfter = time.Minute
+
+// IssuerServer is an in-process fake license issuer. It serves the license issue and cluster
+// registration endpoints, as well as the revocation list and license verify endpoints,
+// so that client.Client consumers can be tested without a real license issuer.
+type IssuerServer struct {
+	*httptest.Server
+	// Issuer signs the licenses issued by the server.
+	Issuer *Issuer
+
+	mu         sync.Mutex
+	response   Response
+	retryAfter time.Duration
+	profile    Profile
+	clusters   map[string]bool
+	revoked    map[string]time.Time
+	issued     int
+}
+
+// NewIssuerServer starts a fake license issuer that issues licenses signed by issuer using
+// the enterprise profile with the requested features. Call Close to shut it down.
+func NewIssuerServer(issuer *Issuer) *IssuerServer {
+	p, _ := GetProfile(ProfileEnterprise)
+	s := &IssuerServer{
+		Issuer:     issuer,
+		response:   ResponseSuccess,
+		retryAfter: DefaultRetryAfter,
+		profile:    p,
+		clusters:   map[string]bool{},
+		revoked:    map[string]time.Time{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+info.LicenseIssuerAPIPath, s.issue)
+	mux.HandleFunc("/"+info.RegistrationAPIPath, s.register)
+	mux.HandleFunc("/"+info.LicenseRevocationListAPIPath, s.revocationList)
+	mux.HandleFunc("/"+info.LicenseVerifyAPIPath, s.verify)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(client.HeaderAPIVersion, strconv.Itoa(client.APIVersion))
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// SetResponse changes how the server answers subsequent requests.
+func (s *IssuerServer) SetResponse(r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.response = r
+}
+
+// SetRetryAfter changes the Retry-After duration sent with ResponseTooManyRequests.
+func (s *IssuerServer) SetRetryAfter(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retryAfter = d
+}
+
+// SetProfile changes the shape of subsequently issued licenses. The features of issued licenses
+// are always the requested features.
+func (s *IssuerServer) SetProfile(p Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profile = p
+}
+
+// Registered reports whether the cluster has been registered with the server.
+func (s *IssuerServer) Registered(clusterUID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clusters[clusterUID]
+}
+
+// Issued returns the number of licenses issued by the server.
+func (s *IssuerServer) Issued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// reject writes the error response selected by s.response and reports whether it did.
+// The caller must hold s.mu.
+func (s *IssuerServer) reject(w http.ResponseWriter) bool {
+	switch s.response {
+	case ResponseForbidden:
+		http.Error(w, "license issuer denied the request", http.StatusForbidden)
+		return true
+	case ResponseTooManyRequests:
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.retryAfter.Seconds())))
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return true
+	}
+	return false
+}
+
+func (s *IssuerServer) issue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Cluster  string   `json:"cluster"`
+		Features []string `json:"features"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reject(w) {
+		return
+	}
+	p := s.profile
+	p.Features = req.Features
+	data, err := s.Issuer.Issue(req.Cluster, p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cert, err := parseLicenseCert(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.issued++
+	if s.response == ResponseRevoked {
+		s.revoked[cert.SerialNumber.String()] = s.Issuer.Clock.Now()
+	}
+
+	writeJSON(w, struct {
+		Contract *v1alpha1.Contract `json:"contract,omitempty"`
+		License  []byte             `json:"license"`
+	}{
+		Contract: &v1alpha1.Contract{
+			ID:              "contract-" + cert.SerialNumber.String(),
+			StartTimestamp:  metav1.NewTime(cert.NotBefore),
+			ExpiryTimestamp: metav1.NewTime(cert.NotAfter),
+		},
+		License: data,
+	})
+}
+
+func (s *IssuerServer) register(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Cluster string `json:"cluster"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reject(w) {
+		return
+	}
+	if s.clusters[req.Cluster] {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	s.clusters[req.Cluster] = true
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *IssuerServer) revocationList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Issuer.Clock.Now()
+	tmpl := x509.RevocationList{
+		Number:     big.NewInt(int64(len(s.revoked) + 1)),
+		ThisUpdate: now,
+		NextUpdate: now.Add(24 * time.Hour),
+	}
+	for serial, at := range s.revoked {
+		n, _ := new(big.Int).SetString(serial, 10)
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   n,
+			RevocationTime: at,
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &tmpl, s.Issuer.CACert, s.Issuer.Key())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+}
+
+func (s *IssuerServer) verify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Cluster string `json:"cluster"`
+		License []byte `json:"license"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	cert, err := parseLicenseCert(req.License)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := client.LicenseVerification{
+		Status:  v1alpha1.LicenseActive,
+		Cluster: cert.Subject.CommonName,
+	}
+	if at, ok := s.revoked[cert.SerialNumber.String()]; ok {
+		result.Status = v1alpha1.LicenseCanceled
+		result.Reason = "license was revoked at " + at.UTC().Format(time.RFC3339)
+	}
+	writeJSON(w, result)
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func parseLicenseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("license is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
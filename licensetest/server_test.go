@@ -0,0 +1,115 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licensetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/client"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestIssuerServer(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	srv := licensetest.NewIssuerServer(issuer)
+	defer srv.Close()
+
+	const clusterUID = "5d3f2a1b-7c6e-4f8a-b9d0-1e2f3a4b5c6d"
+	c, err := client.NewClient(srv.URL, "token", clusterUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Registered(clusterUID) {
+		t.Error("Registered() = false, want true")
+	}
+	data, contract, err := c.AcquireLicense(context.Background(), []string{"kubedb-enterprise"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contract == nil {
+		t.Error("AcquireLicense() contract = nil")
+	}
+	if _, err := verifier.VerifyLicense(verifier.Options{
+		ClusterUID: clusterUID,
+		Features:   "kubedb-enterprise",
+		CACert:     issuer.CACertPEM(),
+		License:    data,
+	}); err != nil {
+		t.Errorf("VerifyLicense() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := c.AcquireLicense(ctx, []string{"kubedb-enterprise"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("AcquireLicense() error = %v, want %v", err, context.Canceled)
+	}
+
+	srv.SetResponse(licensetest.ResponseForbidden)
+	if _, _, err := c.AcquireLicense(context.Background(), []string{"kubedb-enterprise"}); !kerr.IsForbidden(err) {
+		t.Errorf("AcquireLicense() error = %v, want forbidden", err)
+	}
+	srv.SetResponse(licensetest.ResponseTooManyRequests)
+	if _, _, err := c.AcquireLicense(context.Background(), []string{"kubedb-enterprise"}); !kerr.IsTooManyRequests(err) {
+		t.Errorf("AcquireLicense() error = %v, want too many requests", err)
+	}
+
+	srv.SetResponse(licensetest.ResponseRevoked)
+	data, _, err = c.AcquireLicense(context.Background(), []string{"kubedb-enterprise"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Issued(); n != 2 {
+		t.Errorf("Issued() = %d, want 2", n)
+	}
+	result, err := c.VerifyLicense(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != v1alpha1.LicenseCanceled {
+		t.Errorf("VerifyLicense() status = %s, want %s", result.Status, v1alpha1.LicenseCanceled)
+	}
+	crlData, err := c.GetRevocationList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := verifier.ParseRevocationList(crlData, issuer.CACert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := verifier.VerifyLicense(verifier.Options{
+		ClusterUID: clusterUID,
+		Features:   "kubedb-enterprise",
+		CACert:     issuer.CACertPEM(),
+		License:    data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.CheckRevocation(&l, crl, time.Now()); err == nil {
+		t.Error("CheckRevocation() error = nil, want revoked")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"crypto"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// LicenseFormat returns the format of a license, either info.LicenseFormatX509 or info.LicenseFormatJWT .
+// Both formats are accepted while the fleet migrates from x509 to JWT licenses.
+func LicenseFormat(data []byte) string {
+	if IsJWT(data) {
+		return info.LicenseFormatJWT
+	}
+	return info.LicenseFormatX509
+}
+
+// ClaimsFromLicense returns the JWT claims carrying the same details as the license.
+func ClaimsFromLicense(license v1alpha1.License) LicenseClaims {
+	claims := LicenseClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   license.Issuer,
+			Audience: license.Clusters,
+			ID:       license.ID,
+		},
+		ProductLine:  license.ProductLine,
+		TierName:     license.TierName,
+		PlanName:     license.PlanName,
+		Features:     license.Features,
+		FeatureFlags: license.FeatureFlags,
+		Limits:       license.Limits,
+		User:         license.User,
+	}
+	if license.NotBefore != nil {
+		claims.NotBefore = jwt.NewNumericDate(license.NotBefore.Time)
+	}
+	if license.NotAfter != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(license.NotAfter.Time)
+	}
+	return claims
+}
+
+// ConvertLicense re-wraps a valid x509 license as a JWT license signed with the private key of the license CA.
+// It is meant for license issuers; clusters can't convert licenses locally since they don't hold the CA key.
+// A JWT license is returned unchanged.
+func ConvertLicense(opts ParserOptions, key crypto.Signer) ([]byte, error) {
+	if IsJWT(opts.License) {
+		return bytes.TrimSpace(opts.License), nil
+	}
+	license, err := ParseLicense(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !trustsKey(opts, pub) {
+		return nil, errors.New("signing key does not belong to the license CA")
+	}
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(jwtSigningMethods, method.Alg()) {
+		return nil, errors.Errorf("signing algorithm %s is not supported for licenses", method.Alg())
+	}
+	token, err := jwt.NewWithClaims(method, ClaimsFromLicense(license)).SignedString(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign license")
+	}
+	return []byte(token), nil
+}
+
+func trustsKey(opts ParserOptions, pub interface{ Equal(crypto.PublicKey) bool }) bool {
+	for _, ca := range opts.TrustedCAs() {
+		if pub.Equal(ca.PublicKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/info"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestConvertLicense(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := verifier.ParserOptions{
+		ClusterUID: clusterUID,
+		CACert:     issuer.CACert,
+		License:    data,
+	}
+	token, err := verifier.ConvertLicense(opts, issuer.Key())
+	if err != nil {
+		t.Fatalf("ConvertLicense() error = %v", err)
+	}
+	if verifier.LicenseFormat(token) != info.LicenseFormatJWT {
+		t.Fatalf("ConvertLicense() did not return a JWT license")
+	}
+
+	want, _ := verifier.ParseLicense(opts)
+	opts.License = token
+	got, err := verifier.ParseLicense(opts)
+	if err != nil {
+		t.Fatalf("ParseLicense() error = %v", err)
+	}
+	if got.ID != want.ID || got.PlanName != want.PlanName || !got.NotAfter.Equal(want.NotAfter) || got.Format != info.LicenseFormatJWT {
+		t.Errorf("converted license %+v does not match %+v", got, want)
+	}
+}
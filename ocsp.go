@@ -0,0 +1,129 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	ErrOCSPNonceMismatch = errors.New("OCSP response nonce does not match the request")
+	ErrOCSPStatusUnknown = errors.New("OCSP responder does not know the license")
+)
+
+// oidOCSPNonce is the id-pkix-ocsp-nonce extension, ref: https://www.rfc-editor.org/rfc/rfc8954
+var oidOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+// ocspRequest mirrors the OCSPRequest structure of RFC 6960, so that a nonce
+// extension can be added to the requests created by golang.org/x/crypto/ocsp.
+type ocspRequest struct {
+	TBSRequest struct {
+		Version           int              `asn1:"explicit,tag:0,default:0,optional"`
+		RequestorName     asn1.RawValue    `asn1:"explicit,tag:1,optional"`
+		RequestList       []asn1.RawValue  `asn1:""`
+		RequestExtensions []pkix.Extension `asn1:"explicit,tag:2,optional"`
+	}
+}
+
+// NewOCSPRequest returns a DER encoded OCSP request for the license certificate issued by issuer.
+// The request carries a random nonce, which must be passed to CheckOCSPResponse.
+func NewOCSPRequest(cert, issuer *x509.Certificate) (request, nonce []byte, err error) {
+	data, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create OCSP request")
+	}
+	var req ocspRequest
+	if _, err := asn1.Unmarshal(data, &req); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse OCSP request")
+	}
+
+	n := make([]byte, 32)
+	if _, err := rand.Read(n); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate OCSP nonce")
+	}
+	nonce, err = asn1.Marshal(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.TBSRequest.RequestExtensions = append(req.TBSRequest.RequestExtensions, pkix.Extension{
+		Id:    oidOCSPNonce,
+		Value: nonce,
+	})
+	request, err = asn1.Marshal(req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to encode OCSP request")
+	}
+	return request, nonce, nil
+}
+
+// CheckOCSPResponse verifies that the DER encoded OCSP response was signed by the issuer of the license certificate
+// and answers the request with the given nonce. Responders may omit the nonce, eg. when serving
+// pre-produced responses, but a response with a different nonce is rejected. A revoked license is marked canceled.
+// If the response has passed its NextUpdate time, ErrRevocationListStale is returned.
+func CheckOCSPResponse(license *v1alpha1.License, data []byte, cert, issuer *x509.Certificate, nonce []byte, now time.Time) (*ocsp.Response, error) {
+	resp, err := ocsp.ParseResponseForCert(data, cert, issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify OCSP response")
+	}
+	if nonce != nil {
+		if err := checkOCSPNonce(resp, nonce); err != nil {
+			return nil, err
+		}
+	}
+	return resp, CheckOCSPStatus(license, resp, now)
+}
+
+// CheckOCSPStatus checks the license against a verified OCSP response.
+func CheckOCSPStatus(license *v1alpha1.License, resp *ocsp.Response, now time.Time) error {
+	switch resp.Status {
+	case ocsp.Revoked:
+		e2 := fmt.Errorf("license %s was revoked at %s", license.ID, resp.RevokedAt.UTC().Format(time.RFC3339))
+		license.Status = v1alpha1.LicenseCanceled
+		license.Reason = e2.Error()
+		return e2
+	case ocsp.Unknown:
+		return errors.Wrapf(ErrOCSPStatusUnknown, "license %s", license.ID)
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return errors.Wrapf(ErrRevocationListStale, "OCSP response for license %s expired at %s", license.ID, resp.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func checkOCSPNonce(resp *ocsp.Response, nonce []byte) error {
+	for _, ext := range resp.Extensions {
+		if ext.Id.Equal(oidOCSPNonce) {
+			if !bytes.Equal(ext.Value, nonce) {
+				return ErrOCSPNonceMismatch
+			}
+			return nil
+		}
+	}
+	// a response without a nonce can't be replayed past its NextUpdate time, which is checked by the caller
+	return nil
+}
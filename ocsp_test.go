@@ -0,0 +1,91 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOCSP(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	issuer.OCSPServer = "http://ocsp.example.com"
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := info.ParseCertificates(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs[0].OCSPServer) != 1 {
+		t.Fatalf("license does not embed the OCSP responder url")
+	}
+
+	req, nonce, err := verifier.NewOCSPRequest(certs[0], issuer.CACert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := licensetest.OCSPNonce(req); string(got) != string(nonce) {
+		t.Fatalf("OCSP request nonce = %x, want %x", got, nonce)
+	}
+	if _, err := ocsp.ParseRequest(req); err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		nonce      []byte
+		wantErr    error
+		wantStatus v1alpha1.LicenseStatus
+	}{
+		{"good", ocsp.Good, nonce, nil, ""},
+		{"revoked", ocsp.Revoked, nonce, nil, v1alpha1.LicenseCanceled},
+		{"unknown", ocsp.Unknown, nonce, verifier.ErrOCSPStatusUnknown, ""},
+		{"wrong nonce", ocsp.Good, []byte{4, 1, 0}, verifier.ErrOCSPNonceMismatch, ""},
+		{"missing nonce", ocsp.Good, nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := issuer.OCSPResponse(data, tt.status, tt.nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var license v1alpha1.License
+			_, err = verifier.CheckOCSPResponse(&license, resp, certs[0], issuer.CACert, nonce, time.Now())
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckOCSPResponse() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (err != nil) != (tt.wantStatus != "") {
+				t.Errorf("CheckOCSPResponse() error = %v", err)
+			}
+			if license.Status != tt.wantStatus {
+				t.Errorf("license status = %q, want %q", license.Status, tt.wantStatus)
+			}
+		})
+	}
+}
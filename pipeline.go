@@ -0,0 +1,590 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Names of the checks run by the default verification pipeline.
+const (
+	CheckParse          = "Parse"
+	CheckChain          = "Chain"
+	CheckClusterBinding = "ClusterBinding"
+	CheckExpiry         = "Expiry"
+	CheckProduct        = "Product"
+	CheckLimits         = "Limits"
+	CheckNamespace      = "Namespace"
+	CheckVersion        = "Version"
+)
+
+// Check is a single step of license verification.
+// A check returns an error if the license must be rejected.
+type Check interface {
+	Name() string
+	Check(c *CheckContext) error
+}
+
+// CheckContext is the state shared by the checks of a Pipeline.
+type CheckContext struct {
+	Options VerifyOptions
+	// License is decoded by the Parse check and may be updated by later checks.
+	License v1alpha1.License
+	// Certificates of a PEM encoded license, the license certificate first.
+	Certificates []*x509.Certificate
+
+	token  string
+	claims *LicenseClaims
+	// cacheKey is set if the license may be cached, cached if it was restored from the cache
+	cacheKey string
+	cached   bool
+}
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Outcomes of a check, as reported by CheckResult.Outcome .
+const (
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// Outcome returns whether the check passed, failed or was skipped.
+func (r CheckResult) Outcome() string {
+	switch {
+	case r.Skipped:
+		return CheckSkipped
+	case r.Passed:
+		return CheckPassed
+	}
+	return CheckFailed
+}
+
+// Pipeline is an ordered list of checks. Embedders can reorder, remove or add checks
+// and pass the result via VerifyOptions.Checks .
+type Pipeline []Check
+
+// DefaultPipeline returns the checks run by CheckLicense.
+func DefaultPipeline() Pipeline {
+	return Pipeline{ParseCheck{}, ChainCheck{}, ClusterBindingCheck{}, ExpiryCheck{}, ProductCheck{}, VersionCheck{}, NamespaceCheck{}, LimitsCheck{}}
+}
+
+// parserPipeline returns the checks run by ParseLicense.
+func parserPipeline() Pipeline {
+	return Pipeline{ParseCheck{}, ChainCheck{}, ClusterBindingCheck{}, ExpiryCheck{}}
+}
+
+// Without returns a copy of the pipeline without the named checks.
+func (p Pipeline) Without(names ...string) Pipeline {
+	out := make(Pipeline, 0, len(p))
+	for _, c := range p {
+		if !contains(names, c.Name()) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// With returns a copy of the pipeline with the checks appended.
+func (p Pipeline) With(checks ...Check) Pipeline {
+	return append(append(make(Pipeline, 0, len(p)+len(checks)), p...), checks...)
+}
+
+// Replace returns a copy of the pipeline with the check of the same name replaced by c.
+func (p Pipeline) Replace(c Check) Pipeline {
+	out := append(make(Pipeline, 0, len(p)), p...)
+	for i := range out {
+		if out[i].Name() == c.Name() {
+			out[i] = c
+		}
+	}
+	return out
+}
+
+// Run runs the checks in order and stops at the first failure.
+// The results of the checks that were not run are reported as skipped.
+func (p Pipeline) Run(opts VerifyOptions) (v1alpha1.License, []CheckResult, error) {
+	c := &CheckContext{Options: opts}
+	results := make([]CheckResult, 0, len(p))
+	var err error
+	for _, check := range p {
+		if err != nil {
+			results = append(results, CheckResult{Name: check.Name(), Skipped: true})
+			continue
+		}
+		start := time.Now()
+		err = check.Check(c)
+		result := CheckResult{Name: check.Name(), Passed: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	if err != nil {
+		if c.License.Kind == "" {
+			// license could not be decoded
+			license, err := BadLicense(err)
+			return license, results, err
+		}
+		c.License.Status = v1alpha1.LicenseInvalid
+		c.License.Reason = err.Error()
+		return c.License, results, err
+	}
+	if c.License.Kind == "" {
+		return v1alpha1.License{}, results, errors.New("license verification pipeline has no Parse check")
+	}
+	if c.License.Status != v1alpha1.LicenseGracePeriod {
+		c.License.Status = v1alpha1.LicenseActive
+	}
+	return c.License, results, nil
+}
+
+// ParseCheck decodes the license and enforces the FIPS algorithm policy.
+type ParseCheck struct{}
+
+func (ParseCheck) Name() string { return CheckParse }
+
+func (ParseCheck) Check(c *CheckContext) error {
+	opts := c.Options
+	if opts.Cache != nil {
+		c.cacheKey = cacheKey(opts.ParserOptions)
+		if opts.Cache.restore(c.cacheKey, c) {
+			c.cached = true
+			return nil
+		}
+	}
+	if IsJWT(opts.License) {
+		if info.FIPSEnabled() {
+			for _, ca := range opts.TrustedCAs() {
+				if err := CheckAlgorithmPolicy(ca); err != nil {
+					return err
+				}
+			}
+		}
+		var claims LicenseClaims
+		c.token = string(bytes.TrimSpace(opts.License))
+		if _, _, err := jwt.NewParser().ParseUnverified(c.token, &claims); err != nil {
+			return withCause(ErrMalformedLicense, errors.Wrap(err, "failed to parse license token"))
+		}
+		c.claims = &claims
+		c.License = licenseFromClaims(opts.License, claims)
+		return nil
+	}
+
+	// The license may be followed by the intermediate certificates it was issued by.
+	certs, err := info.ParseCertificates(opts.License)
+	if err != nil {
+		return withCause(ErrMalformedLicense, err)
+	}
+	if info.FIPSEnabled() {
+		for _, ca := range opts.TrustedCAs() {
+			if err := CheckAlgorithmPolicy(ca); err != nil {
+				return err
+			}
+		}
+		for _, cert := range certs {
+			if err := CheckAlgorithmPolicy(cert); err != nil {
+				return err
+			}
+		}
+	}
+	license, err := licenseFromCertificate(opts.License, certs[0])
+	if err != nil {
+		return err
+	}
+	c.Certificates = certs
+	c.License = license
+	return nil
+}
+
+// ChainCheck verifies that the license was signed by one of the trusted license CAs.
+// The validity period of the license is checked by ExpiryCheck.
+type ChainCheck struct{}
+
+func (ChainCheck) Name() string { return CheckChain }
+
+func (ChainCheck) Check(c *CheckContext) error {
+	if c.cached {
+		return nil
+	}
+	err := checkChain(c)
+	if err == nil && c.cacheKey != "" {
+		c.Options.Cache.add(c.cacheKey, c)
+	}
+	return err
+}
+
+func checkChain(c *CheckContext) error {
+	opts := c.Options
+	cas := opts.TrustedCAs()
+	if c.claims != nil {
+		// JWT licenses don't name their CA, so the token is checked against every trusted CA
+		var err error
+		for _, ca := range cas {
+			_, err = jwt.Parse(
+				c.token,
+				func(token *jwt.Token) (interface{}, error) {
+					return ca.PublicKey, nil
+				},
+				jwt.WithValidMethods(jwtSigningMethods),
+				jwt.WithoutClaimsValidation(),
+			)
+			if err == nil {
+				return nil
+			}
+		}
+		if err == nil {
+			err = jwt.ErrTokenUnverifiable
+		}
+		return tokenError(err)
+	}
+
+	cert := c.Certificates[0]
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+	// Only the configured license CAs are trusted, a CA certificate included
+	// in the license file is merely used as an intermediate.
+	intermediates := x509.NewCertPool()
+	for _, ic := range c.Certificates[1:] {
+		intermediates.AddCert(ic)
+	}
+	// verify the chain as of a time the license certificate is valid
+	now := opts.now()
+	if now.Before(cert.NotBefore) {
+		now = cert.NotBefore
+	} else if now.After(cert.NotAfter) {
+		now = cert.NotAfter
+	}
+	// ref: https://github.com/appscode/gitea/blob/master/models/stripe_license.go#L117-L126
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageClientAuth,
+		},
+		CurrentTime: now,
+	})
+	if err != nil {
+		return certificateError(err)
+	}
+	return nil
+}
+
+// ClusterBindingCheck verifies that the license was issued for the cluster UID
+// and, if the license is bound to a cluster CA, for the cluster CA certificate.
+type ClusterBindingCheck struct{}
+
+func (ClusterBindingCheck) Name() string { return CheckClusterBinding }
+
+func (ClusterBindingCheck) Check(c *CheckContext) error {
+	opts := c.Options
+	// without a cluster UID, eg. when a license is inspected offline, only the wildcard binding is checked
+	if c.claims != nil {
+		if opts.ClusterUID != "" && !MatchCluster(c.claims.Audience, opts.ClusterUID) {
+			return tokenError(jwt.ErrTokenInvalidAudience)
+		}
+	} else {
+		cert := c.Certificates[0]
+		var names []string
+		if opts.ClusterUID != "" {
+			names = append(names, opts.ClusterUID)
+		}
+		// wildcard certificate
+		if strings.HasPrefix(cert.Subject.CommonName, "*.") {
+			var wildcards []string
+			for _, ca := range opts.TrustedCAs() {
+				if len(ca.Subject.Organization) > 0 {
+					wildcards = append(wildcards, "*."+ca.Subject.Organization[0])
+				}
+			}
+			if len(wildcards) > 0 {
+				names = wildcards
+			}
+		}
+		// multi-cluster licenses list the cluster UIDs or wildcard patterns as DNS SANs
+		if len(names) > 0 && !MatchCluster(cert.DNSNames, opts.ClusterUID) {
+			var err error
+			for _, name := range names {
+				if err = cert.VerifyHostname(name); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				return certificateError(err)
+			}
+		}
+	}
+
+	if fp := c.License.ClusterCAFingerprint(); fp != "" && !strings.EqualFold(fp, opts.ClusterCAFingerprint) {
+		return withCause(ErrWrongCluster, fmt.Errorf("license %s was issued for a cluster with a different CA certificate", c.License.ID))
+	}
+	return nil
+}
+
+// MatchCluster returns true if a license issued for the clusters covers the cluster UID.
+// Besides cluster UIDs, a license can list wildcard patterns as supported by path.Match, eg. "*"
+// to cover any cluster or "fleet-a-*" to cover a fleet of clusters with a common prefix.
+func MatchCluster(clusters []string, clusterUID string) bool {
+	if clusterUID == "" {
+		return false
+	}
+	for _, c := range clusters {
+		if c == clusterUID {
+			return true
+		}
+		if ok, err := path.Match(c, clusterUID); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiryCheck verifies the validity period of the license.
+// An expired license is accepted with the grace-period status during the GracePeriod.
+// For trial licenses, it reports the remaining days of the trial and ErrTrialExpired once the trial is over.
+type ExpiryCheck struct{}
+
+func (ExpiryCheck) Name() string { return CheckExpiry }
+
+func (ExpiryCheck) Check(c *CheckContext) error {
+	err := checkExpiry(c)
+	if !c.License.IsTrial() {
+		return err
+	}
+	c.License.Trial = true
+	c.License.TrialDaysRemaining = trialDaysRemaining(c.License, c.Options.now())
+	if err != nil && errors.Is(err, ErrLicenseExpired) && c.License.TrialDaysRemaining == 0 {
+		return withCause(ErrTrialExpired, err)
+	}
+	return err
+}
+
+// trialDaysRemaining returns the number of started days left until the license expires.
+func trialDaysRemaining(license v1alpha1.License, now time.Time) int {
+	if license.NotAfter == nil || !now.Before(license.NotAfter.Time) {
+		return 0
+	}
+	return int(math.Ceil(license.NotAfter.Sub(now).Hours() / 24))
+}
+
+func checkExpiry(c *CheckContext) error {
+	opts := c.Options
+	now := opts.now()
+	// a clock running behind must not reject a new license and a clock running ahead must not expire a license early
+	early, late := now.Add(opts.ClockSkew), now.Add(-opts.ClockSkew)
+	if c.claims != nil {
+		switch {
+		case c.claims.ExpiresAt == nil:
+			return tokenError(errors.Wrap(jwt.ErrTokenRequiredClaimMissing, "exp claim is required"))
+		case c.claims.NotBefore != nil && early.Before(c.claims.NotBefore.Time):
+			return tokenError(jwt.ErrTokenNotValidYet)
+		case !late.Before(c.claims.ExpiresAt.Time):
+			if opts.inGracePeriod(c.claims.ExpiresAt.Time) {
+				c.License = opts.gracePeriodLicense(c.License)
+				return nil
+			}
+			return tokenError(jwt.ErrTokenExpired)
+		}
+		return nil
+	}
+
+	cert := c.Certificates[0]
+	switch {
+	case early.Before(cert.NotBefore):
+		return certificateError(x509.CertificateInvalidError{
+			Cert:   cert,
+			Reason: x509.Expired,
+			Detail: fmt.Sprintf("current time %s is before %s", now.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339)),
+		})
+	case late.After(cert.NotAfter):
+		if opts.inGracePeriod(cert.NotAfter) {
+			c.License = opts.gracePeriodLicense(c.License)
+			return nil
+		}
+		return certificateError(x509.CertificateInvalidError{
+			Cert:   cert,
+			Reason: x509.Expired,
+			Detail: fmt.Sprintf("current time %s is after %s", now.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339)),
+		})
+	}
+	return nil
+}
+
+// ProductCheck verifies that the license covers the requested Features as per the FeaturePolicy,
+// after resolving the FeatureAliases. Add-on licenses only count when stacked on a base license using StackLicenses.
+type ProductCheck struct{}
+
+func (ProductCheck) Name() string { return CheckProduct }
+
+func (ProductCheck) Check(c *CheckContext) error {
+	opts := c.Options
+	license := c.License
+	if err := checkAddOn(license); err != nil {
+		return err
+	}
+	license.Features = opts.FeatureAliases.Resolve(license.Features)
+	results := VerifyFeatures(license, info.ParseFeatures(opts.Features))
+	if err := CheckFeatures(results, opts.FeaturePolicy, opts.Quorum); err != nil {
+		if opts.FeaturePolicy == FeaturePolicyAny || opts.FeaturePolicy == "" {
+			err = fmt.Errorf("license was not issued for %s", opts.Features)
+		}
+		return withCause(ErrProductMismatch, err)
+	}
+	return nil
+}
+
+// VersionCheck verifies that the ProductVersion satisfies the semver constraint of a license
+// sold for fixed product versions. Such a license is rejected if the ProductVersion is unknown.
+type VersionCheck struct{}
+
+func (VersionCheck) Name() string { return CheckVersion }
+
+func (VersionCheck) Check(c *CheckContext) error {
+	constraint := c.License.ProductVersion()
+	if constraint == "" {
+		return nil
+	}
+	if c.Options.ProductVersion == "" {
+		return withCause(ErrVersionMismatch, fmt.Errorf("license %s covers product versions %s, but the product version is unknown", c.License.ID, constraint))
+	}
+	cs, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return withCause(ErrMalformedLicense, errors.Wrapf(err, "invalid product version constraint %q", constraint))
+	}
+	v, err := semver.NewVersion(c.Options.ProductVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid product version %q", c.Options.ProductVersion)
+	}
+	if !cs.Check(v) {
+		return withCause(ErrVersionMismatch, fmt.Errorf("license %s covers product versions %s, found %s", c.License.ID, constraint, c.Options.ProductVersion))
+	}
+	return nil
+}
+
+// NamespaceCheck verifies that a license restricted to namespaces covers the Namespaces
+// the product runs in or manages. Such a license is rejected if the Namespaces are unknown.
+type NamespaceCheck struct{}
+
+func (NamespaceCheck) Name() string { return CheckNamespace }
+
+func (NamespaceCheck) Check(c *CheckContext) error {
+	if scope := c.License.Namespaces(); len(scope) > 0 && len(c.Options.Namespaces) == 0 {
+		return withCause(ErrNamespaceScope, fmt.Errorf("license %s is restricted to namespace(s) %s, but the product namespaces are unknown", c.License.ID, strings.Join(scope, ",")))
+	}
+	var missing []string
+	for _, ns := range c.Options.Namespaces {
+		if !c.License.CoversNamespace(ns) {
+			missing = append(missing, ns)
+		}
+	}
+	if len(missing) > 0 {
+		return withCause(ErrNamespaceScope, fmt.Errorf("license %s does not cover namespace(s) %s", c.License.ID, strings.Join(missing, ",")))
+	}
+	return nil
+}
+
+// LimitsCheck verifies the current Usage against the entitlement limits of the license.
+// Resources without a limit are unlimited.
+type LimitsCheck struct {
+	Usage map[string]int64
+}
+
+func (LimitsCheck) Name() string { return CheckLimits }
+
+func (l LimitsCheck) Check(c *CheckContext) error {
+	return CheckLimitsUsage(c.License, l.Usage)
+}
+
+// CheckLimitsUsage returns an error if the usage of any resource exceeds its limit in the license.
+func CheckLimitsUsage(license v1alpha1.License, usage map[string]int64) error {
+	for _, resource := range sets.StringKeySet(usage).List() {
+		limit, ok := license.Limit(resource)
+		if ok && usage[resource] > limit {
+			return withCause(ErrLimitExceeded, fmt.Errorf("license %s allows %d %s, found %d", license.ID, limit, resource, usage[resource]))
+		}
+	}
+	return nil
+}
+
+// limitsFromFeatureFlags parses the limit.<resource>=<n> feature flags of an x509 license.
+func limitsFromFeatureFlags(flags map[string]string) (map[string]int64, error) {
+	var limits map[string]int64
+	for k, v := range flags {
+		resource, ok := strings.CutPrefix(k, v1alpha1.FeatureFlagLimitPrefix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid limit for %s", resource)
+		}
+		if limits == nil {
+			limits = map[string]int64{}
+		}
+		limits[resource] = n
+	}
+	return limits, nil
+}
+
+func certificateError(err error) error {
+	e2 := errors.Wrap(err, "failed to verify certificate")
+	if cause := certificateErrorCause(err); cause != nil {
+		e2 = withCause(cause, e2)
+	}
+	return e2
+}
+
+func tokenError(err error) error {
+	e2 := errors.Wrap(err, "failed to verify license token")
+	if cause := tokenErrorCause(err); cause != nil {
+		e2 = withCause(cause, e2)
+	}
+	return e2
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestPipeline(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileExpired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := verifier.VerifyOptions{
+		ParserOptions: verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     issuer.CACert,
+			License:    data,
+		},
+		Features: "kubedb-enterprise",
+	}
+
+	_, results, err := verifier.RunChecks(opts)
+	if !errors.Is(err, verifier.ErrLicenseExpired) {
+		t.Fatalf("RunChecks() error = %v, want ErrLicenseExpired", err)
+	}
+	for _, r := range results {
+		switch r.Name {
+		case verifier.CheckExpiry:
+			if r.Passed || r.Error == "" {
+				t.Errorf("%s check passed for an expired license", r.Name)
+			}
+		case verifier.CheckProduct, verifier.CheckVersion, verifier.CheckNamespace, verifier.CheckLimits:
+			if !r.Skipped {
+				t.Errorf("%s check ran after a failed check", r.Name)
+			}
+		default:
+			if !r.Passed {
+				t.Errorf("%s check failed: %s", r.Name, r.Error)
+			}
+		}
+	}
+
+	opts.Checks = verifier.DefaultPipeline().Without(verifier.CheckExpiry)
+	if _, err := verifier.CheckLicense(opts); err != nil {
+		t.Errorf("CheckLicense() without expiry check error = %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	p, _ := licensetest.GetProfile(licensetest.ProfileEnterprise)
+	p.FeatureFlags = map[string]string{v1alpha1.FeatureFlagLimitPrefix + v1alpha1.LimitNodes: "3"}
+	data, err := issuer.Issue(clusterUID, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := verifier.VerifyOptions{
+		ParserOptions: verifier.ParserOptions{
+			ClusterUID: clusterUID,
+			CACert:     issuer.CACert,
+			License:    data,
+		},
+		Features: "kubedb-enterprise",
+	}
+	for used, wantErr := range map[int64]error{3: nil, 4: verifier.ErrLimitExceeded} {
+		opts.Checks = verifier.DefaultPipeline().Replace(verifier.LimitsCheck{
+			Usage: map[string]int64{v1alpha1.LimitNodes: used, v1alpha1.LimitCPU: 100},
+		})
+		license, err := verifier.CheckLicense(opts)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("CheckLicense() with %d nodes error = %v, want %v", used, err, wantErr)
+		}
+		if limit, ok := license.Limit(v1alpha1.LimitNodes); !ok || limit != 3 {
+			t.Errorf("License.Limit() = %d, %v, want 3", limit, ok)
+		}
+	}
+}
+
+func TestNamespaceScope(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	p, _ := licensetest.GetProfile(licensetest.ProfileTrial7d)
+	p.Namespaces = []string{"team-a", "team-b"}
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.Issue(clusterUID, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		namespaces []string
+		want       error
+	}{
+		// a namespace restricted license must not be accepted for unknown namespaces
+		{nil, verifier.ErrNamespaceScope},
+		{[]string{"team-a"}, nil},
+		{[]string{"team-a", "team-b"}, nil},
+		{[]string{"team-a", "team-c"}, verifier.ErrNamespaceScope},
+	}
+	for _, tt := range tests {
+		license, err := verifier.CheckLicense(verifier.VerifyOptions{
+			ParserOptions: verifier.ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     issuer.CACert,
+				License:    data,
+			},
+			Features:   "kubedb-enterprise",
+			Namespaces: tt.namespaces,
+		})
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("CheckLicense() for namespaces %v error = %v, want %v", tt.namespaces, err, tt.want)
+		}
+		if got := license.Namespaces(); len(got) != 2 {
+			t.Errorf("license namespaces = %v, want %v", got, p.Namespaces)
+		}
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	p, _ := licensetest.GetProfile(licensetest.ProfileEnterprise)
+	p.FeatureFlags = map[string]string{v1alpha1.FeatureFlagProductVersion: "<= v2024.12.x"}
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.Issue(clusterUID, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    error
+	}{
+		// a version constrained license must not be accepted for an unknown version
+		{"", verifier.ErrVersionMismatch},
+		{"v2024.1.31", nil},
+		{"v2024.12.18", nil},
+		{"v2025.1.1", verifier.ErrVersionMismatch},
+	}
+	for _, tt := range tests {
+		_, err := verifier.CheckLicense(verifier.VerifyOptions{
+			ParserOptions: verifier.ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     issuer.CACert,
+				License:    data,
+			},
+			Features:       "kubedb-enterprise",
+			ProductVersion: tt.version,
+		})
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("CheckLicense() for version %q error = %v, want %v", tt.version, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+)
+
+// PreflightReport is the result of every check of a license verification, so that support
+// engineers and embedders can see which check rejected a license.
+type PreflightReport struct {
+	LicenseID string                 `json:"licenseID,omitempty"`
+	Status    v1alpha1.LicenseStatus `json:"status"`
+	Checks    []CheckResult          `json:"checks"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// NewPreflightReport returns the report of a verification by RunChecks.
+func NewPreflightReport(license v1alpha1.License, checks []CheckResult, err error) PreflightReport {
+	r := PreflightReport{
+		LicenseID: license.ID,
+		Status:    license.Status,
+		Checks:    checks,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// Preflight verifies the license like VerifyLicense and reports the result of every check.
+func Preflight(opts Options) PreflightReport {
+	vopts, err := opts.verifyOptions()
+	if err != nil {
+		license, err := BadLicense(err)
+		return NewPreflightReport(license, nil, err)
+	}
+	return NewPreflightReport(RunChecks(vopts))
+}
+
+// Print writes the report as a table with a row per check.
+func (r PreflightReport) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDURATION\tERROR")
+	for _, c := range r.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name, c.Outcome(), c.Duration, c.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if r.Error != "" && len(r.Checks) == 0 {
+		_, err := fmt.Fprintf(w, "license verification failed: %s\n", r.Error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestPreflight(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := verifier.Options{
+		ClusterUID: clusterUID,
+		Features:   "kubedb-enterprise",
+		CACert:     issuer.CACertPEM(),
+		License:    data,
+	}
+
+	r := verifier.Preflight(opts)
+	if r.Error != "" || len(r.Checks) != len(verifier.DefaultPipeline()) {
+		t.Fatalf("Preflight() = %+v, want all checks passed", r)
+	}
+	for _, c := range r.Checks {
+		if c.Outcome() != verifier.CheckPassed {
+			t.Errorf("check %s = %s, want %s", c.Name, c.Outcome(), verifier.CheckPassed)
+		}
+	}
+
+	opts.ClusterUID = "3c1f0a2e-7b1d-4c59-9e0a-2a1b3c4d5e6f"
+	r = verifier.Preflight(opts)
+	want := map[string]string{
+		verifier.CheckParse:          verifier.CheckPassed,
+		verifier.CheckClusterBinding: verifier.CheckFailed,
+		verifier.CheckExpiry:         verifier.CheckSkipped,
+	}
+	for _, c := range r.Checks {
+		if w, ok := want[c.Name]; ok && c.Outcome() != w {
+			t.Errorf("check %s = %s, want %s", c.Name, c.Outcome(), w)
+		}
+	}
+	var buf bytes.Buffer
+	if err := r.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), verifier.CheckClusterBinding+"  ") || !strings.Contains(buf.String(), verifier.CheckFailed) {
+		t.Errorf("Print() = %q, want a row per check", buf.String())
+	}
+}
+
+func TestClusterBindingWithoutClusterUID(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	data, err := issuer.IssueProfile("8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11", licensetest.ProfileEnterprise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = verifier.CheckLicense(verifier.VerifyOptions{
+		ParserOptions: verifier.ParserOptions{CACert: issuer.CACert, License: data},
+		Features:      "kubedb-enterprise",
+		Checks:        verifier.DefaultPipeline(),
+	})
+	if err != nil {
+		t.Errorf("CheckLicense() without a cluster UID error = %v", err)
+	}
+}
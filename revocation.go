@@ -0,0 +1,101 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+)
+
+// RevocationPolicy decides whether a license is accepted when the revocation list is stale.
+type RevocationPolicy string
+
+const (
+	// RevocationPolicyFailOpen accepts licenses with a warning when the revocation list is stale.
+	RevocationPolicyFailOpen RevocationPolicy = "FailOpen"
+	// RevocationPolicyFailClosed rejects licenses when the revocation list is stale.
+	RevocationPolicyFailClosed RevocationPolicy = "FailClosed"
+)
+
+var (
+	ErrRevocationListStale    = errors.New("license revocation list is stale")
+	ErrRevocationListRollback = errors.New("license revocation list is older than the last one seen")
+)
+
+// ParseRevocationList parses a PEM or DER encoded x509 CRL and verifies that it is signed by one of the license CAs.
+func ParseRevocationList(data []byte, caCerts ...*x509.Certificate) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse license revocation list")
+	}
+	err = errors.New("no license CA")
+	for _, ca := range caCerts {
+		if err = crl.CheckSignatureFrom(ca); err == nil {
+			return crl, nil
+		}
+	}
+	return nil, errors.Wrap(err, "failed to verify license revocation list signature")
+}
+
+// CheckRevocationListOrder returns ErrRevocationListRollback if crl is older than prev, the newest
+// revocation list seen so far, ie. it has a lower CRL number or, with the same number, an earlier
+// ThisUpdate time. This keeps an old revocation list from being replayed to un-revoke a license.
+func CheckRevocationListOrder(crl, prev *x509.RevocationList) error {
+	if prev == nil {
+		return nil
+	}
+	if crl.Number != nil && prev.Number != nil {
+		switch crl.Number.Cmp(prev.Number) {
+		case -1:
+			return errors.Wrapf(ErrRevocationListRollback, "found CRL number %s, last seen %s", crl.Number, prev.Number)
+		case 1:
+			return nil
+		}
+	}
+	if crl.ThisUpdate.Before(prev.ThisUpdate) {
+		return errors.Wrapf(ErrRevocationListRollback, "found CRL issued at %s, last seen issued at %s",
+			crl.ThisUpdate.UTC().Format(time.RFC3339), prev.ThisUpdate.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CheckRevocation checks the license against the revocation list. A revoked license is marked canceled.
+// If the license is not revoked but the list has passed its NextUpdate time, ErrRevocationListStale is returned
+// and the caller decides what to do based on its RevocationPolicy.
+func CheckRevocation(license *v1alpha1.License, crl *x509.RevocationList, now time.Time) error {
+	for _, rc := range crl.RevokedCertificateEntries {
+		if rc.SerialNumber != nil && rc.SerialNumber.String() == license.ID {
+			e2 := fmt.Errorf("license %s was revoked at %s", license.ID, rc.RevocationTime.UTC().Format(time.RFC3339))
+			license.Status = v1alpha1.LicenseCanceled
+			license.Reason = e2.Error()
+			return e2
+		}
+	}
+	if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+		return errors.Wrapf(ErrRevocationListStale, "next update was due at %s", crl.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
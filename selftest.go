@@ -0,0 +1,122 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// SelfTest checks that the license settings compiled into the binary via ldflags are
+// consistent, so that release pipelines catch a bad build before it is shipped.
+// It does not make any network calls.
+func SelfTest() error {
+	var errs []error
+
+	flags := []struct {
+		name  string
+		value string
+	}{
+		{"EnforceLicense", info.EnforceLicense},
+		{"FIPSMode", info.FIPSMode},
+	}
+	for _, f := range flags {
+		if f.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseBool(f.value); err != nil {
+			errs = append(errs, fmt.Errorf("info.%s %q is not a boolean", f.name, f.value))
+		}
+	}
+
+	enforce := !info.SkipLicenseVerification()
+	if enforce {
+		if info.LicenseCA == "" {
+			errs = append(errs, fmt.Errorf("info.LicenseCA must be set when license enforcement is enabled"))
+		}
+		if len(info.Features()) == 0 {
+			errs = append(errs, fmt.Errorf("info.ProductName must be set when license enforcement is enabled"))
+		}
+		if info.ProductUID == "" {
+			errs = append(errs, fmt.Errorf("info.ProductUID must be set when license enforcement is enabled"))
+		}
+	}
+
+	if info.LicenseCA != "" {
+		if err := checkLicenseCA([]byte(info.LicenseCA)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	endpoints := []struct {
+		name     string
+		endpoint func(...string) (string, error)
+	}{
+		{"registration", info.RegistrationAPIEndpoint},
+		{"license issuer", info.LicenseIssuerAPIEndpoint},
+		{"license quota", info.LicenseQuotaAPIEndpoint},
+		{"license revocation list", info.LicenseRevocationListAPIEndpoint},
+		{"license verification", info.LicenseVerifyAPIEndpoint},
+		{"license usage", info.LicenseUsageAPIEndpoint},
+	}
+	for _, e := range endpoints {
+		s, err := e.endpoint()
+		if err == nil {
+			err = checkEndpoint(s)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s endpoint: %w", e.name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func checkLicenseCA(data []byte) error {
+	cert, err := info.ParseCertificate(data)
+	if err != nil {
+		return fmt.Errorf("info.LicenseCA is not a PEM encoded certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return fmt.Errorf("info.LicenseCA %s is not a CA certificate", cert.Subject.CommonName)
+	}
+	if time.Now().After(cert.NotAfter) {
+		return fmt.Errorf("info.LicenseCA %s expired at %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if info.FIPSEnabled() {
+		if err := CheckAlgorithmPolicy(cert); err != nil {
+			return fmt.Errorf("info.LicenseCA can't be used in FIPS mode: %w", err)
+		}
+	}
+	return nil
+}
+
+func checkEndpoint(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s is not an absolute https url", s)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Snapshot records the result of a license verification at a point in time.
+// Signed snapshots can be archived by customers to prove license compliance.
+type Snapshot struct {
+	ClusterUID string `json:"clusterUID"`
+	LicenseID  string `json:"licenseID,omitempty"`
+	// LicenseFingerprint is the hex encoded sha256 checksum of the license data
+	LicenseFingerprint string                 `json:"licenseFingerprint,omitempty"`
+	PlanName           string                 `json:"planName,omitempty"`
+	Features           []string               `json:"features,omitempty"`
+	NotAfter           *metav1.Time           `json:"notAfter,omitempty"`
+	Status             v1alpha1.LicenseStatus `json:"status"`
+	Reason             string                 `json:"reason,omitempty"`
+	Timestamp          metav1.Time            `json:"timestamp"`
+	VerifierVersion    string                 `json:"verifierVersion"`
+}
+
+type snapshotClaims struct {
+	jwt.RegisteredClaims
+	Snapshot
+}
+
+// NewSnapshot returns a snapshot of the verified license.
+func NewSnapshot(license v1alpha1.License, clusterUID string, now time.Time) Snapshot {
+	s := Snapshot{
+		ClusterUID:      clusterUID,
+		LicenseID:       license.ID,
+		PlanName:        license.PlanName,
+		Features:        license.Features,
+		NotAfter:        license.NotAfter,
+		Status:          license.Status,
+		Reason:          license.Reason,
+		Timestamp:       metav1.NewTime(now.UTC().Truncate(time.Second)),
+		VerifierVersion: info.LibraryVersion(),
+	}
+	if len(license.Data) > 0 {
+		h := sha256.Sum256(license.Data)
+		s.LicenseFingerprint = hex.EncodeToString(h[:])
+	}
+	return s
+}
+
+// Sign returns the snapshot as a JWT signed with key. The certificate of the key is embedded
+// in the x5c header, so that auditors can verify the snapshot without any other input.
+func (s Snapshot) Sign(key crypto.Signer, cert *x509.Certificate) ([]byte, error) {
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return nil, err
+	}
+	token := jwt.NewWithClaims(method, snapshotClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  s.ClusterUID,
+			IssuedAt: jwt.NewNumericDate(s.Timestamp.Time),
+		},
+		Snapshot: s,
+	})
+	token.Header["x5c"] = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+	out, err := token.SignedString(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign license snapshot")
+	}
+	return []byte(out), nil
+}
+
+// VerifySnapshot checks the signature of a snapshot created by Snapshot.Sign and returns it.
+// The embedded signing certificate must chain to one of the roots. If roots is nil, the
+// license CA compiled into the binary is used. The embedded certificate is never trusted on its own.
+func VerifySnapshot(data []byte, roots *x509.CertPool) (*Snapshot, error) {
+	if roots == nil {
+		var err error
+		if roots, err = licenseCAPool(); err != nil {
+			return nil, errors.Wrap(err, "failed to verify license snapshot")
+		}
+	}
+	var claims snapshotClaims
+	_, err := jwt.ParseWithClaims(string(data), &claims, func(token *jwt.Token) (interface{}, error) {
+		cert, err := snapshotCertificate(token)
+		if err != nil {
+			return nil, err
+		}
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:       roots,
+			CurrentTime: claims.Timestamp.Time,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "snapshot signing certificate is not trusted")
+		}
+		return cert.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256", "ES256", "ES384", "EdDSA"}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify license snapshot")
+	}
+	return &claims.Snapshot, nil
+}
+
+// licenseCAPool returns the license CA compiled into the binary. Unlike info.LoadLicenseCA, it
+// never downloads the CA, as a downloaded CA can't anchor trust.
+func licenseCAPool() (*x509.CertPool, error) {
+	if info.LicenseCA == "" {
+		return nil, errors.New("no license CA compiled in, snapshot roots are required")
+	}
+	certs, err := info.ParseCertificates([]byte(info.LicenseCA))
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	return roots, nil
+}
+
+func snapshotCertificate(token *jwt.Token) (*x509.Certificate, error) {
+	chain, ok := token.Header["x5c"].([]interface{})
+	if !ok || len(chain) == 0 {
+		return nil, errors.New("snapshot is missing the signing certificate")
+	}
+	s, ok := chain[0].(string)
+	if !ok {
+		return nil, errors.New("snapshot signing certificate is malformed")
+	}
+	der, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "snapshot signing certificate is malformed")
+	}
+	return x509.ParseCertificate(der)
+}
+
+func signingMethodFor(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported snapshot signing key %T", key)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestVerifySnapshot(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "snapshot-signer"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	license := v1alpha1.License{ID: "1", PlanName: "kubedb-enterprise", Status: v1alpha1.LicenseActive}
+	data, err := verifier.NewSnapshot(license, "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11", now).Sign(key, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without roots, the self supplied signing certificate must not be trusted
+	if _, err := verifier.VerifySnapshot(data, nil); err == nil {
+		t.Errorf("VerifySnapshot() without roots must reject a self signed snapshot")
+	}
+	if _, err := verifier.VerifySnapshot(data, x509.NewCertPool()); err == nil {
+		t.Errorf("VerifySnapshot() with foreign roots must reject the snapshot")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	s, err := verifier.VerifySnapshot(data, roots)
+	if err != nil {
+		t.Fatalf("VerifySnapshot() error = %v", err)
+	}
+	if s.LicenseID != license.ID || s.Status != license.Status {
+		t.Errorf("VerifySnapshot() = %+v, want license %s with status %s", s, license.ID, license.Status)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"fmt"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// StackLicenses verifies a bundle of licenses as returned by SplitLicenses and stacks the add-on
+// licenses of the bundle on its base license, eg. a capacity add-on on an enterprise license.
+// The returned license is the effective license:
+//
+//   - If the bundle holds more than one valid base license, the one sorting first as per License.Less,
+//     which prefers enterprise licenses, is used.
+//   - Add-on licenses that are not valid for the cluster, eg. because they have expired, are ignored.
+//   - Add-on licenses are counted once, even if the bundle holds them more than once.
+//   - Features are the union of the features of the base and the add-on licenses.
+//   - Limits of the base license are raised by the limits of the add-on licenses. Resources that
+//     are unlimited by the base license stay unlimited.
+//   - Everything else, eg. the validity period, the feature flags and the cluster binding, is taken
+//     from the base license.
+//
+// The product and limits checks of the pipeline are run against the effective license.
+func StackLicenses(opts VerifyOptions) (v1alpha1.License, error) {
+	checks := opts.Checks
+	if checks == nil {
+		checks = DefaultPipeline()
+	}
+	// the effective license decides whether the product is covered and the limits are satisfied
+	perLicense := checks.Without(CheckProduct, CheckLimits)
+
+	var base, invalid *v1alpha1.License
+	var invalidErr error
+	var addOns []v1alpha1.License
+	for _, data := range SplitLicenses(opts.License) {
+		o := opts
+		o.License = data
+		license, _, err := perLicense.Run(o)
+		if license.IsAddOn() {
+			if err == nil {
+				addOns = append(addOns, license)
+			}
+			continue
+		}
+		if err != nil {
+			if invalid == nil {
+				invalid, invalidErr = &license, err
+			}
+			continue
+		}
+		if base == nil || license.Less(base) {
+			base = &license
+		}
+	}
+	if base == nil {
+		if invalid != nil {
+			return *invalid, invalidErr
+		}
+		return BadLicense(errors.New("no base license found, add-on licenses can't be used on their own"))
+	}
+
+	license := MergeLicenses(*base, addOns...)
+	c := &CheckContext{Options: opts, License: license}
+	for _, check := range checks {
+		if check.Name() != CheckProduct && check.Name() != CheckLimits {
+			continue
+		}
+		if err := check.Check(c); err != nil {
+			c.License.Status = v1alpha1.LicenseInvalid
+			c.License.Reason = err.Error()
+			return c.License, err
+		}
+	}
+	return c.License, nil
+}
+
+// MergeLicenses stacks the add-on licenses on the base license, following the rules of StackLicenses.
+// Add-on licenses must be verified by the caller. An add-on license is identified by its ID, which is
+// the serial number of its certificate, so copies of the same add-on raise the limits only once.
+func MergeLicenses(base v1alpha1.License, addOns ...v1alpha1.License) v1alpha1.License {
+	license := *base.DeepCopy()
+	features := sets.NewString(license.Features...)
+	stacked := sets.NewString(license.AddOns...)
+	for _, addOn := range addOns {
+		if stacked.Has(addOn.ID) {
+			continue
+		}
+		stacked.Insert(addOn.ID)
+		for _, f := range addOn.Features {
+			if !features.Has(f) {
+				features.Insert(f)
+				license.Features = append(license.Features, f)
+			}
+		}
+		for resource, n := range addOn.Limits {
+			if limit, ok := license.Limit(resource); ok {
+				license.Limits[resource] = limit + n
+			}
+		}
+		license.AddOns = append(license.AddOns, addOn.ID)
+	}
+	return license
+}
+
+// checkAddOn rejects an add-on license that is used without a base license.
+func checkAddOn(license v1alpha1.License) error {
+	if license.IsAddOn() {
+		return withCause(ErrProductMismatch, fmt.Errorf("license %s is an add-on license, which must be stacked on a base license", license.ID))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.bytebuilders.dev/license-verifier/apis/licenses/v1alpha1"
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestStackLicenses(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	issue := func(name string, mutate func(p *licensetest.Profile)) []byte {
+		p, _ := licensetest.GetProfile(name)
+		mutate(&p)
+		data, err := issuer.Issue(clusterUID, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	base := issue(licensetest.ProfileEnterprise, func(p *licensetest.Profile) {
+		p.FeatureFlags = map[string]string{v1alpha1.FeatureFlagLimitPrefix + v1alpha1.LimitNodes: "10"}
+	})
+	capacity := issue(licensetest.ProfileEnterprise, func(p *licensetest.Profile) {
+		p.AddOn = true
+		p.Features = []string{"kubedb-autoscaler"}
+		p.FeatureFlags = map[string]string{
+			v1alpha1.FeatureFlagLimitPrefix + v1alpha1.LimitNodes: "5",
+			v1alpha1.FeatureFlagLimitPrefix + v1alpha1.LimitCPU:   "8",
+		}
+	})
+	expired := issue(licensetest.ProfileExpired, func(p *licensetest.Profile) {
+		p.AddOn = true
+		p.FeatureFlags = map[string]string{v1alpha1.FeatureFlagLimitPrefix + v1alpha1.LimitNodes: "100"}
+	})
+	bundle := func(licenses ...[]byte) []byte {
+		var out []byte
+		for _, l := range licenses {
+			out = append(out, l...)
+		}
+		return out
+	}
+	opts := func(license []byte, nodes int64) verifier.VerifyOptions {
+		return verifier.VerifyOptions{
+			ParserOptions: verifier.ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     issuer.CACert,
+				License:    license,
+			},
+			Features: "kubedb-enterprise",
+			Checks:   verifier.DefaultPipeline().Replace(verifier.LimitsCheck{Usage: map[string]int64{v1alpha1.LimitNodes: nodes}}),
+		}
+	}
+
+	license, err := verifier.StackLicenses(opts(bundle(base, capacity, expired), 15))
+	if err != nil {
+		t.Fatalf("StackLicenses() error = %v", err)
+	}
+	if n, _ := license.Limit(v1alpha1.LimitNodes); n != 15 {
+		t.Errorf("StackLicenses() nodes limit = %d, want 15", n)
+	}
+	if _, ok := license.Limit(v1alpha1.LimitCPU); ok {
+		t.Errorf("StackLicenses() cpu must stay unlimited")
+	}
+	if !sets.NewString(license.Features...).Has("kubedb-autoscaler") || len(license.AddOns) != 1 {
+		t.Errorf("StackLicenses() features = %v, add-ons = %v", license.Features, license.AddOns)
+	}
+
+	// a duplicated add-on must raise the limits only once
+	if _, err := verifier.StackLicenses(opts(bundle(base, capacity, capacity), 16)); !errors.Is(err, verifier.ErrLimitExceeded) {
+		t.Errorf("StackLicenses() with a duplicated add-on error = %v, want %v", err, verifier.ErrLimitExceeded)
+	}
+	addOn := v1alpha1.License{ID: license.AddOns[0], Limits: map[string]int64{v1alpha1.LimitNodes: 5}}
+	license = verifier.MergeLicenses(license, addOn, addOn)
+	if n, _ := license.Limit(v1alpha1.LimitNodes); n != 15 || len(license.AddOns) != 1 {
+		t.Errorf("MergeLicenses() of a stacked add-on nodes limit = %d, add-ons = %v", n, license.AddOns)
+	}
+
+	if _, err := verifier.StackLicenses(opts(bundle(base, capacity), 16)); !errors.Is(err, verifier.ErrLimitExceeded) {
+		t.Errorf("StackLicenses() with 16 nodes error = %v, want %v", err, verifier.ErrLimitExceeded)
+	}
+	if _, err := verifier.StackLicenses(opts(base, 15)); !errors.Is(err, verifier.ErrLimitExceeded) {
+		t.Errorf("StackLicenses() without add-on error = %v, want %v", err, verifier.ErrLimitExceeded)
+	}
+	if _, err := verifier.StackLicenses(opts(capacity, 1)); err == nil {
+		t.Errorf("StackLicenses() of an add-on license alone must fail")
+	}
+	if _, err := verifier.CheckLicense(opts(capacity, 1)); !errors.Is(err, verifier.ErrProductMismatch) {
+		t.Errorf("CheckLicense() of an add-on license error = %v, want %v", err, verifier.ErrProductMismatch)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/x509"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/info"
+
+	"github.com/pkg/errors"
+)
+
+// VerifyCert verifies that the PEM encoded license certificate was signed by the PEM encoded caCert,
+// was issued for clusterUID and productName and is valid at now. It returns the license certificate.
+//
+// VerifyCert only performs the cryptographic checks. It does not read files, environment variables
+// or the clock and needs no Kubernetes cluster, so it can be used by CLIs and web services.
+// Licenses bound to a cluster CA certificate are rejected, since the cluster CA is unknown.
+func VerifyCert(caCert, license []byte, clusterUID, productName string, now time.Time) (*x509.Certificate, error) {
+	if IsJWT(license) {
+		return nil, withCause(ErrMalformedLicense, errors.New("license is not a x509 certificate"))
+	}
+	ca, err := info.ParseCertificate(caCert)
+	if err != nil {
+		return nil, err
+	}
+	c := &CheckContext{
+		Options: VerifyOptions{
+			ParserOptions: ParserOptions{
+				ClusterUID: clusterUID,
+				CACert:     ca,
+				License:    license,
+				Clock:      fixedClock(now),
+			},
+			Features: productName,
+		},
+	}
+	for _, check := range (Pipeline{ParseCheck{}, ChainCheck{}, ClusterBindingCheck{}, ExpiryCheck{}, ProductCheck{}}) {
+		if err := check.Check(c); err != nil {
+			return nil, err
+		}
+	}
+	return c.Certificates[0], nil
+}
+
+// fixedClock is a clock.PassiveClock that always returns the same time.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func (c fixedClock) Since(t time.Time) time.Duration { return time.Time(c).Sub(t) }
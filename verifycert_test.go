@@ -0,0 +1,64 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.bytebuilders.dev/license-verifier/licensetest"
+
+	verifier "go.bytebuilders.dev/license-verifier"
+)
+
+func TestVerifyCert(t *testing.T) {
+	issuer := licensetest.NewTestIssuer(t)
+	const clusterUID = "8e0e7f93-3b9b-4a4c-9f1c-6f1c2f1c1a11"
+	data, err := issuer.IssueProfile(clusterUID, licensetest.ProfileTrial7d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	cert, err := verifier.VerifyCert(issuer.CACertPEM(), data, clusterUID, "kubedb-enterprise", now)
+	if err != nil {
+		t.Fatalf("VerifyCert() error = %v", err)
+	}
+	if !cert.NotAfter.After(now) {
+		t.Errorf("VerifyCert() returned certificate expiring at %s", cert.NotAfter)
+	}
+
+	tests := []struct {
+		name       string
+		clusterUID string
+		product    string
+		now        time.Time
+		want       error
+	}{
+		{"wrong cluster", licensetest.WrongClusterUID, "kubedb-enterprise", now, verifier.ErrWrongCluster},
+		{"wrong product", clusterUID, "stash-enterprise", now, verifier.ErrProductMismatch},
+		{"expired", clusterUID, "kubedb-enterprise", now.Add(8 * 24 * time.Hour), verifier.ErrLicenseExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifier.VerifyCert(issuer.CACertPEM(), data, tt.clusterUID, tt.product, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyCert() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}